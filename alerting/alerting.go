@@ -0,0 +1,193 @@
+// Package alerting raises alerts when spots of the lot run low, so the entrance signs can show FULL before cars queue up.
+package alerting
+
+import (
+	"fmt"
+	parkingpkg "github.com/mtfiqh/DoiT-parking-system/parking"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
+	"log"
+	"sync"
+	"time"
+)
+
+// Level is an occupancy threshold with hysteresis: the alert is raised once the occupancy reaches At
+// and cleared once it drops to Clear or below, Clear must be below At.
+type Level struct {
+	At    float64 `json:"at"`
+	Clear float64 `json:"clear"`
+}
+
+// DefaultLevels warn at 90% and tell the lot is full at 100%, both clear at 80%.
+var DefaultLevels = []Level{{At: 0.9, Clear: 0.8}, {At: 1, Clear: 0.8}}
+
+// Config holds the levels of every scope, the most specific one wins: the floor, then the vehicle type, then Levels.
+type Config struct {
+	Levels []Level
+	Types  map[parkingentity.VehicleType][]Level
+	Floors map[int][]Level
+}
+
+// levels returns the levels watched for the scope.
+func (c Config) levels(scope Scope) []Level {
+	if levels, ok := c.Floors[scope.Floor]; ok && scope.Floor != AllFloors {
+		return levels
+	}
+	if levels, ok := c.Types[scope.VehicleType]; ok {
+		return levels
+	}
+	return c.Levels
+}
+
+// AllFloors is the floor of a scope that covers the whole lot.
+const AllFloors = -1
+
+// Scope is the spots an alert is about: the spots of a vehicle type on a floor, or on every floor.
+type Scope struct {
+	Floor       int                       `json:"floor"`
+	VehicleType parkingentity.VehicleType `json:"vehicle_type"`
+}
+
+func (s Scope) String() string {
+	if s.Floor == AllFloors {
+		return s.VehicleType.String()
+	}
+	return fmt.Sprintf("%s on floor %d", s.VehicleType, s.Floor)
+}
+
+// Alert tells a level of a scope was raised or cleared.
+type Alert struct {
+	Scope  Scope     `json:"scope"`
+	Level  Level     `json:"level"`
+	Raised bool      `json:"raised"`
+	Time   time.Time `json:"time"`
+	// Occupancy is the share of the enabled spots that are occupied or held, Free the spots left.
+	Occupancy float64 `json:"occupancy"`
+	Free      int     `json:"free"`
+	Total     int     `json:"total"`
+}
+
+func (a Alert) String() string {
+	state := "cleared"
+	if a.Raised {
+		state = "raised"
+	}
+	if a.Free == 0 && a.Raised {
+		state = "raised, FULL"
+	}
+	return fmt.Sprintf("%s at %.0f%%: %s, %d of %d spots free", a.Scope, a.Level.At*100, state, a.Free, a.Total)
+}
+
+// Notifier delivers alerts, e.g. to a log, a webhook or a file.
+type Notifier interface {
+	Notify(alert Alert) error
+}
+
+// Monitor checks the occupancy of a lot against the levels and notifies every raised and cleared alert once.
+type Monitor struct {
+	config    Config
+	notifiers []Notifier
+	// raised holds the raised levels of every scope, guarded by mutex, the notifiers are called without it
+	raised map[Scope]map[Level]bool
+	mutex  *sync.Mutex
+}
+
+// NewMonitor returns a monitor without raised alerts.
+func NewMonitor(config Config, notifiers ...Notifier) *Monitor {
+	return &Monitor{
+		config:    config,
+		notifiers: notifiers,
+		raised:    make(map[Scope]map[Level]bool),
+		mutex:     new(sync.Mutex),
+	}
+}
+
+// Run checks the occupancy of the lot every interval until done is closed,
+// the lots of parkingcli report it from counters kept by every change, so a check doesn't scan the spots.
+func (m *Monitor) Run(park parkingpkg.OccupancyReporter, interval time.Duration, done <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		m.Check(park.Occupancy(), time.Now())
+
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check raises the levels the occupancy reached and clears the ones it dropped below,
+// every vehicle type of the lot and of each floor is a scope. The changed alerts are notified and returned,
+// a slow notifier doesn't hold up the next check.
+func (m *Monitor) Check(occupancy parkingentity.Occupancy, now time.Time) []Alert {
+	alerts := m.changes(occupancy, now)
+
+	for _, alert := range alerts {
+		for _, notifier := range m.notifiers {
+			if err := notifier.Notify(alert); err != nil {
+				// the alert isn't raised again, the other notifiers still get it
+				log.Println("Error notifying alert:", err)
+			}
+		}
+	}
+
+	return alerts
+}
+
+// changes updates the raised levels with the occupancy and returns the alerts of the levels that changed.
+func (m *Monitor) changes(occupancy parkingentity.Occupancy, now time.Time) []Alert {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	var alerts []Alert
+	check := func(scope Scope, count parkingentity.SpotCount) {
+		if count.Total == 0 {
+			return
+		}
+
+		used := float64(count.Occupied+count.Held) / float64(count.Total)
+		for _, level := range m.config.levels(scope) {
+			raised := m.raised[scope][level]
+			switch {
+			case !raised && used >= level.At:
+				raised = true
+			case raised && used <= level.Clear:
+				raised = false
+			default:
+				continue
+			}
+
+			if m.raised[scope] == nil {
+				m.raised[scope] = make(map[Level]bool)
+			}
+			m.raised[scope][level] = raised
+			alerts = append(alerts, Alert{Scope: scope, Level: level, Raised: raised, Time: now, Occupancy: used, Free: count.Free(), Total: count.Total})
+		}
+	}
+
+	types := occupancy.Types()
+	for _, vehicleType := range []parkingentity.VehicleType{parkingentity.M1, parkingentity.B1, parkingentity.A1} {
+		check(Scope{Floor: AllFloors, VehicleType: vehicleType}, types[vehicleType])
+		for _, floor := range occupancy.Floors {
+			check(Scope{Floor: floor.Floor, VehicleType: vehicleType}, floor.Types[vehicleType])
+		}
+	}
+
+	return alerts
+}
+
+// Start runs the monitor on the lot in the background, stop ends it.
+func (m *Monitor) Start(park parkingpkg.OccupancyReporter, interval time.Duration) (stop func()) {
+	done, stopped := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(stopped)
+		m.Run(park, interval, done)
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
@@ -0,0 +1,224 @@
+package alerting_test
+
+import (
+	"encoding/json"
+	"github.com/mtfiqh/DoiT-parking-system/alerting"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// carsOnFloor returns a one floor lot of 10 car spots with n occupied.
+func carsOnFloor(n int) parkingentity.Occupancy {
+	count := parkingentity.SpotCount{Total: 10, Occupied: n}
+	return parkingentity.Occupancy{Floors: []parkingentity.FloorOccupancy{
+		{Floor: 0, Types: map[parkingentity.VehicleType]parkingentity.SpotCount{parkingentity.A1: count}},
+	}}
+}
+
+type recorder []alerting.Alert
+
+func (r *recorder) Notify(alert alerting.Alert) error {
+	*r = append(*r, alert)
+	return nil
+}
+
+func TestMonitor(t *testing.T) {
+	t0 := time.Date(2025, 1, 1, 8, 0, 0, 0, time.UTC)
+	var notified recorder
+
+	// the ground floor has its own levels, the lot wide scope uses the default ones
+	monitor := alerting.NewMonitor(alerting.Config{
+		Levels: alerting.DefaultLevels,
+		Floors: map[int][]alerting.Level{0: {{At: 0.5, Clear: 0.3}}},
+	}, &notified)
+
+	lot := alerting.Scope{Floor: alerting.AllFloors, VehicleType: parkingentity.A1}
+	floor := alerting.Scope{Floor: 0, VehicleType: parkingentity.A1}
+	warn, full := alerting.DefaultLevels[0], alerting.DefaultLevels[1]
+
+	type change struct {
+		scope  alerting.Scope
+		level  alerting.Level
+		raised bool
+	}
+	steps := []struct {
+		occupied int
+		expected []change
+	}{
+		{occupied: 4},
+		{occupied: 5, expected: []change{{floor, alerting.Level{At: 0.5, Clear: 0.3}, true}}},
+		{occupied: 9, expected: []change{{lot, warn, true}}},
+		{occupied: 10, expected: []change{{lot, full, true}}},
+		// hysteresis: nothing clears until the occupancy drops to the clear level
+		{occupied: 9},
+		{occupied: 10},
+		{occupied: 8, expected: []change{{lot, warn, false}, {lot, full, false}}},
+		{occupied: 3, expected: []change{{floor, alerting.Level{At: 0.5, Clear: 0.3}, false}}},
+	}
+
+	for i, step := range steps {
+		alerts := monitor.Check(carsOnFloor(step.occupied), t0)
+
+		var got []change
+		for _, alert := range alerts {
+			got = append(got, change{alert.Scope, alert.Level, alert.Raised})
+		}
+		if !reflect.DeepEqual(got, step.expected) {
+			t.Errorf("Step %d with %d occupied: expected %v, got %v", i+1, step.occupied, step.expected, got)
+		}
+	}
+
+	if len(notified) != 6 {
+		t.Errorf("Expected every change notified once, got %d", len(notified))
+	}
+	if got := notified[2].String(); got != "A-1 at 100%: raised, FULL, 0 of 10 spots free" {
+		t.Errorf("Expected the full alert, got %q", got)
+	}
+}
+
+// blockingNotifier holds the first alert until release is closed.
+type blockingNotifier struct {
+	notified chan struct{}
+	release  chan struct{}
+	calls    atomic.Int32
+}
+
+func (n *blockingNotifier) Notify(alerting.Alert) error {
+	if n.calls.Add(1) == 1 {
+		close(n.notified)
+		<-n.release
+	}
+	return nil
+}
+
+func TestMonitorNotifiesOutsideLock(t *testing.T) {
+	t0 := time.Date(2025, 1, 1, 8, 0, 0, 0, time.UTC)
+	notifier := &blockingNotifier{notified: make(chan struct{}), release: make(chan struct{})}
+	monitor := alerting.NewMonitor(alerting.Config{Levels: alerting.DefaultLevels}, notifier)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		monitor.Check(carsOnFloor(10), t0)
+	}()
+	<-notifier.notified
+
+	// the first check is still notifying, the next one clears the levels without waiting for it
+	checked := make(chan []alerting.Alert)
+	go func() { checked <- monitor.Check(carsOnFloor(0), t0) }()
+	select {
+	case alerts := <-checked:
+		// both levels of the lot and of the floor
+		if len(alerts) != 4 {
+			t.Errorf("Expected 4 levels cleared, got %v", alerts)
+		}
+		for _, alert := range alerts {
+			if alert.Raised {
+				t.Errorf("Expected %v cleared", alert)
+			}
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected a check while a notifier is blocked")
+	}
+
+	close(notifier.release)
+	<-done
+}
+
+func TestNotifiers(t *testing.T) {
+	alert := alerting.Alert{
+		Scope:     alerting.Scope{Floor: 1, VehicleType: parkingentity.M1},
+		Level:     alerting.Level{At: 1, Clear: 0.8},
+		Raised:    true,
+		Time:      time.Date(2025, 1, 1, 8, 0, 0, 0, time.UTC),
+		Occupancy: 1,
+		Total:     4,
+	}
+
+	var posted alerting.Alert
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&posted); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	if err := alerting.NewWebhookNotifier(server.URL).Notify(alert); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(posted, alert) {
+		t.Errorf("Expected the webhook to get %+v, got %+v", alert, posted)
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+	if err := alerting.NewWebhookNotifier(failing.URL).Notify(alert); err == nil {
+		t.Error("Expected an error when the webhook fails")
+	}
+
+	path := filepath.Join(t.TempDir(), "alerts.jsonl")
+	notifier := alerting.NewFileNotifier(path)
+	for i := 0; i < 2; i++ {
+		if err := notifier.Notify(alert); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(string(data)), "\n"); len(lines) != 2 || !strings.Contains(lines[0], `"raised":true`) {
+		t.Errorf("Expected 2 JSON lines, got %q", data)
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "alerts.json")
+	content := `{
+		"types": {"A-1": [{"at": 95, "clear": 85}]},
+		"floors": {"2": [{"at": 100, "clear": 90}]},
+		"interval": "5s",
+		"notify": ["log", "file:alerts.jsonl", "webhook:http://localhost:9000/signs"]
+	}`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := alerting.LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := alerting.Config{
+		Levels: alerting.DefaultLevels,
+		Types:  map[parkingentity.VehicleType][]alerting.Level{parkingentity.A1: {{At: 0.95, Clear: 0.85}}},
+		Floors: map[int][]alerting.Level{2: {{At: 1, Clear: 0.9}}},
+	}
+	if !reflect.DeepEqual(file.Config, expected) || file.Interval != 5*time.Second || len(file.Notifiers) != 3 {
+		t.Errorf("Expected %+v every 5s with 3 notifiers, got %+v", expected, file)
+	}
+
+	for _, invalid := range []string{
+		`{"levels": [{"at": 80, "clear": 90}]}`,
+		`{"types": {"Z-9": []}}`,
+		`{"floors": {"ground": []}}`,
+		`{"notify": ["pager"]}`,
+	} {
+		if err := os.WriteFile(path, []byte(invalid), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := alerting.LoadConfig(path); err == nil {
+			t.Errorf("Expected an error for %s", invalid)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package alerting
+
+import (
+	"encoding/json"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
+	"github.com/pkg/errors"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// configFile is the JSON layout of an alert file, levels are percentages of occupied or held spots:
+//
+//	{
+//	  "levels": [{"at": 90, "clear": 80}, {"at": 100, "clear": 80}],
+//	  "types": {"A-1": [{"at": 95, "clear": 85}]},
+//	  "floors": {"0": [{"at": 100, "clear": 90}]},
+//	  "interval": "1s",
+//	  "notify": ["log", "file:alerts.jsonl", "webhook:http://localhost:9000/signs"]
+//	}
+type configFile struct {
+	Levels   []Level            `json:"levels"`
+	Types    map[string][]Level `json:"types"`
+	Floors   map[string][]Level `json:"floors"`
+	Interval string             `json:"interval"`
+	Notify   []string           `json:"notify"`
+}
+
+// File is a loaded alert file.
+type File struct {
+	Config Config
+	// Interval is how often the occupancy is checked.
+	Interval  time.Duration
+	Notifiers []Notifier
+}
+
+// LoadConfig reads the levels and notifiers from a JSON file, the default levels are used when it has none.
+func LoadConfig(path string) (File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return File{}, err
+	}
+
+	var f configFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return File{}, errors.Wrapf(err, "parsing alerts %s", path)
+	}
+
+	file := File{
+		Config: Config{
+			Levels: DefaultLevels,
+			Types:  make(map[parkingentity.VehicleType][]Level, len(f.Types)),
+			Floors: make(map[int][]Level, len(f.Floors)),
+		},
+		Interval: time.Second,
+	}
+
+	if f.Levels != nil {
+		if file.Config.Levels, err = percentLevels(f.Levels); err != nil {
+			return File{}, errors.Wrapf(err, "parsing alerts %s", path)
+		}
+	}
+
+	for code, levels := range f.Types {
+		vehicleType, err := parkingentity.ParseVehicleType(code)
+		if err != nil {
+			return File{}, errors.Wrapf(err, "parsing alerts %s: %q", path, code)
+		}
+		if file.Config.Types[vehicleType], err = percentLevels(levels); err != nil {
+			return File{}, errors.Wrapf(err, "parsing alerts %s: levels of %s", path, code)
+		}
+	}
+
+	for key, levels := range f.Floors {
+		floor, err := strconv.Atoi(key)
+		if err != nil || floor < 0 {
+			return File{}, errors.Errorf("parsing alerts %s: invalid floor %q", path, key)
+		}
+		if file.Config.Floors[floor], err = percentLevels(levels); err != nil {
+			return File{}, errors.Wrapf(err, "parsing alerts %s: levels of floor %d", path, floor)
+		}
+	}
+
+	if f.Interval != "" {
+		file.Interval, err = time.ParseDuration(f.Interval)
+		if err != nil || file.Interval <= 0 {
+			return File{}, errors.Errorf("parsing alerts %s: invalid interval %q", path, f.Interval)
+		}
+	}
+
+	for _, spec := range f.Notify {
+		notifier, err := ParseNotifier(spec)
+		if err != nil {
+			return File{}, errors.Wrapf(err, "parsing alerts %s", path)
+		}
+		file.Notifiers = append(file.Notifiers, notifier)
+	}
+
+	return file, nil
+}
+
+// percentLevels turns levels in percent into shares, every level must clear below where it's raised.
+func percentLevels(levels []Level) ([]Level, error) {
+	shares := make([]Level, len(levels))
+	for i, level := range levels {
+		if level.At <= 0 || level.At > 100 || level.Clear < 0 || level.Clear >= level.At {
+			return nil, errors.Errorf("level at %v%% clearing at %v%% must have 0 <= clear < at <= 100", level.At, level.Clear)
+		}
+		shares[i] = Level{At: level.At / 100, Clear: level.Clear / 100}
+	}
+
+	return shares, nil
+}
+
+// ParseNotifier parses a notifier written as log, file:path or webhook:url.
+func ParseNotifier(spec string) (Notifier, error) {
+	kind, target, _ := strings.Cut(spec, ":")
+	switch {
+	case kind == "log" && target == "":
+		return LogNotifier{}, nil
+	case kind == "file" && target != "":
+		return NewFileNotifier(target), nil
+	case kind == "webhook" && target != "":
+		return NewWebhookNotifier(target), nil
+	default:
+		return nil, errors.Errorf("invalid notifier %q, expected log, file:path or webhook:url", spec)
+	}
+}
@@ -0,0 +1,87 @@
+package alerting
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"log"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// LogNotifier writes every alert to the logger, the standard logger when nil.
+type LogNotifier struct {
+	Logger *log.Logger
+}
+
+func (n LogNotifier) Notify(alert Alert) error {
+	if n.Logger == nil {
+		log.Println("ALERT", alert)
+		return nil
+	}
+
+	n.Logger.Println("ALERT", alert)
+	return nil
+}
+
+// WebhookNotifier posts every alert as JSON to URL, e.g. the controller of the entrance signs.
+type WebhookNotifier struct {
+	URL    string
+	Client *http.Client
+}
+
+// NewWebhookNotifier returns a notifier posting to url that gives up on a request after 5 seconds.
+func NewWebhookNotifier(url string) *WebhookNotifier {
+	return &WebhookNotifier{URL: url, Client: &http.Client{Timeout: 5 * time.Second}}
+}
+
+func (n *WebhookNotifier) Notify(alert Alert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+
+	resp, err := n.Client.Post(n.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "posting alert")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return errors.Errorf("posting alert: %s answered %s", n.URL, resp.Status)
+	}
+
+	return nil
+}
+
+// FileNotifier appends every alert to a file as a JSON line.
+type FileNotifier struct {
+	path  string
+	mutex *sync.Mutex
+}
+
+// NewFileNotifier returns a notifier appending to the file at path, it's created on the first alert.
+func NewFileNotifier(path string) *FileNotifier {
+	return &FileNotifier{path: path, mutex: new(sync.Mutex)}
+}
+
+func (n *FileNotifier) Notify(alert Alert) error {
+	line, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	f, err := os.OpenFile(n.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return errors.Wrap(err, "opening alert file")
+	}
+	defer f.Close()
+
+	_, err = f.Write(append(line, '\n'))
+	return errors.Wrap(err, "writing alert")
+}
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"encoding/json"
+	parkingpkg "github.com/mtfiqh/DoiT-parking-system/parking"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
+	"github.com/pkg/errors"
+	"net/http"
+)
+
+var (
+	errInvalidVehicleNumber = errors.New("invalid vehicle number")
+	errInvalidRequestBody   = errors.New("invalid request body")
+	errGatesNotSupported    = errors.New("parking system does not know its gates")
+)
+
+// Server exposes a ParkingSystem over HTTP using the routes described in the readme API blueprint.
+type Server struct {
+	park parkingpkg.ParkingSystem
+	// spots is the format of the spot IDs in requests and responses, the one of the parking system.
+	spots parkingentity.SpotFormat
+	mux   *http.ServeMux
+}
+
+// NewServer wraps the given parking system and registers the parking routes.
+func NewServer(park parkingpkg.ParkingSystem) *Server {
+	s := &Server{
+		park:  park,
+		spots: parkingpkg.SpotFormatOf(park),
+		mux:   http.NewServeMux(),
+	}
+
+	s.mux.HandleFunc("POST /parking/park", s.handlePark)
+	s.mux.HandleFunc("POST /parking/unpark", s.handleUnpark)
+	s.mux.HandleFunc("GET /parking", s.handleAvailableSpot)
+	s.mux.HandleFunc("GET /parking/search-vehicle", s.handleSearchVehicle)
+	s.mux.HandleFunc("GET /parking/history", s.handleHistory)
+	s.mux.HandleFunc("GET /parking/sessions", s.handleSessions)
+	s.mux.HandleFunc("POST /parking/reserve", s.handleReserve)
+	s.mux.HandleFunc("POST /parking/claim", s.handleClaim)
+	s.mux.HandleFunc("POST /parking/cancel-reservation", s.handleCancelReservation)
+
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// plateQuery reads the vehicle_number query parameter as a plate.
+func plateQuery(r *http.Request) (parkingentity.Plate, error) {
+	vehicleNumber := parkingentity.NormalizePlate(r.URL.Query().Get("vehicle_number"))
+	if vehicleNumber == "" {
+		return "", errInvalidVehicleNumber
+	}
+
+	return vehicleNumber, nil
+}
+
+// response is the envelope for every response body.
+type response struct {
+	Data    any    `json:"data"`
+	Message string `json:"message"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, data any, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(response{Data: data, Message: message})
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	writeJSON(w, statusFromError(err), nil, err.Error())
+}
+
+// statusFromError maps parking errors to the HTTP status code returned to the client.
+func statusFromError(err error) int {
+	switch errors.Cause(err) {
+	case parkingentity.ErrInvalidVehicleType, parkingentity.ErrInvalidPlate, parkingentity.ErrMalformedSpotID, errInvalidVehicleNumber, errInvalidRequestBody, errInvalidTimeRange,
+		parkingentity.ErrInvalidWindow, errInvalidWindow, parkingentity.ErrGateDirection, parkingentity.ErrInvalidSpotQuery, parkingentity.ErrInvalidCursor:
+		return http.StatusBadRequest
+	case parkingentity.ErrVehicleNotFound, parkingentity.ErrSpotOutOfRange, parkingentity.ErrReservationNotFound, parkingentity.ErrUnknownGate:
+		return http.StatusNotFound
+	case parkingentity.ErrVehicleAlreadyParked, parkingentity.ErrSpotNotFound, parkingentity.ErrVehicleNotAtSpot, parkingentity.ErrVehicleReserved:
+		return http.StatusConflict
+	case parkingentity.ErrReservationExpired:
+		return http.StatusGone
+	case errHistoryNotSupported, errReservationNotSupported, errGatesNotSupported, errLostTicketNotSupported:
+		return http.StatusNotImplemented
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,81 @@
+package api
+
+import (
+	parkingpkg "github.com/mtfiqh/DoiT-parking-system/parking"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
+	"net/http"
+	"strconv"
+)
+
+type availableSpotResponse struct {
+	AvailableSpots []string `json:"available_spots"`
+	Total          int      `json:"total"`
+	NextCursor     string   `json:"next_cursor,omitempty"`
+}
+
+func (s *Server) handleAvailableSpot(w http.ResponseWriter, r *http.Request) {
+	query, err := s.spotQuery(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	page, err := parkingpkg.QuerySpotsOf(s.park, query)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	ids := make([]string, 0, len(page.Spots))
+	for _, spot := range page.Spots {
+		ids = append(ids, s.spots.Format(parkingentity.SpotID(spot)))
+	}
+
+	writeJSON(w, http.StatusOK, availableSpotResponse{AvailableSpots: ids, Total: page.Total, NextCursor: page.Next}, "ok")
+}
+
+// spotQuery reads the vehicle type, the filters and the page from the query parameters,
+// the floor and rows are numbered like the spot IDs of the responses.
+func (s *Server) spotQuery(r *http.Request) (parkingentity.SpotQuery, error) {
+	params := r.URL.Query()
+
+	vehicleType, err := parkingentity.ParseVehicleType(params.Get("vehicle_type"))
+	if err != nil {
+		return parkingentity.SpotQuery{}, err
+	}
+
+	query := parkingentity.SpotQuery{VehicleType: vehicleType, Cursor: params.Get("cursor")}
+	for name, value := range map[string]*int{"limit": &query.Limit, "within": &query.Within} {
+		if params.Has(name) {
+			if *value, err = strconv.Atoi(params.Get(name)); err != nil {
+				return parkingentity.SpotQuery{}, parkingentity.ErrInvalidSpotQuery
+			}
+		}
+	}
+
+	if params.Has("floor") {
+		floor, err := s.spots.ParseFloor(params.Get("floor"))
+		if err != nil {
+			return parkingentity.SpotQuery{}, parkingentity.ErrInvalidSpotQuery
+		}
+		query.Floor = &floor
+	}
+
+	if params.Has("rows") {
+		rows, err := s.spots.ParseRowRange(params.Get("rows"))
+		if err != nil {
+			return parkingentity.SpotQuery{}, err
+		}
+		query.Rows = &rows
+	}
+
+	if params.Has("near") {
+		gate, err := parkingpkg.GateOf(s.park, params.Get("near"))
+		if err != nil {
+			return parkingentity.SpotQuery{}, err
+		}
+		query.Near = &gate.Spot
+	}
+
+	return query, nil
+}
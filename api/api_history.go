@@ -0,0 +1,98 @@
+package api
+
+import (
+	parkingpkg "github.com/mtfiqh/DoiT-parking-system/parking"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
+	"github.com/pkg/errors"
+	"net/http"
+	"time"
+)
+
+var (
+	errHistoryNotSupported = errors.New("parking system does not keep session history")
+	errInvalidTimeRange    = errors.New("invalid time range, from and to must be RFC3339 and from before to")
+)
+
+type sessionResponse struct {
+	VehicleNumber string     `json:"vehicle_number"`
+	VehicleType   string     `json:"vehicle_type"`
+	SpotID        string     `json:"spot_id"`
+	Gate          string     `json:"gate,omitempty"`
+	ExitGate      string     `json:"exit_gate,omitempty"`
+	EntryAt       time.Time  `json:"entry_at"`
+	ExitAt        *time.Time `json:"exit_at"`
+	LostTicket    bool       `json:"lost_ticket,omitempty"`
+}
+
+func (s *Server) newSessionResponses(sessions []parkingentity.Session) []sessionResponse {
+	res := make([]sessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		res = append(res, sessionResponse{
+			VehicleNumber: session.VehicleNumber.String(),
+			VehicleType:   session.VehicleType.String(),
+			SpotID:        s.spots.Format(session.SpotID),
+			Gate:          session.Gate,
+			ExitGate:      session.ExitGate,
+			EntryAt:       session.EntryAt,
+			ExitAt:        exitAt(session),
+			LostTicket:    session.LostTicket,
+		})
+	}
+
+	return res
+}
+
+// exitAt is nil while the vehicle is still parked.
+func exitAt(session parkingentity.Session) *time.Time {
+	if session.Active() {
+		return nil
+	}
+
+	exitAt := session.ExitAt
+	return &exitAt
+}
+
+func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
+	history, ok := s.park.(parkingpkg.SessionHistory)
+	if !ok {
+		writeError(w, errHistoryNotSupported)
+		return
+	}
+
+	vehicleNumber, err := plateQuery(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	sessions, err := history.History(vehicleNumber)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"sessions": s.newSessionResponses(sessions)}, "ok")
+}
+
+func (s *Server) handleSessions(w http.ResponseWriter, r *http.Request) {
+	history, ok := s.park.(parkingpkg.SessionHistory)
+	if !ok {
+		writeError(w, errHistoryNotSupported)
+		return
+	}
+
+	from, errFrom := time.Parse(time.RFC3339, r.URL.Query().Get("from"))
+	to, errTo := time.Parse(time.RFC3339, r.URL.Query().Get("to"))
+	if errFrom != nil || errTo != nil || !from.Before(to) {
+		writeError(w, errInvalidTimeRange)
+		return
+	}
+
+	sessions, err := history.SessionsBetween(from, to)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"sessions": s.newSessionResponses(sessions)}, "ok")
+}
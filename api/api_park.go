@@ -0,0 +1,48 @@
+package api
+
+import (
+	"encoding/json"
+	parkingpkg "github.com/mtfiqh/DoiT-parking-system/parking"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
+	"net/http"
+)
+
+type parkRequest struct {
+	VehicleType   string              `json:"vehicle_type"`
+	VehicleNumber parkingentity.Plate `json:"vehicle_number"`
+	// Gate is the gate the vehicle enters at, optional.
+	Gate string `json:"gate"`
+}
+
+type spotResponse struct {
+	SpotID string `json:"spot_id"`
+}
+
+func (s *Server) handlePark(w http.ResponseWriter, r *http.Request) {
+	var req parkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, errInvalidRequestBody)
+		return
+	}
+
+	vehicleType, err := parkingentity.ParseVehicleType(req.VehicleType)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	var spotID *parkingentity.SpotID
+	if req.Gate == "" {
+		spotID, err = s.park.Park(vehicleType, req.VehicleNumber)
+	} else if gated, ok := s.park.(parkingpkg.GateParker); ok {
+		spotID, err = gated.ParkAt(req.Gate, vehicleType, req.VehicleNumber)
+	} else {
+		err = errGatesNotSupported
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, spotResponse{SpotID: s.spots.Format(*spotID)}, "created")
+}
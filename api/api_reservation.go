@@ -0,0 +1,107 @@
+package api
+
+import (
+	"encoding/json"
+	parkingpkg "github.com/mtfiqh/DoiT-parking-system/parking"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
+	"github.com/pkg/errors"
+	"net/http"
+	"time"
+)
+
+var (
+	errReservationNotSupported = errors.New("parking system does not support reservations")
+	errInvalidWindow           = errors.New("invalid window, must be a duration like 30m")
+)
+
+type reserveRequest struct {
+	VehicleType   string              `json:"vehicle_type"`
+	VehicleNumber parkingentity.Plate `json:"vehicle_number"`
+	Window        string              `json:"window"`
+}
+
+type reservationRequest struct {
+	VehicleNumber parkingentity.Plate `json:"vehicle_number"`
+}
+
+type reservationResponse struct {
+	SpotID    string    `json:"spot_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func (s *Server) handleReserve(w http.ResponseWriter, r *http.Request) {
+	reserver, ok := s.park.(parkingpkg.Reserver)
+	if !ok {
+		writeError(w, errReservationNotSupported)
+		return
+	}
+
+	var req reserveRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, errInvalidRequestBody)
+		return
+	}
+
+	vehicleType, err := parkingentity.ParseVehicleType(req.VehicleType)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	window, err := time.ParseDuration(req.Window)
+	if err != nil {
+		writeError(w, errInvalidWindow)
+		return
+	}
+
+	reservation, err := reserver.Reserve(vehicleType, req.VehicleNumber, window)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, reservationResponse{SpotID: s.spots.Format(reservation.SpotID), ExpiresAt: reservation.ExpiresAt}, "created")
+}
+
+func (s *Server) handleClaim(w http.ResponseWriter, r *http.Request) {
+	reserver, ok := s.park.(parkingpkg.Reserver)
+	if !ok {
+		writeError(w, errReservationNotSupported)
+		return
+	}
+
+	var req reservationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, errInvalidRequestBody)
+		return
+	}
+
+	spotID, err := reserver.ClaimReservation(req.VehicleNumber)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, spotResponse{SpotID: s.spots.Format(*spotID)}, "created")
+}
+
+func (s *Server) handleCancelReservation(w http.ResponseWriter, r *http.Request) {
+	reserver, ok := s.park.(parkingpkg.Reserver)
+	if !ok {
+		writeError(w, errReservationNotSupported)
+		return
+	}
+
+	var req reservationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, errInvalidRequestBody)
+		return
+	}
+
+	if err := reserver.CancelReservation(req.VehicleNumber); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, nil, "ok")
+}
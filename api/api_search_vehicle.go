@@ -0,0 +1,28 @@
+package api
+
+import (
+	parkingpkg "github.com/mtfiqh/DoiT-parking-system/parking"
+	"net/http"
+)
+
+type searchVehicleResponse struct {
+	SpotID      string `json:"spot_id"`
+	StillParked bool   `json:"still_parked"`
+}
+
+func (s *Server) handleSearchVehicle(w http.ResponseWriter, r *http.Request) {
+	vehicleNumber, err := plateQuery(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	// the spot and whether the vehicle is still there come from the same read
+	vehicleSpot, err := parkingpkg.LocateVehicleOf(s.park, vehicleNumber)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, searchVehicleResponse{SpotID: s.spots.Format(vehicleSpot.SpotID), StillParked: vehicleSpot.StillParked}, "ok")
+}
@@ -0,0 +1,273 @@
+package api_test
+
+import (
+	"encoding/json"
+	"github.com/mtfiqh/DoiT-parking-system/api"
+	"github.com/mtfiqh/DoiT-parking-system/billing"
+	"github.com/mtfiqh/DoiT-parking-system/cli/parkingcli"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
+	"github.com/mtfiqh/DoiT-parking-system/pkg/clockx"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type envelope struct {
+	Data    map[string]any `json:"data"`
+	Message string         `json:"message"`
+}
+
+func do(t *testing.T, h http.Handler, method, target, body string) (int, envelope) {
+	t.Helper()
+
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	var env envelope
+	if err := json.NewDecoder(rec.Body).Decode(&env); err != nil {
+		t.Fatalf("Failed to decode response of %s %s: %v", method, target, err)
+	}
+
+	return rec.Code, env
+}
+
+func TestServer(t *testing.T) {
+	park, err := parkingcli.NewPark(parkingcli.WithRandomizeParkingSpots(2, 10, 10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := api.NewServer(park)
+
+	var spotID string
+
+	t.Run("park", func(t *testing.T) {
+		code, env := do(t, srv, http.MethodPost, "/parking/park", `{"vehicle_type":"A-1","vehicle_number":"1234"}`)
+		if code != http.StatusCreated || env.Message != "created" {
+			t.Fatalf("Expected 201 created, got %d %q", code, env.Message)
+		}
+
+		spotID, _ = env.Data["spot_id"].(string)
+		if spotID == "" {
+			t.Fatal("Expected a spot_id in response data")
+		}
+	})
+
+	t.Run("park at unknown gate", func(t *testing.T) {
+		code, _ := do(t, srv, http.MethodPost, "/parking/park", `{"vehicle_type":"A-1","vehicle_number":"4321","gate":"north"}`)
+		if code != http.StatusNotFound {
+			t.Errorf("Expected 404, got %d", code)
+		}
+	})
+
+	t.Run("park already parked", func(t *testing.T) {
+		code, _ := do(t, srv, http.MethodPost, "/parking/park", `{"vehicle_type":"A-1","vehicle_number":"1234"}`)
+		if code != http.StatusConflict {
+			t.Errorf("Expected 409, got %d", code)
+		}
+	})
+
+	t.Run("park invalid vehicle type", func(t *testing.T) {
+		code, _ := do(t, srv, http.MethodPost, "/parking/park", `{"vehicle_type":"Z-9","vehicle_number":"1"}`)
+		if code != http.StatusBadRequest {
+			t.Errorf("Expected 400, got %d", code)
+		}
+	})
+
+	t.Run("search vehicle", func(t *testing.T) {
+		code, env := do(t, srv, http.MethodGet, "/parking/search-vehicle?vehicle_number=1234", "")
+		if code != http.StatusOK || env.Data["spot_id"] != spotID || env.Data["still_parked"] != true {
+			t.Errorf("Expected 200 with spot %s still parked, got %d %v", spotID, code, env.Data)
+		}
+	})
+
+	t.Run("search vehicle not found", func(t *testing.T) {
+		code, _ := do(t, srv, http.MethodGet, "/parking/search-vehicle?vehicle_number=999", "")
+		if code != http.StatusNotFound {
+			t.Errorf("Expected 404, got %d", code)
+		}
+	})
+
+	t.Run("available spot", func(t *testing.T) {
+		total, _ := park.AvailableSpot(parkingentity.B1)
+		code, env := do(t, srv, http.MethodGet, "/parking?vehicle_type=B-1", "")
+		if code != http.StatusOK || int(env.Data["total"].(float64)) != total {
+			t.Errorf("Expected 200 with total %d, got %d %v", total, code, env.Data["total"])
+		}
+	})
+
+	t.Run("available spot pages", func(t *testing.T) {
+		total, spots := park.AvailableSpot(parkingentity.A1)
+		code, env := do(t, srv, http.MethodGet, "/parking?vehicle_type=A-1&limit=3&cursor=3", "")
+		ids, _ := env.Data["available_spots"].([]any)
+		if code != http.StatusOK || int(env.Data["total"].(float64)) != total || len(ids) != 3 || env.Data["next_cursor"] != "6" {
+			t.Fatalf("Expected 200 with 3 of %d spots and the next cursor, got %d %v", total, code, env.Data)
+		}
+		if ids[0] != parkingentity.SpotID(spots[3]).ID() {
+			t.Errorf("Expected the page to start at %v, got %v", spots[3], ids[0])
+		}
+
+		code, env = do(t, srv, http.MethodGet, "/parking?vehicle_type=A-1&floor=1&rows=2-3", "")
+		if code != http.StatusOK || int(env.Data["total"].(float64)) >= total {
+			t.Errorf("Expected 200 with fewer than %d spots, got %d %v", total, code, env.Data["total"])
+		}
+	})
+
+	t.Run("available spot invalid query", func(t *testing.T) {
+		for _, query := range []string{"limit=-1", "rows=5-2", "floor=one", "cursor=abc"} {
+			if code, _ := do(t, srv, http.MethodGet, "/parking?vehicle_type=A-1&"+query, ""); code != http.StatusBadRequest {
+				t.Errorf("%s: expected 400, got %d", query, code)
+			}
+		}
+		if code, _ := do(t, srv, http.MethodGet, "/parking?vehicle_type=A-1&near=north", ""); code != http.StatusNotFound {
+			t.Errorf("Expected 404 for an unknown gate, got %d", code)
+		}
+	})
+
+	t.Run("unpark malformed spot id", func(t *testing.T) {
+		code, _ := do(t, srv, http.MethodPost, "/parking/unpark", `{"spot_id":"1/2/3","vehicle_number":"1234"}`)
+		if code != http.StatusBadRequest {
+			t.Errorf("Expected 400, got %d", code)
+		}
+	})
+
+	t.Run("unpark", func(t *testing.T) {
+		code, env := do(t, srv, http.MethodPost, "/parking/unpark", `{"spot_id":"`+spotID+`","vehicle_number":"1234"}`)
+		if code != http.StatusOK || env.Message != "ok" {
+			t.Errorf("Expected 200 ok, got %d %q", code, env.Message)
+		}
+	})
+
+	t.Run("search vehicle after unpark", func(t *testing.T) {
+		code, env := do(t, srv, http.MethodGet, "/parking/search-vehicle?vehicle_number=1234", "")
+		if code != http.StatusOK || env.Data["spot_id"] != spotID || env.Data["still_parked"] != false {
+			t.Errorf("Expected 200 with last spot %s no longer parked, got %d %v", spotID, code, env.Data)
+		}
+	})
+
+	t.Run("history", func(t *testing.T) {
+		code, env := do(t, srv, http.MethodGet, "/parking/history?vehicle_number=1234", "")
+		sessions, _ := env.Data["sessions"].([]any)
+		if code != http.StatusOK || len(sessions) != 1 {
+			t.Fatalf("Expected 200 with 1 session, got %d %v", code, env.Data)
+		}
+
+		session := sessions[0].(map[string]any)
+		if session["spot_id"] != spotID || session["vehicle_type"] != "A-1" || session["exit_at"] == nil {
+			t.Errorf("Expected a closed A-1 session at %s, got %v", spotID, session)
+		}
+	})
+
+	t.Run("sessions invalid range", func(t *testing.T) {
+		code, _ := do(t, srv, http.MethodGet, "/parking/sessions?from=2025-01-02T00:00:00Z&to=2025-01-01T00:00:00Z", "")
+		if code != http.StatusBadRequest {
+			t.Errorf("Expected 400, got %d", code)
+		}
+	})
+
+	t.Run("reserve and claim", func(t *testing.T) {
+		code, env := do(t, srv, http.MethodPost, "/parking/reserve", `{"vehicle_type":"B-1","vehicle_number":"5678","window":"30m"}`)
+		held, _ := env.Data["spot_id"].(string)
+		if code != http.StatusCreated || held == "" || env.Data["expires_at"] == nil {
+			t.Fatalf("Expected 201 with a held spot, got %d %v", code, env.Data)
+		}
+
+		if code, _ := do(t, srv, http.MethodPost, "/parking/park", `{"vehicle_type":"B-1","vehicle_number":"5678"}`); code != http.StatusConflict {
+			t.Errorf("Expected 409 parking a reserved vehicle, got %d", code)
+		}
+		if code, _ := do(t, srv, http.MethodPost, "/parking/reserve", `{"vehicle_type":"B-1","vehicle_number":"9999","window":"soon"}`); code != http.StatusBadRequest {
+			t.Errorf("Expected 400 for an invalid window, got %d", code)
+		}
+
+		code, env = do(t, srv, http.MethodPost, "/parking/claim", `{"vehicle_number":"5678"}`)
+		if code != http.StatusCreated || env.Data["spot_id"] != held {
+			t.Errorf("Expected 201 at %s, got %d %v", held, code, env.Data)
+		}
+
+		if code, _ := do(t, srv, http.MethodPost, "/parking/cancel-reservation", `{"vehicle_number":"5678"}`); code != http.StatusNotFound {
+			t.Errorf("Expected 404 cancelling a claimed reservation, got %d", code)
+		}
+	})
+
+	t.Run("unpark twice", func(t *testing.T) {
+		code, _ := do(t, srv, http.MethodPost, "/parking/unpark", `{"spot_id":"`+spotID+`","vehicle_number":"1234"}`)
+		if code != http.StatusNotFound {
+			t.Errorf("Expected 404, got %d", code)
+		}
+	})
+}
+
+func TestServerSpotFormat(t *testing.T) {
+	park, err := parkingcli.NewPark(parkingcli.WithRandomizeParkingSpots(2, 10, 10), parkingcli.WithSpotFormat(parkingentity.SpotFormat{OneBased: true, Basements: 1}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := api.NewServer(park)
+
+	// count is the number of free A1 spots on the floor and rows, zero based
+	count := func(floor, from, to int) int {
+		_, spots := park.AvailableSpot(parkingentity.A1)
+		n := 0
+		for _, spot := range spots {
+			if spot.Floor == floor && spot.Row >= from && spot.Row <= to {
+				n++
+			}
+		}
+		return n
+	}
+
+	// the filters are numbered like the spot IDs: B1 is floor 0 and the rows start at 1
+	for query, expected := range map[string]int{
+		"floor=B1&rows=1-1": count(0, 0, 0),
+		"floor=1&rows=2-10": count(1, 1, 9),
+	} {
+		code, env := do(t, srv, http.MethodGet, "/parking?vehicle_type=A-1&limit=1&"+query, "")
+		if code != http.StatusOK || int(env.Data["total"].(float64)) != expected {
+			t.Errorf("%s: expected 200 with %d spots, got %d %v", query, expected, code, env.Data["total"])
+		}
+	}
+
+	for _, query := range []string{"floor=0", "floor=B2", "rows=0-3"} {
+		if code, _ := do(t, srv, http.MethodGet, "/parking?vehicle_type=A-1&"+query, ""); code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", query, code)
+		}
+	}
+}
+
+func TestServerLostTicket(t *testing.T) {
+	clock := clockx.NewFake(time.Date(2025, 1, 1, 8, 0, 0, 0, time.UTC))
+	tariffs := billing.New(billing.Config{Currency: "IDR", Tariffs: map[parkingentity.VehicleType]billing.Tariff{
+		parkingentity.A1: {FirstHour: 5000, Hourly: 3000, LostTicket: 25000},
+	}}, billing.WithClock(clock))
+
+	park, err := parkingcli.NewPark(parkingcli.WithRandomizeParkingSpots(2, 10, 10), parkingcli.WithClock(clock), parkingcli.WithBilling(tariffs))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := api.NewServer(park)
+
+	code, env := do(t, srv, http.MethodPost, "/parking/park", `{"vehicle_type":"A-1","vehicle_number":"B 1234 XYZ"}`)
+	if code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d %q", code, env.Message)
+	}
+	spotID := env.Data["spot_id"]
+	clock.Advance(2 * time.Hour)
+
+	// the spot ID is unknown without the ticket, the plate is enough
+	code, env = do(t, srv, http.MethodPost, "/parking/unpark", `{"vehicle_number":"b 1234 xyz","lost_ticket":true}`)
+	if code != http.StatusOK || env.Data["spot_id"] != spotID || env.Data["amount"] != float64(5000+3000+25000) || env.Data["lost_ticket"] != true {
+		t.Errorf("Expected 200 charging 33000 for the lost ticket at %v, got %d %v", spotID, code, env.Data)
+	}
+
+	if code, _ := do(t, srv, http.MethodPost, "/parking/unpark", `{"vehicle_number":"B1234XYZ","lost_ticket":true}`); code != http.StatusNotFound {
+		t.Errorf("Expected 404 unparking twice, got %d", code)
+	}
+
+	code, env = do(t, srv, http.MethodGet, "/parking/history?vehicle_number=B1234XYZ", "")
+	sessions, _ := env.Data["sessions"].([]any)
+	if code != http.StatusOK || len(sessions) != 1 || sessions[0].(map[string]any)["lost_ticket"] != true {
+		t.Errorf("Expected the session with its lost ticket, got %d %v", code, env.Data)
+	}
+}
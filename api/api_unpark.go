@@ -0,0 +1,66 @@
+package api
+
+import (
+	"encoding/json"
+	parkingpkg "github.com/mtfiqh/DoiT-parking-system/parking"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
+	"github.com/pkg/errors"
+	"net/http"
+)
+
+var errLostTicketNotSupported = errors.New("parking system does not unpark lost tickets")
+
+type unparkRequest struct {
+	SpotID        string              `json:"spot_id"`
+	VehicleNumber parkingentity.Plate `json:"vehicle_number"`
+	// Gate is the gate the vehicle leaves at, optional.
+	Gate string `json:"gate"`
+	// LostTicket unparks the vehicle by its plate without a spot ID, and charges the lost ticket penalty.
+	LostTicket bool `json:"lost_ticket"`
+}
+
+type unparkResponse struct {
+	SpotID     string `json:"spot_id"`
+	Duration   string `json:"duration"`
+	Amount     int64  `json:"amount"`
+	Currency   string `json:"currency,omitempty"`
+	LostTicket bool   `json:"lost_ticket,omitempty"`
+}
+
+func (s *Server) handleUnpark(w http.ResponseWriter, r *http.Request) {
+	var req unparkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, errInvalidRequestBody)
+		return
+	}
+
+	var (
+		receipt *parkingentity.Receipt
+		err     error
+	)
+	if req.LostTicket {
+		if unparker, ok := s.park.(parkingpkg.LostTicketUnparker); ok {
+			receipt, err = unparker.UnparkLostTicket(req.Gate, req.VehicleNumber)
+		} else {
+			err = errLostTicketNotSupported
+		}
+	} else if req.Gate == "" {
+		receipt, err = s.park.Unpark(req.SpotID, req.VehicleNumber)
+	} else if gated, ok := s.park.(parkingpkg.GateParker); ok {
+		receipt, err = gated.UnparkAt(req.Gate, req.SpotID, req.VehicleNumber)
+	} else {
+		err = errGatesNotSupported
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, unparkResponse{
+		SpotID:     s.spots.Format(receipt.Session.SpotID),
+		Duration:   receipt.Duration.String(),
+		Amount:     receipt.Amount,
+		Currency:   receipt.Currency,
+		LostTicket: receipt.Session.LostTicket,
+	}, "ok")
+}
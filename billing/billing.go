@@ -0,0 +1,175 @@
+// Package billing computes the charge of parking sessions from tariffs per vehicle type.
+package billing
+
+import (
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
+	"github.com/mtfiqh/DoiT-parking-system/pkg/clockx"
+	"github.com/pkg/errors"
+	"time"
+)
+
+var ErrNoTariff = errors.New("no tariff for vehicle type")
+
+// Tariff holds the rules to charge a vehicle type, amounts are in the smallest currency unit.
+type Tariff struct {
+	// FirstHour is charged for the first started hour.
+	FirstHour int64
+	// Hourly is charged for every following started hour.
+	Hourly int64
+	// NightHourly replaces Hourly for hours starting in the night window, 0 keeps Hourly.
+	NightHourly int64
+	// DailyCap is the maximum charge for every 24 hours from entry, 0 means no cap.
+	DailyCap int64
+	// Grace is the stay that is free of charge.
+	Grace time.Duration
+	// LostTicket is added when the ticket is lost.
+	LostTicket int64
+}
+
+// Config holds the tariffs of a parking lot.
+type Config struct {
+	Currency string
+	// Location is the time zone of the night window, nil uses the location of the session times.
+	Location *time.Location
+	// NightFrom and NightTo are the hours of the night window, it wraps midnight when NightFrom > NightTo.
+	// The window is disabled when both are equal.
+	NightFrom int
+	NightTo   int
+	Tariffs   map[parkingentity.VehicleType]Tariff
+}
+
+// Engine charges sessions with the configured tariffs.
+type Engine struct {
+	cfg   Config
+	clock clockx.Clock
+}
+
+// New returns an engine charging with cfg.
+func New(cfg Config, opts ...Option) *Engine {
+	e := &Engine{
+		cfg:   cfg,
+		clock: clockx.New(),
+	}
+	for _, o := range opts {
+		o(e)
+	}
+
+	return e
+}
+
+// Option is a function type that modifies the Engine.
+type Option func(*Engine)
+
+// WithClock sets the clock used to charge sessions that are still active.
+func WithClock(clock clockx.Clock) Option {
+	return func(e *Engine) {
+		e.clock = clock
+	}
+}
+
+type chargeOptions struct {
+	lostTicket bool
+}
+
+// ChargeOption is a function type that modifies a single charge.
+type ChargeOption func(*chargeOptions)
+
+// LostTicket adds the lost ticket penalty of the tariff to the charge, as for a session with LostTicket set.
+func LostTicket() ChargeOption {
+	return func(opt *chargeOptions) {
+		opt.lostTicket = true
+	}
+}
+
+// Charge computes the receipt of a session, an active session is charged until now.
+// A session with LostTicket set is charged the lost ticket penalty.
+func (e *Engine) Charge(session parkingentity.Session) (parkingentity.Receipt, error) {
+	return e.ChargeWith(session)
+}
+
+// ChargeWith computes the receipt of a session with extra charge options.
+func (e *Engine) ChargeWith(session parkingentity.Session, opts ...ChargeOption) (parkingentity.Receipt, error) {
+	opt := &chargeOptions{}
+	for _, o := range opts {
+		o(opt)
+	}
+
+	tariff, ok := e.cfg.Tariffs[session.VehicleType]
+	if !ok {
+		return parkingentity.Receipt{}, errors.Wrapf(ErrNoTariff, "vehicle type %s", session.VehicleType)
+	}
+
+	exitAt := session.ExitAt
+	if session.Active() {
+		exitAt = e.clock.Now()
+	}
+
+	duration := exitAt.Sub(session.EntryAt)
+	if duration < 0 {
+		duration = 0
+	}
+
+	receipt := parkingentity.Receipt{
+		Session:  session,
+		Duration: duration,
+		Currency: e.cfg.Currency,
+	}
+
+	if duration > tariff.Grace {
+		receipt.Amount = e.amount(tariff, session.EntryAt, duration)
+	}
+
+	if opt.lostTicket || session.LostTicket {
+		receipt.Amount += tariff.LostTicket
+	}
+
+	return receipt, nil
+}
+
+// amount charges every started hour, capped for every 24 hours from entry.
+func (e *Engine) amount(tariff Tariff, entryAt time.Time, duration time.Duration) int64 {
+	hours := int((duration + time.Hour - 1) / time.Hour)
+
+	var total, day int64
+	for h := 0; h < hours; h++ {
+		if h > 0 && h%24 == 0 {
+			total += e.capped(tariff, day)
+			day = 0
+		}
+
+		switch {
+		case h == 0:
+			day += tariff.FirstHour
+		case tariff.NightHourly > 0 && e.isNight(entryAt.Add(time.Duration(h)*time.Hour)):
+			day += tariff.NightHourly
+		default:
+			day += tariff.Hourly
+		}
+	}
+
+	return total + e.capped(tariff, day)
+}
+
+func (e *Engine) capped(tariff Tariff, amount int64) int64 {
+	if tariff.DailyCap > 0 && amount > tariff.DailyCap {
+		return tariff.DailyCap
+	}
+
+	return amount
+}
+
+func (e *Engine) isNight(t time.Time) bool {
+	if e.cfg.Location != nil {
+		t = t.In(e.cfg.Location)
+	}
+
+	from, to, hour := e.cfg.NightFrom, e.cfg.NightTo, t.Hour()
+	switch {
+	case from == to:
+		return false
+	case from < to:
+		return hour >= from && hour < to
+	default:
+		return hour >= from || hour < to
+	}
+}
@@ -0,0 +1,157 @@
+package billing_test
+
+import (
+	"errors"
+	"github.com/mtfiqh/DoiT-parking-system/billing"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
+	"github.com/mtfiqh/DoiT-parking-system/pkg/clockx"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var carTariff = billing.Tariff{
+	FirstHour:   5000,
+	Hourly:      3000,
+	NightHourly: 2000,
+	DailyCap:    40000,
+	Grace:       10 * time.Minute,
+	LostTicket:  25000,
+}
+
+func TestCharge(t *testing.T) {
+	morning := time.Date(2025, 1, 1, 8, 0, 0, 0, time.UTC)
+	evening := time.Date(2025, 1, 1, 20, 0, 0, 0, time.UTC)
+
+	engine := billing.New(billing.Config{
+		Currency:  "IDR",
+		Location:  time.UTC,
+		NightFrom: 22,
+		NightTo:   6,
+		Tariffs:   map[parkingentity.VehicleType]billing.Tariff{parkingentity.A1: carTariff},
+	})
+
+	testCases := []struct {
+		name       string
+		entryAt    time.Time
+		duration   time.Duration
+		lostTicket bool
+		expected   int64
+	}{
+		{name: "within grace", entryAt: morning, duration: 5 * time.Minute, expected: 0},
+		{name: "exactly grace", entryAt: morning, duration: 10 * time.Minute, expected: 0},
+		{name: "after grace charges the first hour", entryAt: morning, duration: 11 * time.Minute, expected: 5000},
+		{name: "one hour", entryAt: morning, duration: time.Hour, expected: 5000},
+		{name: "started hour is charged", entryAt: morning, duration: time.Hour + time.Minute, expected: 8000},
+		{name: "three hours", entryAt: morning, duration: 3 * time.Hour, expected: 11000},
+		{name: "night rate from 22", entryAt: evening, duration: 4 * time.Hour, expected: 5000 + 3000 + 2000 + 2000},
+		{name: "daily cap per 24 hours", entryAt: morning, duration: 30 * time.Hour, expected: 40000 + 6*3000},
+		{name: "lost ticket within grace", entryAt: morning, duration: 5 * time.Minute, lostTicket: true, expected: 25000},
+		{name: "lost ticket", entryAt: morning, duration: time.Hour, lostTicket: true, expected: 30000},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			session := parkingentity.Session{
+				VehicleType: parkingentity.A1,
+				EntryAt:     tc.entryAt,
+				ExitAt:      tc.entryAt.Add(tc.duration),
+			}
+
+			var opts []billing.ChargeOption
+			if tc.lostTicket {
+				opts = append(opts, billing.LostTicket())
+			}
+
+			receipt, err := engine.ChargeWith(session, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if receipt.Amount != tc.expected || receipt.Duration != tc.duration || receipt.Currency != "IDR" {
+				t.Errorf("Expected %d IDR for %v, got %d %s for %v", tc.expected, tc.duration, receipt.Amount, receipt.Currency, receipt.Duration)
+			}
+		})
+	}
+
+	t.Run("active session is charged until now", func(t *testing.T) {
+		clock := clockx.NewFake(morning.Add(2 * time.Hour))
+		engine := billing.New(billing.Config{Tariffs: map[parkingentity.VehicleType]billing.Tariff{parkingentity.A1: carTariff}}, billing.WithClock(clock))
+
+		receipt, err := engine.Charge(parkingentity.Session{VehicleType: parkingentity.A1, EntryAt: morning})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if receipt.Amount != 8000 || receipt.Duration != 2*time.Hour {
+			t.Errorf("Expected 8000 for 2h, got %d for %v", receipt.Amount, receipt.Duration)
+		}
+	})
+
+	t.Run("no tariff", func(t *testing.T) {
+		_, err := engine.Charge(parkingentity.Session{VehicleType: parkingentity.M1, EntryAt: morning, ExitAt: morning.Add(time.Hour)})
+		if !errors.Is(err, billing.ErrNoTariff) || !strings.Contains(err.Error(), "vehicle type M-1") {
+			t.Errorf("Expected %v for vehicle type M-1, got %v", billing.ErrNoTariff, err)
+		}
+	})
+}
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tariffs.json")
+	err := os.WriteFile(path, []byte(`{
+  "currency": "IDR",
+  "timezone": "UTC",
+  "night": {"from": 22, "to": 6},
+  "tariffs": {
+    "A-1": {"first_hour": 5000, "hourly": 3000, "night_hourly": 2000, "daily_cap": 40000, "grace": "10m", "lost_ticket": 25000},
+    "M-1": {"first_hour": 2000},
+    "B-1": {}
+  }
+}`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := billing.LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := billing.Config{
+		Currency:  "IDR",
+		Location:  time.UTC,
+		NightFrom: 22,
+		NightTo:   6,
+		Tariffs: map[parkingentity.VehicleType]billing.Tariff{
+			parkingentity.A1: carTariff,
+			parkingentity.M1: {FirstHour: 2000},
+			parkingentity.B1: {},
+		},
+	}
+	if !reflect.DeepEqual(cfg, expected) {
+		t.Errorf("Expected config %+v, got %+v", expected, cfg)
+	}
+
+	t.Run("missing vehicle type", func(t *testing.T) {
+		if err := os.WriteFile(path, []byte(`{"tariffs": {"A-1": {}, "B-1": {}}}`), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		_, err := billing.LoadConfig(path)
+		if !errors.Is(err, billing.ErrNoTariff) || !strings.Contains(err.Error(), "M-1") {
+			t.Errorf("Expected %v for M-1, got %v", billing.ErrNoTariff, err)
+		}
+	})
+
+	t.Run("unknown vehicle type", func(t *testing.T) {
+		if err := os.WriteFile(path, []byte(`{"tariffs": {"Z-9": {}}}`), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := billing.LoadConfig(path); !errors.Is(err, parkingentity.ErrInvalidVehicleType) {
+			t.Errorf("Expected %v, got %v", parkingentity.ErrInvalidVehicleType, err)
+		}
+	})
+}
@@ -0,0 +1,100 @@
+package billing
+
+import (
+	"encoding/json"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
+	"github.com/pkg/errors"
+	"os"
+	"time"
+)
+
+// configFile is the JSON layout of a tariff file:
+//
+//	{
+//	  "currency": "IDR",
+//	  "timezone": "Asia/Jakarta",
+//	  "night": {"from": 22, "to": 6},
+//	  "tariffs": {
+//	    "A-1": {"first_hour": 5000, "hourly": 3000, "night_hourly": 2000, "daily_cap": 40000, "grace": "10m", "lost_ticket": 25000}
+//	  }
+//	}
+type configFile struct {
+	Currency string `json:"currency"`
+	Timezone string `json:"timezone"`
+	Night    struct {
+		From int `json:"from"`
+		To   int `json:"to"`
+	} `json:"night"`
+	Tariffs map[string]struct {
+		FirstHour   int64  `json:"first_hour"`
+		Hourly      int64  `json:"hourly"`
+		NightHourly int64  `json:"night_hourly"`
+		DailyCap    int64  `json:"daily_cap"`
+		Grace       string `json:"grace"`
+		LostTicket  int64  `json:"lost_ticket"`
+	} `json:"tariffs"`
+}
+
+// LoadConfig reads the tariffs from a JSON file, every vehicle type that can be parked needs a tariff,
+// otherwise its vehicles could never be charged and unparked.
+func LoadConfig(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+
+	var f configFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return Config{}, errors.Wrapf(err, "parsing tariffs %s", path)
+	}
+
+	if f.Night.From < 0 || f.Night.From > 23 || f.Night.To < 0 || f.Night.To > 23 {
+		return Config{}, errors.Errorf("parsing tariffs %s: night hours must be between 0 and 23", path)
+	}
+
+	cfg := Config{
+		Currency:  f.Currency,
+		NightFrom: f.Night.From,
+		NightTo:   f.Night.To,
+		Tariffs:   make(map[parkingentity.VehicleType]Tariff, len(f.Tariffs)),
+	}
+
+	if f.Timezone != "" {
+		cfg.Location, err = time.LoadLocation(f.Timezone)
+		if err != nil {
+			return Config{}, errors.Wrapf(err, "parsing tariffs %s", path)
+		}
+	}
+
+	for code, t := range f.Tariffs {
+		vehicleType, err := parkingentity.ParseVehicleType(code)
+		if err != nil {
+			return Config{}, errors.Wrapf(err, "parsing tariffs %s: %q", path, code)
+		}
+
+		var grace time.Duration
+		if t.Grace != "" {
+			grace, err = time.ParseDuration(t.Grace)
+			if err != nil {
+				return Config{}, errors.Wrapf(err, "parsing tariffs %s: grace of %s", path, code)
+			}
+		}
+
+		cfg.Tariffs[vehicleType] = Tariff{
+			FirstHour:   t.FirstHour,
+			Hourly:      t.Hourly,
+			NightHourly: t.NightHourly,
+			DailyCap:    t.DailyCap,
+			Grace:       grace,
+			LostTicket:  t.LostTicket,
+		}
+	}
+
+	for _, vehicleType := range []parkingentity.VehicleType{parkingentity.M1, parkingentity.B1, parkingentity.A1} {
+		if _, ok := cfg.Tariffs[vehicleType]; !ok {
+			return Config{}, errors.Wrapf(ErrNoTariff, "parsing tariffs %s: %s", path, vehicleType)
+		}
+	}
+
+	return cfg, nil
+}
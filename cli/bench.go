@@ -0,0 +1,231 @@
+package cli
+
+import (
+	"encoding/json"
+	"github.com/mtfiqh/DoiT-parking-system/cli/parkingcli"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
+	"github.com/pkg/errors"
+	"io"
+	"math"
+	"math/bits"
+	"math/rand"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// BenchConfig holds the settings of a benchmark run, the operation mix is the one of the simulation:
+// 60% park, 30% unpark and 10% search.
+type BenchConfig struct {
+	Gates    int
+	Duration time.Duration
+	// ParkOptions are used to build the parking lot, e.g. parkingcli.WithRandomizeParkingSpots and parkingcli.WithStrategy.
+	ParkOptions []parkingcli.ParkOption
+	// Seed makes the lot, when randomized, and the operations of every gate the same from run to run.
+	Seed int64
+}
+
+// BenchReport is the result of a benchmark run, marshalled as JSON.
+type BenchReport struct {
+	Gates    int      `json:"gates"`
+	Duration Duration `json:"duration"`
+	// Spots is the number of spots of the lot before the run.
+	Spots int `json:"spots"`
+
+	Ops       int64   `json:"ops"`
+	OpsPerSec float64 `json:"ops_per_sec"`
+	// AllocsPerOp and BytesPerOp are the heap allocations of the whole process during the run per operation.
+	AllocsPerOp float64 `json:"allocs_per_op"`
+	BytesPerOp  float64 `json:"bytes_per_op"`
+
+	Operations map[string]OpReport `json:"operations"`
+}
+
+// OpReport is the throughput and latency of one kind of operation, the latencies are within 1/16 of the measured ones.
+type OpReport struct {
+	Ops       int64   `json:"ops"`
+	OpsPerSec float64 `json:"ops_per_sec"`
+	Errors    int64   `json:"errors"`
+	// Full is the parks turned away because the lot was full, they aren't errors.
+	Full int64    `json:"full,omitempty"`
+	P50  Duration `json:"p50"`
+	P99  Duration `json:"p99"`
+	Max  Duration `json:"max"`
+}
+
+// Duration marshals as a duration string such as "1.5µs".
+type Duration time.Duration
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// RunBenchmark runs the operations of the simulation on a new lot from every gate for the duration,
+// without the logging of the simulation, and measures every operation.
+func RunBenchmark(cfg BenchConfig) (BenchReport, error) {
+	if cfg.Gates < 1 || cfg.Duration <= 0 {
+		return BenchReport{}, errors.New("benchmark needs at least one gate and a positive duration")
+	}
+
+	// the options given may still draw the lot from their own seed
+	park, err := parkingcli.NewPark(append([]parkingcli.ParkOption{parkingcli.WithSeed(cfg.Seed)}, cfg.ParkOptions...)...)
+	if err != nil {
+		return BenchReport{}, errors.Wrap(err, "building the lot")
+	}
+
+	report := BenchReport{Gates: cfg.Gates, Duration: Duration(cfg.Duration), Operations: make(map[string]OpReport, len(operations))}
+	for _, vehicleType := range []parkingentity.VehicleType{parkingentity.M1, parkingentity.B1, parkingentity.A1} {
+		free, err := freeSpots(park, vehicleType)
+		if err != nil {
+			return BenchReport{}, err
+		}
+		report.Spots += free
+	}
+
+	gates := make([]benchGate, cfg.Gates)
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+
+	start := time.Now()
+	deadline := start.Add(cfg.Duration)
+
+	var wg sync.WaitGroup
+	for i := range gates {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = newGateWorker(park, i, rand.New(rand.NewSource(cfg.Seed+int64(i)))).run(deadline, gates[i].observe)
+		}()
+	}
+	wg.Wait()
+
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+
+	for op, name := range operations {
+		var (
+			latencies latencyHistogram
+			errs      int64
+		)
+		for i := range gates {
+			latencies.merge(&gates[i].latencies[op])
+			errs += gates[i].errors[op]
+		}
+		full := int64(0)
+		if name == "park" {
+			for i := range gates {
+				full += gates[i].full
+			}
+		}
+
+		report.Ops += latencies.count
+		report.Operations[name] = OpReport{
+			Ops:       latencies.count,
+			OpsPerSec: float64(latencies.count) / elapsed.Seconds(),
+			Errors:    errs,
+			Full:      full,
+			P50:       Duration(latencies.quantile(0.5)),
+			P99:       Duration(latencies.quantile(0.99)),
+			Max:       Duration(latencies.max),
+		}
+	}
+
+	report.OpsPerSec = float64(report.Ops) / elapsed.Seconds()
+	if report.Ops > 0 {
+		report.AllocsPerOp = float64(after.Mallocs-before.Mallocs) / float64(report.Ops)
+		report.BytesPerOp = float64(after.TotalAlloc-before.TotalAlloc) / float64(report.Ops)
+	}
+
+	return report, nil
+}
+
+// WriteJSON writes the report as indented JSON.
+func (r BenchReport) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// benchGate measures the operations of one gate worker.
+type benchGate struct {
+	latencies [len(operations)]latencyHistogram
+	errors    [len(operations)]int64
+	full      int64
+}
+
+func (g *benchGate) observe(o outcome) error {
+	g.latencies[o.op].record(o.took)
+	switch {
+	case o.full():
+		g.full++
+	case o.err != nil:
+		g.errors[o.op]++
+	}
+
+	return nil
+}
+
+// latencySubBits splits every power of two of nanoseconds in 16 buckets.
+const latencySubBits = 4
+
+// latencyHistogram counts latencies in log-linear buckets, so recording never allocates and a bucket is within 1/16 of its latencies.
+type latencyHistogram struct {
+	buckets [64 << latencySubBits]int64
+	count   int64
+	max     time.Duration
+}
+
+func (h *latencyHistogram) record(d time.Duration) {
+	h.buckets[latencyBucket(d)]++
+	h.count++
+	h.max = max(h.max, d)
+}
+
+func (h *latencyHistogram) merge(other *latencyHistogram) {
+	for i, n := range other.buckets {
+		h.buckets[i] += n
+	}
+	h.count += other.count
+	h.max = max(h.max, other.max)
+}
+
+// quantile returns the upper bound of the bucket holding the q quantile, never more than the slowest latency.
+func (h *latencyHistogram) quantile(q float64) time.Duration {
+	if h.count == 0 {
+		return 0
+	}
+
+	rank := int64(math.Ceil(q * float64(h.count)))
+	var seen int64
+	for i, n := range h.buckets {
+		seen += n
+		if seen >= rank {
+			return min(latencyUpperBound(i), h.max)
+		}
+	}
+
+	return h.max
+}
+
+// latencyBucket is the index of the bucket of d: its power of two, then the next latencySubBits bits.
+func latencyBucket(d time.Duration) int {
+	ns := uint64(max(d, 0))
+	if ns < 1<<latencySubBits {
+		return int(ns)
+	}
+
+	exp := bits.Len64(ns) - 1 - latencySubBits
+	return (exp+1)<<latencySubBits + int(ns>>exp) - 1<<latencySubBits
+}
+
+// latencyUpperBound is the largest latency of the bucket.
+func latencyUpperBound(bucket int) time.Duration {
+	if bucket < 1<<latencySubBits {
+		return time.Duration(bucket)
+	}
+
+	exp := bucket>>latencySubBits - 1
+	sub := bucket&(1<<latencySubBits-1) + 1<<latencySubBits
+	return time.Duration((uint64(sub)+1)<<exp - 1)
+}
@@ -0,0 +1,61 @@
+package cli_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/mtfiqh/DoiT-parking-system/cli"
+	"github.com/mtfiqh/DoiT-parking-system/cli/parkingcli"
+	"testing"
+	"time"
+)
+
+func TestRunBenchmark(t *testing.T) {
+	report, err := cli.RunBenchmark(cli.BenchConfig{
+		Gates:       4,
+		Duration:    200 * time.Millisecond,
+		ParkOptions: []parkingcli.ParkOption{parkingcli.WithRandomizeParkingSpots(2, 10, 10)},
+		Seed:        1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ops int64
+	for _, name := range []string{"park", "unpark", "search"} {
+		op, ok := report.Operations[name]
+		if !ok || op.Ops == 0 || op.Errors != 0 {
+			t.Fatalf("Expected %s operations without errors, got %+v", name, op)
+		}
+		if op.P50 <= 0 || op.P50 > op.P99 || op.P99 > op.Max {
+			t.Errorf("Expected 0 < p50 <= p99 <= max for %s, got %+v", name, op)
+		}
+		ops += op.Ops
+	}
+	if report.Ops != ops || report.OpsPerSec <= 0 || report.Spots == 0 || report.AllocsPerOp <= 0 {
+		t.Errorf("Expected the totals of %d operations, got %+v", ops, report)
+	}
+
+	var out bytes.Buffer
+	if err := report.WriteJSON(&out); err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded struct {
+		Gates      int                             `json:"gates"`
+		Duration   string                          `json:"duration"`
+		Operations map[string]struct{ P99 string } `json:"operations"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Gates != 4 || decoded.Duration != "200ms" {
+		t.Errorf("Expected 4 gates for 200ms, got %+v", decoded)
+	}
+	if p99, err := time.ParseDuration(decoded.Operations["park"].P99); err != nil || p99 <= 0 {
+		t.Errorf("Expected the park p99 as a duration, got %q", decoded.Operations["park"].P99)
+	}
+
+	if _, err := cli.RunBenchmark(cli.BenchConfig{Duration: time.Second}); err == nil {
+		t.Error("Expected an error without gates")
+	}
+}
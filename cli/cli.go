@@ -3,25 +3,90 @@ package cli
 import (
 	"context"
 	"fmt"
+	"github.com/mtfiqh/DoiT-parking-system/alerting"
 	"github.com/mtfiqh/DoiT-parking-system/cli/parkingcli"
+	parkingpkg "github.com/mtfiqh/DoiT-parking-system/parking"
 	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
-	"github.com/mtfiqh/DoiT-parking-system/pkg/randomizer"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingmetrics"
 	"github.com/pkg/errors"
 	"golang.org/x/sync/errgroup"
+	"io"
 	"log"
-	"sync"
+	"math/rand"
+	"strconv"
+	"sync/atomic"
 	"time"
 )
 
-func RunParkingSimulation(floor, column, row, gates int, duration time.Duration) error {
+// SimulationConfig holds the settings of a parking simulation run.
+type SimulationConfig struct {
+	// Gates is the number of operations running at the same time.
+	Gates    int
+	Duration time.Duration
+
+	// ParkOptions are used to build the parking lot, e.g. parkingcli.WithRandomizeParkingSpots or parkingcli.WithLayoutFile.
+	// With parkingcli.WithGates every operation goes through a random gate and the throughput of each gate is reported.
+	ParkOptions []parkingcli.ParkOption
+
+	// Metrics, when set, counts every operation and exposes the free spots of the lot.
+	Metrics *parkingmetrics.Metrics
+
+	// Alerts, when set, checks the occupancy of the lot every AlertInterval during the run.
+	Alerts        *alerting.Monitor
+	AlertInterval time.Duration
+
+	// Dashboard, when set, gets a live occupancy dashboard redrawn every second during the run instead of the log of every operation.
+	Dashboard io.Writer
+}
+
+// SpreadGates returns n gates for both directions spread along the first row of the ground floor, G1 to Gn.
+func SpreadGates(n, columns int) []parkingentity.Gate {
+	gates := make([]parkingentity.Gate, n)
+	for i := range gates {
+		gates[i] = parkingentity.Gate{
+			ID:   "G" + strconv.Itoa(i+1),
+			Spot: parkingentity.Spot{Col: i * columns / n},
+		}
+	}
+
+	return gates
+}
+
+// gateStats counts the operations of a gate.
+type gateStats struct {
+	parks   atomic.Int64
+	unparks atomic.Int64
+	full    atomic.Int64
+}
+
+func RunParkingSimulation(cfg SimulationConfig) error {
+	gates, duration := cfg.Gates, cfg.Duration
 
 	log.Println("Running parking simulation...")
-	log.Printf("floor: %d, column: %d, row: %d, gates: %d", floor, column, row, gates)
+	log.Printf("gates: %d, duration: %v", gates, duration)
 
-	park, err := parkingcli.NewPark(parkingcli.WithRandomizeParkingSpots(floor, column, row))
+	park, err := parkingcli.NewPark(append(cfg.ParkOptions, parkingcli.WithMetrics(cfg.Metrics))...)
 	if err != nil {
 		log.Fatal(err)
 	}
+	if reporter, ok := park.(parkingpkg.OccupancyReporter); ok && cfg.Metrics != nil {
+		cfg.Metrics.Watch(reporter)
+	}
+	if reporter, ok := park.(parkingpkg.OccupancyReporter); ok && cfg.Alerts != nil {
+		defer cfg.Alerts.Start(reporter, cfg.AlertInterval)()
+	}
+	format := parkingpkg.SpotFormatOf(park)
+
+	// operations go through a random gate when the lot knows its gates
+	var gateList []parkingentity.Gate
+	gated, _ := park.(parkingpkg.GateParker)
+	if gated != nil {
+		gateList = gated.Gates()
+	}
+	stats := make(map[string]*gateStats, len(gateList))
+	for _, gate := range gateList {
+		stats[gate.ID] = new(gateStats)
+	}
 
 	// get initial available spots
 	errg, _ := errgroup.WithContext(context.Background())
@@ -31,21 +96,21 @@ func RunParkingSimulation(floor, column, row, gates int, duration time.Duration)
 		m1countChan = make(chan int, 1)
 	)
 	errg.Go(func() error {
-		a1Count, _ := park.AvailableSpot(parkingentity.A1)
+		a1Count, err := freeSpots(park, parkingentity.A1)
 		a1countChan <- a1Count
-		return nil
+		return err
 	})
 
 	errg.Go(func() error {
-		b1Count, _ := park.AvailableSpot(parkingentity.B1)
+		b1Count, err := freeSpots(park, parkingentity.B1)
 		b1countChan <- b1Count
-		return nil
+		return err
 	})
 
 	errg.Go(func() error {
-		m1Count, _ := park.AvailableSpot(parkingentity.M1)
+		m1Count, err := freeSpots(park, parkingentity.M1)
 		m1countChan <- m1Count
-		return nil
+		return err
 	})
 
 	if err := errg.Wait(); err != nil {
@@ -69,148 +134,113 @@ func RunParkingSimulation(floor, column, row, gates int, duration time.Duration)
 	tnow := time.Now().Local()
 	tend := tnow.Add(duration)
 
-	wg, _ := errgroup.WithContext(context.Background())
+	var completed atomic.Int64
+
+	var dashboardDone, dashboardStopped chan struct{}
+	logOutput := log.Writer()
+	if reporter, ok := park.(parkingpkg.OccupancyReporter); ok && cfg.Dashboard != nil {
+		log.SetOutput(io.Discard)
+
+		dashboardDone, dashboardStopped = make(chan struct{}), make(chan struct{})
+		go func() {
+			defer close(dashboardStopped)
+			NewDashboard(reporter, completed.Load).Run(cfg.Dashboard, time.Second, dashboardDone)
+		}()
+	}
 
-	wg.SetLimit(gates) // how many gates to simulate
-
-	parked := make(map[int]parkingentity.SpotID)
-	mu := new(sync.RWMutex)
-
-	i := 0
-
-	/**
-	function to simulate parking operations
-	with chance of
-	- parking: 60%
-	- unparking: 30%
-	- searching: 10%
-	 **/
-	for time.Now().Local().Before(tend) {
-		i++
-		func(i int) {
-			wg.Go(func() error {
-				op := randomizer.RandomizeInt(1, 10)
-
-				// parking operation
-				if op <= 6 {
-					vehicleNum := 10000 + i
-					vehicleType := randomizer.RandomizeEnum(parkingentity.M1, parkingentity.B1, parkingentity.A1)
-
-					spotID, err := park.Park(vehicleType, vehicleNum)
-					if err != nil {
-						switch errors.Cause(err) {
-						case parkingentity.ErrSpotNotFound:
-							// means full, do nothing
-							log.Printf("Parking full for vehicle %d of type %d", vehicleNum, vehicleType)
-						default:
-							err = errors.Wrap(err, fmt.Sprintf("parking vehicle %d of type %d", vehicleNum, vehicleType))
-							log.Println("Error parking vehicle:", err)
-							return err
-						}
-
-						return nil
-					}
-
-					mu.Lock()
-					defer mu.Unlock()
-					parked[vehicleNum] = *spotID
-
-					log.Printf("parked vehicle: %v, in: %v", vehicleNum, spotID.ID())
-					return nil
-				}
-
-				// unparking operation
-				if op <= 9 {
-					mu.Lock()
-					defer mu.Unlock()
-
-					// if empty, unpark nothing
-					if len(parked) == 0 {
-						return nil
-					}
-
-					// get a random vehicle to unpark
-					// todo optimize
-					keys := make([]int, 0, len(parked))
-					for k := range parked {
-						keys = append(keys, k)
-					}
-
-					vehicleNum := randomizer.RandomizeEnum(keys...)
-
-					// unpark method
-					err := park.Unpark(parked[vehicleNum].ID(), vehicleNum)
-					if err != nil {
-						err = errors.Wrap(err, fmt.Sprintf("unparking vehicle %d", vehicleNum))
-						log.Println("Error unparking vehicle:", err)
-						return err
-					}
-
-					// delete in parked map
-					delete(parked, vehicleNum)
-
-					log.Println("Unparked vehicle:", vehicleNum)
-					return nil
-				}
-
-				// searching operation
-				if op <= 10 {
-					mu.RLock()
-
-					keys := make([]int, 0, len(parked))
-
-					if len(parked) == 0 {
-						mu.RUnlock()
-						return nil
-					}
-
-					for k := range parked {
-						keys = append(keys, k)
-					}
-
-					vehicleNum := randomizer.RandomizeEnum(keys...)
-					mu.RUnlock()
-
-					spotID, err := park.SearchVehicle(vehicleNum)
-					if err != nil {
-						err = errors.Wrap(err, fmt.Sprintf("searching vehicle %d", vehicleNum))
-						log.Println("Error searching vehicle:", err)
-						return err
-					}
-
-					if spotID == nil {
-						return errors.New("spotID empty")
-					} else {
-						log.Printf("Vehicle %d found at spot %s", vehicleNum, spotID.ID())
-					}
-
-					return nil
-				}
-
-				return nil
-			})
-
-		}(i)
+	// logs an operation and counts it for its gate, an error other than a full lot stops the worker
+	observe := func(o outcome) error {
+		defer completed.Add(1)
+
+		stat := stats[o.gate]
+		if o.full() {
+			if stat != nil {
+				stat.full.Add(1)
+			}
+			log.Printf("Parking full for vehicle %s of type %d", o.vehicle.number, o.vehicle.vehicleType)
+			return nil
+		}
+
+		switch o.op {
+		case opPark:
+			if o.err != nil {
+				err := errors.Wrap(o.err, fmt.Sprintf("parking vehicle %s of type %d", o.vehicle.number, o.vehicle.vehicleType))
+				log.Println("Error parking vehicle:", err)
+				return err
+			}
+			if stat != nil {
+				stat.parks.Add(1)
+			}
+			log.Printf("parked vehicle: %v, in: %v", o.vehicle.number, format.Format(o.vehicle.spotID))
+
+		case opUnpark:
+			if o.err != nil {
+				err := errors.Wrap(o.err, fmt.Sprintf("unparking vehicle %s", o.vehicle.number))
+				log.Println("Error unparking vehicle:", err)
+				return err
+			}
+			if stat != nil {
+				stat.unparks.Add(1)
+			}
+			log.Println("Unparked vehicle:", o.vehicle.number)
+
+		case opSearch:
+			if o.err != nil {
+				err := errors.Wrap(o.err, fmt.Sprintf("searching vehicle %s", o.vehicle.number))
+				log.Println("Error searching vehicle:", err)
+				return err
+			}
+			log.Printf("Vehicle %s found at spot %s", o.vehicle.number, format.Format(o.vehicle.spotID))
+		}
+
+		return nil
+	}
+
+	// every simulated gate runs the operation mix of the simulation until the end, see gateWorker
+	workers := make([]*gateWorker, gates)
+	wg, _ := errgroup.WithContext(context.Background())
+	for i := range workers {
+		workers[i] = newGateWorker(park, i+1, rand.New(rand.NewSource(tnow.UnixNano()+int64(i))))
+		wg.Go(func() error {
+			return workers[i].run(tend, observe)
+		})
 	}
 
-	if err := wg.Wait(); err != nil {
+	err = wg.Wait()
+	if dashboardDone != nil {
+		close(dashboardDone)
+		<-dashboardStopped
+		log.SetOutput(logOutput)
+	}
+	if err != nil {
 		log.Fatal(errors.Wrap(err, "error in parking simulation"))
 		return err
 	}
 
 	log.Println("Parking simulation completed successfully.")
-	a1, _ := park.AvailableSpot(parkingentity.A1)
-	b1, _ := park.AvailableSpot(parkingentity.B1)
-	m1, _ := park.AvailableSpot(parkingentity.M1)
+	a1, _ := freeSpots(park, parkingentity.A1)
+	b1, _ := freeSpots(park, parkingentity.B1)
+	m1, _ := freeSpots(park, parkingentity.M1)
 	log.Println("RESULT:")
 	log.Printf("before: A1: %d, B1: %d, M1: %d", beforeA1, beforeB1, beforeM1)
 	log.Printf("after: A1: %d, B1: %d, M1: %d", a1, b1, m1)
-	log.Printf("remaining vehicles parked: %d", len(parked))
+	remaining := 0
+	for _, worker := range workers {
+		remaining += len(worker.parked)
+	}
+	log.Printf("remaining vehicles parked: %d", remaining)
 
 	totalBefore := beforeA1 + beforeB1 + beforeM1
 	totalAfter := a1 + b1 + m1
-	log.Printf("total spots: %d, total free spots: %d, remaining + free spots: %d", totalBefore, totalAfter, totalAfter+len(parked))
-	log.Printf("total executions: %d", i)
+	log.Printf("total spots: %d, total free spots: %d, remaining + free spots: %d", totalBefore, totalAfter, totalAfter+remaining)
+	log.Printf("total executions: %d", completed.Load())
+
+	elapsed := time.Since(tnow).Seconds()
+	for _, gate := range gateList {
+		s := stats[gate.ID]
+		log.Printf("gate %s at %s: %d parks, %d unparks, %d turned away full, %.1f vehicles/s",
+			gate.ID, format.Format(parkingentity.SpotID(gate.Spot)), s.parks.Load(), s.unparks.Load(), s.full.Load(), float64(s.parks.Load()+s.unparks.Load())/elapsed)
+	}
 
 	return nil
 }
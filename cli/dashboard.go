@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"fmt"
+	parkingpkg "github.com/mtfiqh/DoiT-parking-system/parking"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	// heatLevels shades a cell of the heatmap from empty to full.
+	heatLevels = " .:-=+*#%@"
+	// heatWidth is the most cells of a floor's heatmap, rows are grouped into cells on bigger floors.
+	heatWidth = 40
+
+	clearScreen = "\x1b[H\x1b[2J"
+)
+
+// Dashboard renders how full a lot is as a text grid: spots per vehicle type, then every floor with a heatmap of its rows.
+type Dashboard struct {
+	park parkingpkg.OccupancyReporter
+	// ops returns the operations done so far, nil when unknown.
+	ops func() int64
+
+	lastOps int64
+	lastAt  time.Time
+}
+
+// NewDashboard returns a dashboard of the lot, ops returns the operations done so far to show the operations per second.
+func NewDashboard(park parkingpkg.OccupancyReporter, ops func() int64) *Dashboard {
+	return &Dashboard{park: park, ops: ops}
+}
+
+// Run clears out and renders a frame every interval until done is closed, then renders the last frame.
+func (d *Dashboard) Run(out io.Writer, interval time.Duration, done <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		fmt.Fprint(out, clearScreen)
+		d.Render(out, time.Now())
+
+		select {
+		case <-done:
+			fmt.Fprint(out, clearScreen)
+			d.Render(out, time.Now())
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Render writes one frame at now, the operations per second are counted since the previous frame.
+func (d *Dashboard) Render(out io.Writer, now time.Time) {
+	occupancy := d.park.Occupancy()
+
+	fmt.Fprintf(out, "parking lot at %s", now.Format(time.TimeOnly))
+	if d.ops != nil {
+		ops := d.ops()
+		rate := 0.0
+		if !d.lastAt.IsZero() && now.After(d.lastAt) {
+			rate = float64(ops-d.lastOps) / now.Sub(d.lastAt).Seconds()
+		}
+		d.lastOps, d.lastAt = ops, now
+
+		fmt.Fprintf(out, ", %d operations, %.1f ops/s", ops, rate)
+	}
+	fmt.Fprint(out, "\n\n")
+
+	types := occupancy.Types()
+	fmt.Fprintf(out, "%-5s %8s %8s %8s %8s\n", "type", "total", "free", "occupied", "held")
+	for _, spotType := range []parkingentity.VehicleType{parkingentity.M1, parkingentity.B1, parkingentity.A1} {
+		c := types[spotType]
+		fmt.Fprintf(out, "%-5s %8d %8d %8d %8d\n", spotType, c.Total, c.Free(), c.Occupied, c.Held)
+	}
+	fmt.Fprintf(out, "disabled: %d\n\n", types[parkingentity.X0].Total)
+
+	fmt.Fprintf(out, "%-5s %15s %6s %6s %6s %8s  %s\n", "floor", "occupied", "M-1", "B-1", "A-1", "disabled", "rows")
+	for _, floor := range occupancy.Floors {
+		enabled := floor.Enabled()
+		fmt.Fprintf(out, "%-5d %15s %6d %6d %6d %8d  [%s]\n",
+			floor.Floor, fmt.Sprintf("%d/%d %3.0f%%", enabled.Occupied, enabled.Total, percent(enabled.Occupied, enabled.Total)),
+			floor.Types[parkingentity.M1].Free(), floor.Types[parkingentity.B1].Free(), floor.Types[parkingentity.A1].Free(),
+			floor.Types[parkingentity.X0].Total, heatmap(floor.Rows))
+	}
+}
+
+// heatmap shades the rows of a floor by how many of their enabled spots are occupied or held, x when a cell has none.
+func heatmap(rows []parkingentity.SpotCount) string {
+	cells := min(len(rows), heatWidth)
+
+	var b strings.Builder
+	for cell := 0; cell < cells; cell++ {
+		var c parkingentity.SpotCount
+		for _, row := range rows[cell*len(rows)/cells : (cell+1)*len(rows)/cells] {
+			c = c.Add(row)
+		}
+
+		if c.Total == 0 {
+			b.WriteByte('x')
+			continue
+		}
+
+		level := (c.Occupied + c.Held) * (len(heatLevels) - 1) / c.Total
+		b.WriteByte(heatLevels[level])
+	}
+
+	return b.String()
+}
+
+func percent(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(n) * 100 / float64(total)
+}
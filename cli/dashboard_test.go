@@ -0,0 +1,68 @@
+package cli_test
+
+import (
+	"bytes"
+	"github.com/mtfiqh/DoiT-parking-system/cli"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fixedOccupancy parkingentity.Occupancy
+
+func (o fixedOccupancy) Occupancy() parkingentity.Occupancy {
+	return parkingentity.Occupancy(o)
+}
+
+func TestDashboard(t *testing.T) {
+	lot := fixedOccupancy{Floors: []parkingentity.FloorOccupancy{
+		{
+			Floor: 0,
+			Types: map[parkingentity.VehicleType]parkingentity.SpotCount{
+				parkingentity.A1: {Total: 4, Occupied: 3, Held: 1},
+				parkingentity.M1: {Total: 4, Occupied: 1},
+				parkingentity.X0: {Total: 2},
+			},
+			Rows: []parkingentity.SpotCount{{Total: 4, Occupied: 4}, {Total: 4, Held: 1}, {}},
+		},
+		{
+			Floor: 1,
+			Types: map[parkingentity.VehicleType]parkingentity.SpotCount{parkingentity.B1: {Total: 5}},
+			Rows:  []parkingentity.SpotCount{{Total: 5}},
+		},
+	}}
+
+	ops := int64(100)
+	dashboard := cli.NewDashboard(lot, func() int64 { return ops })
+	t0 := time.Date(2025, 1, 1, 8, 0, 0, 0, time.UTC)
+	dashboard.Render(new(bytes.Buffer), t0)
+
+	ops = 350
+	var out bytes.Buffer
+	dashboard.Render(&out, t0.Add(2*time.Second))
+
+	expected := []string{
+		"parking lot at 08:00:02, 350 operations, 125.0 ops/s",
+		"",
+		"type     total     free occupied     held",
+		"M-1          4        3        1        0",
+		"B-1          5        5        0        0",
+		"A-1          4        0        3        1",
+		"disabled: 2",
+		"",
+		"floor        occupied    M-1    B-1    A-1 disabled  rows",
+		"0            4/8  50%      3      0      0        2  [@:x]",
+		"1            0/5   0%      0      5      0        0  [ ]",
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != len(expected) {
+		t.Fatalf("Expected %d lines, got %d:\n%s", len(expected), len(lines), out.String())
+	}
+	for i, line := range lines {
+		if line != expected[i] {
+			t.Errorf("Line %d: expected %q, got %q", i+1, expected[i], line)
+		}
+	}
+}
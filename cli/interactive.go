@@ -0,0 +1,422 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	parkingpkg "github.com/mtfiqh/DoiT-parking-system/parking"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultAvailableLimit = 10
+
+const interactiveHelp = `commands:
+  park <vehicle type> <vehicle number>    park a vehicle, e.g. park A-1 B 1234 XYZ
+  unpark <spot id> <vehicle number>       unpark a vehicle, e.g. unpark 1-2-10 B 1234 XYZ
+  lost-ticket <vehicle number>            unpark a vehicle without its ticket, charging the lost ticket penalty
+  available <vehicle type> [limit] [floor=<n>] [rows=<from>-<to>] [near=<gate id>] [within=<n>] [cursor=<cursor>]
+                                          show a page of the available spots for a vehicle type, e.g. available A-1 5 floor=1
+  search <vehicle number>                 show the last spot of a vehicle
+  history <vehicle number>                show every parking session of a vehicle
+  reserve <vehicle type> <window> <vehicle number>
+                                          hold a spot for a vehicle, e.g. reserve A-1 30m B 1234 XYZ
+  claim <vehicle number>                  park a vehicle at its held spot
+  cancel <vehicle number>                 cancel the reservation of a vehicle
+  gate [gate id]                          park and unpark at the gate from now on, list the gates without an id
+  disable <spot id>                       close a free spot, e.g. for maintenance
+  enable <spot id> <vehicle type>         reopen a disabled spot for a vehicle type
+  retype <spot id> <vehicle type>         change the vehicle type of a free spot
+  status                                  show available spots of every vehicle type
+  help                                    show this help
+  exit                                    quit
+`
+
+// RunInteractive reads parking commands line by line from in and writes the results to out.
+// When prompt is true a prompt is printed before each line, otherwise the input is treated as a script.
+// Lines starting with # are ignored.
+func RunInteractive(park parkingpkg.ParkingSystem, in io.Reader, out io.Writer, prompt bool) error {
+	scanner := bufio.NewScanner(in)
+	// gate is the gate park and unpark go through, empty for none
+	var gate string
+
+	for {
+		if prompt {
+			fmt.Fprint(out, "> ")
+		}
+
+		if !scanner.Scan() {
+			break
+		}
+
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		args := strings.Fields(line)
+		if args[0] == "exit" || args[0] == "quit" {
+			return nil
+		}
+
+		if err := runCommand(park, out, args, &gate); err != nil {
+			fmt.Fprintf(out, "error: %v\n", err)
+		}
+	}
+
+	return scanner.Err()
+}
+
+func runCommand(park parkingpkg.ParkingSystem, out io.Writer, args []string, gate *string) error {
+	format := parkingpkg.SpotFormatOf(park)
+
+	switch args[0] {
+	case "park":
+		if len(args) < 3 {
+			return fmt.Errorf("usage: park <vehicle type> <vehicle number>")
+		}
+
+		vehicleType, err := parkingentity.ParseVehicleType(args[1])
+		if err != nil {
+			return err
+		}
+
+		vehicleNumber := parsePlate(args[2:])
+
+		var spotID *parkingentity.SpotID
+		if *gate == "" {
+			spotID, err = park.Park(vehicleType, vehicleNumber)
+		} else {
+			spotID, err = park.(parkingpkg.GateParker).ParkAt(*gate, vehicleType, vehicleNumber)
+		}
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(out, "parked vehicle %s at %s\n", vehicleNumber, format.Format(*spotID))
+
+	case "unpark":
+		if len(args) < 3 {
+			return fmt.Errorf("usage: unpark <spot id> <vehicle number>")
+		}
+
+		vehicleNumber := parsePlate(args[2:])
+
+		var (
+			receipt *parkingentity.Receipt
+			err     error
+		)
+		if *gate == "" {
+			receipt, err = park.Unpark(args[1], vehicleNumber)
+		} else {
+			receipt, err = park.(parkingpkg.GateParker).UnparkAt(*gate, args[1], vehicleNumber)
+		}
+		if err != nil {
+			return err
+		}
+
+		charge := strings.TrimSpace(fmt.Sprintf("%d %s", receipt.Amount, receipt.Currency))
+		fmt.Fprintf(out, "unparked vehicle %s from %s after %s, charge %s\n", vehicleNumber, format.Format(receipt.Session.SpotID), receipt.Duration.Round(time.Second), charge)
+
+	case "lost-ticket":
+		if len(args) < 2 {
+			return fmt.Errorf("usage: lost-ticket <vehicle number>")
+		}
+
+		unparker, ok := park.(parkingpkg.LostTicketUnparker)
+		if !ok {
+			return fmt.Errorf("parking system does not unpark lost tickets")
+		}
+
+		vehicleNumber := parsePlate(args[1:])
+
+		receipt, err := unparker.UnparkLostTicket(*gate, vehicleNumber)
+		if err != nil {
+			return err
+		}
+
+		charge := strings.TrimSpace(fmt.Sprintf("%d %s", receipt.Amount, receipt.Currency))
+		fmt.Fprintf(out, "unparked vehicle %s without ticket from %s after %s, charge %s\n", vehicleNumber, format.Format(receipt.Session.SpotID), receipt.Duration.Round(time.Second), charge)
+
+	case "available":
+		if len(args) < 2 {
+			return fmt.Errorf("usage: available <vehicle type> [limit] [floor=<n>] [rows=<from>-<to>] [near=<gate id>] [within=<n>] [cursor=<cursor>]")
+		}
+
+		query, limit, err := parseSpotQuery(park, args[1:])
+		if err != nil {
+			return err
+		}
+
+		page, err := parkingpkg.QuerySpotsOf(park, query)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(out, "%s: %d available\n", query.VehicleType, page.Total)
+		if limit == 0 {
+			break
+		}
+		for _, spot := range page.Spots {
+			fmt.Fprintf(out, "  %s\n", format.Format(parkingentity.SpotID(spot)))
+		}
+		if page.Next != "" {
+			fmt.Fprintf(out, "  more with cursor=%s\n", page.Next)
+		}
+
+	case "search":
+		if len(args) < 2 {
+			return fmt.Errorf("usage: search <vehicle number>")
+		}
+
+		vehicleNumber := parsePlate(args[1:])
+
+		spotID, err := park.SearchVehicle(vehicleNumber)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(out, "vehicle %s last parked at %s\n", vehicleNumber, format.Format(*spotID))
+
+	case "history":
+		if len(args) < 2 {
+			return fmt.Errorf("usage: history <vehicle number>")
+		}
+
+		history, ok := park.(parkingpkg.SessionHistory)
+		if !ok {
+			return fmt.Errorf("parking system does not keep session history")
+		}
+
+		vehicleNumber := parsePlate(args[1:])
+
+		sessions, err := history.History(vehicleNumber)
+		if err != nil {
+			return err
+		}
+
+		for _, session := range sessions {
+			exit := "still parked"
+			if !session.Active() {
+				exit = session.ExitAt.Format(time.DateTime) + " (" + session.ExitAt.Sub(session.EntryAt).Round(time.Second).String() + ")"
+			}
+			fmt.Fprintf(out, "  %s  %s -> %s\n", format.Format(session.SpotID), session.EntryAt.Format(time.DateTime), exit)
+		}
+
+	case "reserve":
+		if len(args) < 4 {
+			return fmt.Errorf("usage: reserve <vehicle type> <window> <vehicle number>")
+		}
+
+		reserver, ok := park.(parkingpkg.Reserver)
+		if !ok {
+			return fmt.Errorf("parking system does not support reservations")
+		}
+
+		vehicleType, err := parkingentity.ParseVehicleType(args[1])
+		if err != nil {
+			return err
+		}
+
+		window, err := time.ParseDuration(args[2])
+		if err != nil {
+			return fmt.Errorf("invalid window %q", args[2])
+		}
+
+		reservation, err := reserver.Reserve(vehicleType, parsePlate(args[3:]), window)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(out, "reserved %s for vehicle %s until %s\n", format.Format(reservation.SpotID), reservation.VehicleNumber, reservation.ExpiresAt.Format(time.DateTime))
+
+	case "claim":
+		if len(args) < 2 {
+			return fmt.Errorf("usage: claim <vehicle number>")
+		}
+
+		reserver, ok := park.(parkingpkg.Reserver)
+		if !ok {
+			return fmt.Errorf("parking system does not support reservations")
+		}
+
+		vehicleNumber := parsePlate(args[1:])
+
+		spotID, err := reserver.ClaimReservation(vehicleNumber)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(out, "parked vehicle %s at %s\n", vehicleNumber, format.Format(*spotID))
+
+	case "cancel":
+		if len(args) < 2 {
+			return fmt.Errorf("usage: cancel <vehicle number>")
+		}
+
+		reserver, ok := park.(parkingpkg.Reserver)
+		if !ok {
+			return fmt.Errorf("parking system does not support reservations")
+		}
+
+		vehicleNumber := parsePlate(args[1:])
+
+		if err := reserver.CancelReservation(vehicleNumber); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(out, "cancelled the reservation of vehicle %s\n", vehicleNumber)
+
+	case "gate":
+		gated, ok := park.(parkingpkg.GateParker)
+		if !ok {
+			return fmt.Errorf("parking system does not know its gates")
+		}
+
+		if len(args) < 2 {
+			for _, g := range gated.Gates() {
+				fmt.Fprintf(out, "  %s  %s at %s\n", g.ID, g.Kind, format.Format(parkingentity.SpotID(g.Spot)))
+			}
+			return nil
+		}
+
+		if !slices.ContainsFunc(gated.Gates(), func(g parkingentity.Gate) bool { return g.ID == args[1] }) {
+			return parkingentity.ErrUnknownGate
+		}
+
+		*gate = args[1]
+		fmt.Fprintf(out, "using gate %s\n", *gate)
+
+	case "disable":
+		if len(args) < 2 {
+			return fmt.Errorf("usage: disable <spot id>")
+		}
+
+		admin, ok := park.(parkingpkg.SpotAdmin)
+		if !ok {
+			return fmt.Errorf("parking system does not support changing spots")
+		}
+
+		if err := admin.DisableSpot(args[1]); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(out, "disabled spot %s\n", args[1])
+
+	case "enable", "retype":
+		if len(args) < 3 {
+			return fmt.Errorf("usage: %s <spot id> <vehicle type>", args[0])
+		}
+
+		admin, ok := park.(parkingpkg.SpotAdmin)
+		if !ok {
+			return fmt.Errorf("parking system does not support changing spots")
+		}
+
+		spotType, err := parkingentity.ParseVehicleType(args[2])
+		if err != nil {
+			return err
+		}
+
+		change := admin.EnableSpot
+		if args[0] == "retype" {
+			change = admin.RetypeSpot
+		}
+		if err := change(args[1], spotType); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(out, "spot %s is now %s\n", args[1], spotType)
+
+	case "status":
+		for _, vehicleType := range []parkingentity.VehicleType{parkingentity.M1, parkingentity.B1, parkingentity.A1} {
+			total, err := freeSpots(park, vehicleType)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "%s: %d available\n", vehicleType, total)
+		}
+
+	case "help":
+		fmt.Fprint(out, interactiveHelp)
+
+	default:
+		return fmt.Errorf("unknown command %q, type help for the list of commands", args[0])
+	}
+
+	return nil
+}
+
+// parseSpotQuery parses the arguments of available: the vehicle type, an optional limit and the filters as name=value.
+// The limit is how many spots are shown, 0 only shows the count. The floor and rows are numbered like the printed spot IDs.
+func parseSpotQuery(park parkingpkg.ParkingSystem, args []string) (parkingentity.SpotQuery, int, error) {
+	format := parkingpkg.SpotFormatOf(park)
+
+	vehicleType, err := parkingentity.ParseVehicleType(args[0])
+	if err != nil {
+		return parkingentity.SpotQuery{}, 0, err
+	}
+
+	query := parkingentity.SpotQuery{VehicleType: vehicleType, Limit: defaultAvailableLimit}
+	args = args[1:]
+	if len(args) > 0 && !strings.Contains(args[0], "=") {
+		query.Limit, err = strconv.Atoi(args[0])
+		if err != nil || query.Limit < 0 {
+			return parkingentity.SpotQuery{}, 0, fmt.Errorf("invalid limit %q", args[0])
+		}
+		args = args[1:]
+	}
+	limit := query.Limit
+	if limit == 0 {
+		// a page of one spot still counts every spot
+		query.Limit = 1
+	}
+
+	for _, arg := range args {
+		name, value, _ := strings.Cut(arg, "=")
+		switch name {
+		case "floor":
+			floor, err := format.ParseFloor(value)
+			if err != nil {
+				return parkingentity.SpotQuery{}, 0, parkingentity.ErrInvalidSpotQuery
+			}
+			query.Floor = &floor
+		case "rows":
+			rows, err := format.ParseRowRange(value)
+			if err != nil {
+				return parkingentity.SpotQuery{}, 0, err
+			}
+			query.Rows = &rows
+		case "near":
+			gate, err := parkingpkg.GateOf(park, value)
+			if err != nil {
+				return parkingentity.SpotQuery{}, 0, err
+			}
+			query.Near = &gate.Spot
+		case "within":
+			if query.Within, err = strconv.Atoi(value); err != nil {
+				return parkingentity.SpotQuery{}, 0, parkingentity.ErrInvalidSpotQuery
+			}
+		case "cursor":
+			query.Cursor = value
+		default:
+			return parkingentity.SpotQuery{}, 0, fmt.Errorf("unknown filter %q, expected floor, rows, near, within or cursor", arg)
+		}
+	}
+
+	return query, limit, nil
+}
+
+// freeSpots counts the free spots of the vehicle type without copying them.
+func freeSpots(park parkingpkg.ParkingSystem, vehicleType parkingentity.VehicleType) (int, error) {
+	page, err := parkingpkg.QuerySpotsOf(park, parkingentity.SpotQuery{VehicleType: vehicleType, Limit: 1})
+	return page.Total, err
+}
+
+// parsePlate joins the arguments of a plate typed with spaces, e.g. B 1234 XYZ.
+func parsePlate(args []string) parkingentity.Plate {
+	return parkingentity.NormalizePlate(strings.Join(args, " "))
+}
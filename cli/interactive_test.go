@@ -0,0 +1,140 @@
+package cli_test
+
+import (
+	"bytes"
+	"github.com/mtfiqh/DoiT-parking-system/billing"
+	"github.com/mtfiqh/DoiT-parking-system/cli"
+	"github.com/mtfiqh/DoiT-parking-system/cli/parkingcli"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
+	"github.com/mtfiqh/DoiT-parking-system/pkg/clockx"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunInteractive(t *testing.T) {
+	// the fake clock never moves, so every stay is free within the grace period
+	tariffs := billing.New(billing.Config{
+		Currency: "IDR",
+		Tariffs: map[parkingentity.VehicleType]billing.Tariff{
+			parkingentity.A1: {FirstHour: 5000, Hourly: 3000, Grace: 10 * time.Minute, LostTicket: 25000},
+		},
+	})
+	clock := clockx.NewFake(time.Date(2025, 1, 1, 8, 0, 0, 0, time.UTC))
+
+	park, err := parkingcli.NewPark(parkingcli.WithRandomizeParkingSpots(2, 10, 10), parkingcli.WithClock(clock), parkingcli.WithBilling(tariffs))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spots, free := park.AvailableSpot(parkingentity.A1)
+	first := parkingentity.SpotID(free[0]).ID()
+	second := parkingentity.SpotID(free[1]).ID()
+	_, freeM1 := park.AvailableSpot(parkingentity.M1)
+	held := parkingentity.SpotID(freeM1[0]).ID()
+
+	script := strings.Join([]string{
+		"# replay a short session",
+		"available A-1 1",
+		"available A-1 1 floor=9",
+		"available A-1 rows=2-1",
+		"park A-1 1234",
+		"park A-1 1234",
+		"search 1234",
+		"unpark 9-9-9 1234",
+		"unpark " + first + " 1234",
+		"park A-1 77",
+		"lost-ticket 77",
+		"lost-ticket 77",
+		"search 42",
+		"park Z-9 1",
+		"reserve M-1 1h 42",
+		"park M-1 42",
+		"claim 42",
+		"cancel 42",
+		"retype " + held + " A-1",
+		"disable " + first,
+		"retype " + first + " M-1",
+		"enable " + first + " A-1",
+		"fly away",
+		"gate north",
+		"exit",
+		"park A-1 5678",
+	}, "\n")
+
+	var out bytes.Buffer
+	if err := cli.RunInteractive(park, strings.NewReader(script), &out, false); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"A-1: " + strconv.Itoa(spots) + " available",
+		"  " + first,
+		"  more with cursor=1",
+		"A-1: 0 available",
+		"error: " + parkingentity.ErrInvalidSpotQuery.Error(),
+		"parked vehicle 1234 at " + first,
+		"error: " + parkingentity.ErrVehicleAlreadyParked.Error(),
+		"vehicle 1234 last parked at " + first,
+		"error: " + parkingentity.ErrSpotOutOfRange.Error(),
+		"unparked vehicle 1234 from " + first + " after 0s, charge 0 IDR",
+		"parked vehicle 77 at " + second,
+		"unparked vehicle 77 without ticket from " + second + " after 0s, charge 25000 IDR",
+		"error: " + parkingentity.ErrVehicleNotFound.Error(),
+		"error: " + parkingentity.ErrVehicleNotFound.Error(),
+		"error: " + parkingentity.ErrInvalidVehicleType.Error(),
+		"reserved " + held + " for vehicle 42 until 2025-01-01 09:00:00",
+		"error: " + parkingentity.ErrVehicleReserved.Error(),
+		"parked vehicle 42 at " + held,
+		"error: " + parkingentity.ErrReservationNotFound.Error(),
+		"error: " + parkingentity.ErrSpotOccupied.Error(),
+		"disabled spot " + first,
+		"error: " + parkingentity.ErrSpotDisabled.Error(),
+		"spot " + first + " is now A-1",
+		`error: unknown command "fly", type help for the list of commands`,
+		"error: " + parkingentity.ErrUnknownGate.Error(),
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != len(expected) {
+		t.Fatalf("Expected %d output lines, got %d:\n%s", len(expected), len(lines), out.String())
+	}
+
+	for i, line := range lines {
+		if line != expected[i] {
+			t.Errorf("Line %d: expected %q, got %q", i+1, expected[i], line)
+		}
+	}
+
+	// the script stops at exit, so vehicle 5678 must never be parked
+	if total, _ := park.AvailableSpot(parkingentity.A1); total != spots {
+		t.Errorf("Expected %d available A1 spots after the script, got %d", spots, total)
+	}
+}
+
+func TestRunInteractiveSpotFormat(t *testing.T) {
+	park, err := parkingcli.NewPark(parkingcli.WithRandomizeParkingSpots(2, 10, 10), parkingcli.WithSpotFormat(parkingentity.SpotFormat{OneBased: true, Basements: 1}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// B1 is floor 0 and row 1 is row 0, like the printed spot IDs
+	_, spots := park.AvailableSpot(parkingentity.A1)
+	onFirstRow := 0
+	for _, spot := range spots {
+		if spot.Floor == 0 && spot.Row == 0 {
+			onFirstRow++
+		}
+	}
+
+	var out bytes.Buffer
+	if err := cli.RunInteractive(park, strings.NewReader("available A-1 0 floor=B1 rows=1\navailable A-1 0 floor=0"), &out, false); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "A-1: " + strconv.Itoa(onFirstRow) + " available\nerror: " + parkingentity.ErrInvalidSpotQuery.Error() + "\n"
+	if out.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, out.String())
+	}
+}
@@ -0,0 +1,153 @@
+package cli
+
+import (
+	parkingpkg "github.com/mtfiqh/DoiT-parking-system/parking"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
+	"github.com/pkg/errors"
+	"math/rand"
+	"time"
+)
+
+// operation is an operation of the simulation mix.
+type operation int
+
+const (
+	opPark operation = iota
+	opUnpark
+	opSearch
+)
+
+// operations are the names of the operations, in order.
+var operations = [...]string{"park", "unpark", "search"}
+
+// parkedVehicle is a vehicle parked by a gate worker.
+type parkedVehicle struct {
+	number      parkingentity.Plate
+	vehicleType parkingentity.VehicleType
+	spotID      parkingentity.SpotID
+}
+
+// outcome is the result of one operation of a gate worker.
+type outcome struct {
+	op      operation
+	vehicle parkedVehicle
+	// gate is the gate of the lot the operation went through, empty when the lot has no gates.
+	gate string
+	took time.Duration
+	err  error
+}
+
+// full reports whether the operation was a park turned away because the lot was full, which isn't an error.
+func (o outcome) full() bool {
+	return o.op == opPark && errors.Cause(o.err) == parkingentity.ErrSpotNotFound
+}
+
+// gateWorker runs the operation mix of the simulation from one gate: 60% park, 30% unpark and 10% search.
+// It only unparks and searches the vehicles it parked itself, so the workers never wait on each other outside of the lot.
+type gateWorker struct {
+	park   parkingpkg.ParkingSystem
+	format parkingentity.SpotFormat
+	// gated is the lot when it knows its gates, every park and unpark then goes through a random one of gates.
+	gated  parkingpkg.GateParker
+	gates  []parkingentity.Gate
+	id     int
+	random *rand.Rand
+
+	parked []parkedVehicle
+	next   int
+}
+
+func newGateWorker(park parkingpkg.ParkingSystem, id int, random *rand.Rand) *gateWorker {
+	w := &gateWorker{park: park, format: parkingpkg.SpotFormatOf(park), id: id, random: random}
+	if gated, ok := park.(parkingpkg.GateParker); ok {
+		w.gated, w.gates = gated, gated.Gates()
+	}
+
+	return w
+}
+
+// run runs operations until the deadline, and stops early with the first error returned by observe.
+func (w *gateWorker) run(deadline time.Time, observe func(outcome) error) error {
+	for time.Now().Before(deadline) {
+		o, ok := w.step()
+		if !ok {
+			continue
+		}
+		if err := observe(o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// step runs one random operation, unparks and searches are skipped while the worker has no vehicle parked.
+func (w *gateWorker) step() (outcome, bool) {
+	vehicleTypes := []parkingentity.VehicleType{parkingentity.M1, parkingentity.B1, parkingentity.A1}
+
+	op := w.random.Intn(10)
+	switch {
+	case op < 6:
+		w.next++
+		o := outcome{op: opPark, gate: w.randomGate(), vehicle: parkedVehicle{
+			number:      parkingentity.PlateFromNumber(w.id*10_000_000 + w.next),
+			vehicleType: vehicleTypes[w.random.Intn(len(vehicleTypes))],
+		}}
+
+		var spotID *parkingentity.SpotID
+		start := time.Now()
+		if o.gate != "" {
+			spotID, o.err = w.gated.ParkAt(o.gate, o.vehicle.vehicleType, o.vehicle.number)
+		} else {
+			spotID, o.err = w.park.Park(o.vehicle.vehicleType, o.vehicle.number)
+		}
+		o.took = time.Since(start)
+
+		if o.err == nil {
+			o.vehicle.spotID = *spotID
+			w.parked = append(w.parked, o.vehicle)
+		}
+		return o, true
+
+	case op < 9 && len(w.parked) > 0:
+		i := w.random.Intn(len(w.parked))
+		o := outcome{op: opUnpark, vehicle: w.parked[i], gate: w.randomGate()}
+		w.parked[i] = w.parked[len(w.parked)-1]
+		w.parked = w.parked[:len(w.parked)-1]
+
+		start := time.Now()
+		if o.gate != "" {
+			_, o.err = w.gated.UnparkAt(o.gate, w.format.Format(o.vehicle.spotID), o.vehicle.number)
+		} else {
+			_, o.err = w.park.Unpark(w.format.Format(o.vehicle.spotID), o.vehicle.number)
+		}
+		o.took = time.Since(start)
+		return o, true
+
+	case op == 9 && len(w.parked) > 0:
+		o := outcome{op: opSearch, vehicle: w.parked[w.random.Intn(len(w.parked))]}
+
+		start := time.Now()
+		spotID, err := w.park.SearchVehicle(o.vehicle.number)
+		o.took = time.Since(start)
+
+		switch {
+		case err != nil:
+			o.err = err
+		case spotID == nil:
+			o.err = errors.New("spotID empty")
+		default:
+			o.vehicle.spotID = *spotID
+		}
+		return o, true
+	}
+
+	return outcome{}, false
+}
+
+func (w *gateWorker) randomGate() string {
+	if len(w.gates) == 0 {
+		return ""
+	}
+	return w.gates[w.random.Intn(len(w.gates))].ID
+}
@@ -3,15 +3,51 @@ package parkingcli
 import (
 	parkingpkg "github.com/mtfiqh/DoiT-parking-system/parking"
 	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
-	"github.com/mtfiqh/DoiT-parking-system/pkg/queuex"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingmetrics"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingstore"
+	"github.com/mtfiqh/DoiT-parking-system/pkg/clockx"
+	"github.com/pkg/errors"
+	"math/rand"
 	"sync"
+	"time"
 )
 
 // parking provides an implementation of a parking system that allows vehicles to be parked, unparked, and searched for within a structured parking space.
 type parking struct {
-	Spaces         [][][]int // floor, col, row
-	AvailableSpots *parkingentity.AvailableSpots
-	VehiclesParked map[int]parkingentity.VehicleSpot
+	Spaces [][][]int // floor, col, row
+	// AvailableSpots holds the free spots of every spot type, the allocator picks the one each vehicle parks at.
+	AvailableSpots map[parkingentity.VehicleType]parkingpkg.Allocator
+	VehiclesParked map[parkingentity.Plate]parkingentity.VehicleSpot
+	// Sessions holds every parking session oldest first, VehicleSessions indexes them per vehicle.
+	Sessions        []parkingentity.Session
+	VehicleSessions map[parkingentity.Plate][]int
+	// Reservations holds the spots taken out of the queues for pre-booked vehicles.
+	Reservations map[parkingentity.Plate]parkingentity.Reservation
+	// occupancy counts the spots of every floor by state, every change updates it so Occupancy doesn't scan the lot.
+	occupancy parkingentity.Occupancy
+	// entering holds the vehicles taking a spot outside the lock, they count as parked until they are recorded.
+	entering map[parkingentity.Plate]struct{}
+
+	plateRule     parkingentity.PlateRule
+	spotFormat    parkingentity.SpotFormat
+	fallback      parkingentity.Fallback
+	gates         []parkingentity.Gate
+	clock         clockx.Clock
+	biller        parkingpkg.Biller
+	metrics       *parkingmetrics.Metrics
+	events        *parkingpkg.Bus
+	store         parkingstore.Store
+	snapshotEvery int
+	ops           int
+	// snapshots is nil unless periodic snapshots are enabled, snapshotDue is set by the operation a snapshot is due after.
+	snapshots   *snapshotter
+	snapshotDue bool
+
+	// reservationSweep is how often the worker started by the first reservation releases expired ones, until done is closed.
+	reservationSweep time.Duration
+	workerOnce       *sync.Once
+	closeOnce        *sync.Once
+	done             chan struct{}
 
 	mutex *sync.RWMutex
 }
@@ -19,27 +55,76 @@ type parking struct {
 // NewPark initializes a new parking instance with empty spaces and available spots.
 func NewPark(opts ...ParkOption) (parkingpkg.ParkingSystem, error) {
 	// get options
-	opt := &ParkOptions{}
+	opt := &ParkOptions{
+		PlateRule:        parkingentity.PlateRuleAny,
+		Strategy:         parkingpkg.FIFO,
+		Clock:            clockx.New(),
+		ReservationSweep: time.Second,
+	}
 	for _, o := range opts {
 		o(opt)
 	}
 
 	park := &parking{
 		Spaces: make([][][]int, 0),
-		AvailableSpots: &parkingentity.AvailableSpots{
-			B1: queuex.NewQueue[parkingentity.Spot](),
-			M1: queuex.NewQueue[parkingentity.Spot](),
-			A1: queuex.NewQueue[parkingentity.Spot](),
+		AvailableSpots: map[parkingentity.VehicleType]parkingpkg.Allocator{
+			parkingentity.B1: opt.Strategy(),
+			parkingentity.M1: opt.Strategy(),
+			parkingentity.A1: opt.Strategy(),
 		},
-		VehiclesParked: make(map[int]parkingentity.VehicleSpot),
-		mutex:          new(sync.RWMutex),
+		VehiclesParked:   make(map[parkingentity.Plate]parkingentity.VehicleSpot),
+		VehicleSessions:  make(map[parkingentity.Plate][]int),
+		Reservations:     make(map[parkingentity.Plate]parkingentity.Reservation),
+		entering:         make(map[parkingentity.Plate]struct{}),
+		plateRule:        opt.PlateRule,
+		spotFormat:       opt.SpotFormat,
+		fallback:         opt.Fallback,
+		gates:            opt.Gates,
+		clock:            opt.Clock,
+		biller:           opt.Biller,
+		metrics:          opt.Metrics,
+		events:           opt.Events,
+		store:            opt.Store,
+		snapshotEvery:    opt.SnapshotEvery,
+		reservationSweep: opt.ReservationSweep,
+		workerOnce:       new(sync.Once),
+		closeOnce:        new(sync.Once),
+		done:             make(chan struct{}),
+		mutex:            new(sync.RWMutex),
+	}
+
+	if park.store != nil {
+		state, err := park.store.Load()
+		if err != nil {
+			return nil, errors.Wrap(err, "recovering parking state")
+		}
+
+		// the stored lot wins over seeding, cars are still physically inside
+		if state != nil {
+			park.restore(state)
+			return park, nil
+		}
 	}
 
-	if opt.WithRandomize {
-		err := park.Seed(opt.MaxFloor, opt.MaxCol, opt.MaxRow)
+	switch {
+	case opt.LayoutFile != "":
+		spaces, err := LoadLayout(opt.LayoutFile)
 		if err != nil {
 			return nil, err
 		}
+		park.loadSpaces(spaces)
+	case opt.WithRandomize:
+		err := park.seed(opt.Rand, opt.MaxFloor, opt.MaxCol, opt.MaxRow)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if park.store != nil {
+		if err := park.store.Snapshot(park.state()); err != nil {
+			return nil, errors.Wrap(err, "storing initial parking state")
+		}
+		park.startSnapshots()
 	}
 
 	return park, nil
@@ -51,6 +136,22 @@ type ParkOptions struct {
 	MaxFloor      int
 	MaxCol        int
 	MaxRow        int
+	// Rand draws the spot types of a randomized lot, the global source when nil.
+	Rand          *rand.Rand
+	LayoutFile    string
+	Store         parkingstore.Store
+	SnapshotEvery int
+	PlateRule     parkingentity.PlateRule
+	SpotFormat    parkingentity.SpotFormat
+	Fallback      parkingentity.Fallback
+	Gates         []parkingentity.Gate
+	Strategy      parkingpkg.Strategy
+	Clock         clockx.Clock
+	Biller        parkingpkg.Biller
+	Metrics       *parkingmetrics.Metrics
+	Events        *parkingpkg.Bus
+	// ReservationSweep is how often expired reservations are released.
+	ReservationSweep time.Duration
 }
 
 // ParkOption is a function type that modifies the ParkOptions.
@@ -65,3 +166,96 @@ func WithRandomizeParkingSpots(maxFloor, maxCol, maxRow int) ParkOption {
 		opt.WithRandomize = true
 	}
 }
+
+// WithSeed is an option to draw the spot types of a randomized lot from the seed, so the same seed builds the same lot.
+func WithSeed(seed int64) ParkOption {
+	return func(opt *ParkOptions) {
+		opt.Rand = rand.New(rand.NewSource(seed))
+	}
+}
+
+// WithStore is an option to persist every operation to the store and recover the lot from it on start.
+// A snapshot is stored after every snapshotEvery operations, 0 disables periodic snapshots.
+func WithStore(store parkingstore.Store, snapshotEvery int) ParkOption {
+	return func(opt *ParkOptions) {
+		opt.Store = store
+		opt.SnapshotEvery = snapshotEvery
+	}
+}
+
+// WithPlateRule is an option to validate the plates of parked vehicles with the rule of a region, defaults to PlateRuleAny.
+func WithPlateRule(rule parkingentity.PlateRule) ParkOption {
+	return func(opt *ParkOptions) {
+		opt.PlateRule = rule
+	}
+}
+
+// WithSpotFormat is an option to display spot IDs in a custom format, Unpark takes spot IDs in that format.
+func WithSpotFormat(format parkingentity.SpotFormat) ParkOption {
+	return func(opt *ParkOptions) {
+		opt.SpotFormat = format
+	}
+}
+
+// WithFallback is an option to let vehicles overflow into other spot types when their own are full, e.g. parkingentity.FallbackBySize.
+func WithFallback(fallback parkingentity.Fallback) ParkOption {
+	return func(opt *ParkOptions) {
+		opt.Fallback = fallback
+	}
+}
+
+// WithGates is an option to set the gates vehicles enter and exit at with ParkAt and UnparkAt,
+// pair it with WithStrategy(parking.NearestGate(gates)) to give each vehicle the spot nearest to its gate.
+func WithGates(gates ...parkingentity.Gate) ParkOption {
+	return func(opt *ParkOptions) {
+		opt.Gates = gates
+	}
+}
+
+// WithStrategy is an option to pick the spot each vehicle parks at with the strategy, defaults to parking.FIFO.
+func WithStrategy(strategy parkingpkg.Strategy) ParkOption {
+	return func(opt *ParkOptions) {
+		opt.Strategy = strategy
+	}
+}
+
+// WithReservationSweep is an option to set how often expired reservations are released back to the queues, defaults to a second.
+// 0 disables the worker, expired reservations are then only released when the vehicle reserves or claims again.
+func WithReservationSweep(interval time.Duration) ParkOption {
+	return func(opt *ParkOptions) {
+		opt.ReservationSweep = interval
+	}
+}
+
+// WithClock is an option to set the clock used for session timestamps.
+func WithClock(clock clockx.Clock) ParkOption {
+	return func(opt *ParkOptions) {
+		opt.Clock = clock
+	}
+}
+
+// WithBilling is an option to charge every unpark, e.g. with a billing.Engine.
+func WithBilling(biller parkingpkg.Biller) ParkOption {
+	return func(opt *ParkOptions) {
+		opt.Biller = biller
+	}
+}
+
+// WithMetrics is an option to count every park, unpark, search and available spot lookup on m,
+// call m.Watch with the lot to also expose its free spots.
+func WithMetrics(m *parkingmetrics.Metrics) ParkOption {
+	return func(opt *ParkOptions) {
+		opt.Metrics = m
+	}
+}
+
+// WithEvents is an option to publish the activity of the lot on the bus.
+func WithEvents(bus *parkingpkg.Bus) ParkOption {
+	return func(opt *ParkOptions) {
+		opt.Events = bus
+	}
+}
+
+func (p *parking) SpotFormat() parkingentity.SpotFormat {
+	return p.spotFormat
+}
@@ -0,0 +1,116 @@
+package parkingcli
+
+import (
+	parkingpkg "github.com/mtfiqh/DoiT-parking-system/parking"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingstore"
+)
+
+func (p *parking) DisableSpot(spotID string) error {
+	p.mutex.Lock()
+	defer p.unlock()
+
+	spot, spotType, err := p.adminSpot(spotID)
+	if err != nil {
+		return err
+	}
+
+	if spotType == parkingentity.X0 {
+		return nil
+	}
+
+	return p.setSpotType(spot, spotType, parkingentity.X0)
+}
+
+func (p *parking) EnableSpot(spotID string, spotType parkingentity.VehicleType) error {
+	if p.allocator(spotType) == nil {
+		return parkingentity.ErrInvalidVehicleType
+	}
+
+	p.mutex.Lock()
+	defer p.unlock()
+
+	spot, current, err := p.adminSpot(spotID)
+	if err != nil {
+		return err
+	}
+
+	if current != parkingentity.X0 {
+		return parkingentity.ErrSpotEnabled
+	}
+
+	return p.setSpotType(spot, current, spotType)
+}
+
+func (p *parking) RetypeSpot(spotID string, spotType parkingentity.VehicleType) error {
+	if p.allocator(spotType) == nil {
+		return parkingentity.ErrInvalidVehicleType
+	}
+
+	p.mutex.Lock()
+	defer p.unlock()
+
+	spot, current, err := p.adminSpot(spotID)
+	if err != nil {
+		return err
+	}
+
+	if current == parkingentity.X0 {
+		return parkingentity.ErrSpotDisabled
+	}
+	if current == spotType {
+		return nil
+	}
+
+	return p.setSpotType(spot, current, spotType)
+}
+
+// adminSpot parses the spot and returns its current type, it must be called while holding the write lock.
+func (p *parking) adminSpot(spotID string) (parkingentity.Spot, parkingentity.VehicleType, error) {
+	id, err := p.spotFormat.Parse(spotID)
+	if err != nil {
+		return parkingentity.Spot{}, 0, err
+	}
+
+	if !p.inLot(id) {
+		return parkingentity.Spot{}, 0, parkingentity.ErrSpotOutOfRange
+	}
+
+	return parkingentity.Spot(id), p.spotType(id), nil
+}
+
+// setSpotType moves a free spot from the allocator of its type to the one of spotType, X-0 disables it.
+// It must be called while holding the write lock.
+func (p *parking) setSpotType(spot parkingentity.Spot, from, to parkingentity.VehicleType) error {
+	target := p.allocator(to)
+
+	// the allocators hold exactly the free spots, a spot missing from them is parked at or held
+	if source := p.allocator(from); source != nil && !source.Remove(spot) {
+		return parkingentity.ErrSpotOccupied
+	}
+
+	now := p.clock.Now()
+	err := p.persist(parkingstore.Record{Op: parkingstore.OpSpotType, VehicleType: to, Spot: spot, Time: now})
+	if err != nil {
+		// the spot is free again
+		if source := p.allocator(from); source != nil {
+			source.Release(spot)
+		}
+		return err
+	}
+
+	p.Spaces[spot.Floor][spot.Row][spot.Col] = int(to)
+	spotID := parkingentity.SpotID(spot)
+	count(&p.occupancy, spotID, from, parkingentity.SpotCount{Total: -1})
+	count(&p.occupancy, spotID, to, parkingentity.SpotCount{Total: 1})
+	if target != nil {
+		target.Release(spot)
+		p.events.Publish(parkingpkg.Event{Type: parkingpkg.SpotFreed, Time: now, VehicleType: to, SpotID: &spotID})
+	} else {
+		p.events.Publish(parkingpkg.Event{Type: parkingpkg.SpotDisabled, Time: now, VehicleType: from, SpotID: &spotID})
+	}
+
+	p.snapshotIfDue()
+
+	return nil
+}
@@ -2,22 +2,19 @@ package parkingcli
 
 import (
 	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
-	"github.com/mtfiqh/DoiT-parking-system/pkg/queuex"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingmetrics"
+	"time"
 )
 
 func (p *parking) AvailableSpot(vehicleType parkingentity.VehicleType) (int, []parkingentity.Spot) {
-	var qfunc *queuex.Queue[parkingentity.Spot]
-	// Find an available spot for the vehicle type
-	switch vehicleType {
-	case parkingentity.A1:
-		qfunc = p.AvailableSpots.A1
-	case parkingentity.B1:
-		qfunc = p.AvailableSpots.B1
-	case parkingentity.M1:
-		qfunc = p.AvailableSpots.M1
-	default:
+	start := time.Now()
+	defer func() { p.metrics.Observe(parkingmetrics.OpAvailableSpot, vehicleType, nil, time.Since(start)) }()
+
+	allocator := p.allocator(vehicleType)
+	if allocator == nil {
 		return 0, nil
 	}
 
-	return qfunc.Size, qfunc.Print()
+	spots := allocator.Spots()
+	return len(spots), spots
 }
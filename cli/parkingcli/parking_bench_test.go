@@ -0,0 +1,150 @@
+package parkingcli
+
+import (
+	"fmt"
+	parkingpkg "github.com/mtfiqh/DoiT-parking-system/parking"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingstore"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func BenchmarkSeed(b *testing.B) {
+	for _, size := range []struct{ floors, rows, cols int }{{1, 10, 10}, {2, 100, 100}, {8, 250, 250}, {8, 500, 500}} {
+		b.Run(fmt.Sprintf("%dx%dx%d", size.floors, size.rows, size.cols), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := NewPark(WithRandomizeParkingSpots(size.floors, size.cols, size.rows)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// benchPark returns a lot of 4 floors of 100x100 spots.
+func benchPark(b *testing.B, opts ...ParkOption) parkingpkg.ParkingSystem {
+	b.Helper()
+
+	park, err := NewPark(append([]ParkOption{WithRandomizeParkingSpots(4, 100, 100)}, opts...)...)
+	if err != nil {
+		b.Fatal(err)
+	}
+	return park
+}
+
+// BenchmarkParkUnpark parks a vehicle and unparks it again on every goroutine of the parallel benchmark.
+func BenchmarkParkUnpark(b *testing.B) {
+	park := benchPark(b)
+	var next atomic.Int64
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			vehicleNumber := parkingentity.PlateFromNumber(int(next.Add(1)))
+			spotID, err := park.Park(parkingentity.A1, vehicleNumber)
+			if err != nil {
+				b.Error(err)
+				return
+			}
+			if _, err := park.Unpark(spotID.ID(), vehicleNumber); err != nil {
+				b.Error(err)
+				return
+			}
+		}
+	})
+}
+
+// BenchmarkParkWithStore parks and unparks on a stored lot of 8 floors of 50x50 spots, snapshotting every 100 operations.
+// max-ns is the slowest Park, which includes the wait for a gate holding the lock while a snapshot is due.
+func BenchmarkParkWithStore(b *testing.B) {
+	store, err := parkingstore.OpenFileStore(b.TempDir())
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer store.Close()
+
+	park, err := NewPark(WithRandomizeParkingSpots(8, 50, 50), WithStore(store, 100))
+	if err != nil {
+		b.Fatal(err)
+	}
+	var next, slowest atomic.Int64
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			vehicleNumber := parkingentity.PlateFromNumber(int(next.Add(1)))
+			start := time.Now()
+			spotID, err := park.Park(parkingentity.A1, vehicleNumber)
+			if err != nil {
+				b.Error(err)
+				return
+			}
+			for took := int64(time.Since(start)); ; {
+				longest := slowest.Load()
+				if took <= longest || slowest.CompareAndSwap(longest, took) {
+					break
+				}
+			}
+			if _, err := park.Unpark(spotID.ID(), vehicleNumber); err != nil {
+				b.Error(err)
+				return
+			}
+		}
+	})
+	b.ReportMetric(float64(slowest.Load()), "max-ns")
+}
+
+// BenchmarkSearchVehicle searches the vehicles of a busy lot on every goroutine of the parallel benchmark.
+func BenchmarkSearchVehicle(b *testing.B) {
+	park := benchPark(b)
+
+	const vehicles = 10000
+	for i := 0; i < vehicles; i++ {
+		if _, err := park.Park(parkingentity.A1, parkingentity.PlateFromNumber(i)); err != nil {
+			break
+		}
+	}
+
+	var next atomic.Int64
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_, _ = park.SearchVehicle(parkingentity.PlateFromNumber(int(next.Add(1) % vehicles)))
+		}
+	})
+}
+
+// BenchmarkAvailableSpot lists the free spots of big queues, next to a page of them with QuerySpots.
+func BenchmarkAvailableSpot(b *testing.B) {
+	for _, rows := range []int{100, 500} {
+		park, err := NewPark(WithRandomizeParkingSpots(4, 500, rows))
+		if err != nil {
+			b.Fatal(err)
+		}
+		total, _ := park.AvailableSpot(parkingentity.A1)
+
+		b.Run(fmt.Sprintf("spots=%d/all", total), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				park.AvailableSpot(parkingentity.A1)
+			}
+		})
+
+		b.Run(fmt.Sprintf("spots=%d/page", total), func(b *testing.B) {
+			querier := park.(parkingpkg.SpotQuerier)
+			floor := 3
+
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := querier.QuerySpots(parkingentity.SpotQuery{VehicleType: parkingentity.A1, Floor: &floor}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
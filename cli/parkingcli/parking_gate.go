@@ -0,0 +1,29 @@
+package parkingcli
+
+import (
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
+	"slices"
+)
+
+func (p *parking) Gates() []parkingentity.Gate {
+	return slices.Clone(p.gates)
+}
+
+// gate returns the gate of the ID.
+func (p *parking) gate(id string) (parkingentity.Gate, error) {
+	for _, gate := range p.gates {
+		if gate.ID == id {
+			return gate, nil
+		}
+	}
+
+	return parkingentity.Gate{}, parkingentity.ErrUnknownGate
+}
+
+// gateID returns the ID of the gate, empty when unknown.
+func gateID(gate *parkingentity.Gate) string {
+	if gate == nil {
+		return ""
+	}
+	return gate.ID
+}
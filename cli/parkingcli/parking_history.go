@@ -0,0 +1,44 @@
+package parkingcli
+
+import (
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
+	"time"
+)
+
+func (p *parking) History(vehicleNumber parkingentity.Plate) ([]parkingentity.Session, error) {
+	vehicleNumber = vehicleNumber.Normalize()
+
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	indexes, exists := p.VehicleSessions[vehicleNumber]
+	if !exists {
+		return nil, parkingentity.ErrVehicleNotFound
+	}
+
+	sessions := make([]parkingentity.Session, 0, len(indexes))
+	for _, i := range indexes {
+		sessions = append(sessions, p.Sessions[i])
+	}
+
+	return sessions, nil
+}
+
+func (p *parking) SessionsBetween(from, to time.Time) ([]parkingentity.Session, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	sessions := make([]parkingentity.Session, 0)
+	for _, session := range p.Sessions {
+		// sessions are appended in entry order, the rest entered after the window
+		if !session.EntryAt.Before(to) {
+			break
+		}
+
+		if session.Overlaps(from, to) {
+			sessions = append(sessions, session)
+		}
+	}
+
+	return sessions, nil
+}
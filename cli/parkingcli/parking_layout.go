@@ -0,0 +1,285 @@
+package parkingcli
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// floorSeparator separates the grids of two floors in a text layout.
+const floorSeparator = "---"
+
+// LayoutError reports a malformed layout file with the position of the offending token.
+type LayoutError struct {
+	File   string
+	Line   int
+	Column int
+	Msg    string
+}
+
+func (e *LayoutError) Error() string {
+	return fmt.Sprintf("%s:%d:%d: %s", e.File, e.Line, e.Column, e.Msg)
+}
+
+// WithLayoutFile is an option to initialize the parking spots from a layout file instead of seeding them randomly.
+//
+// A text layout holds one grid per floor, floors are separated by a line containing only "---".
+// Each line is a row and each whitespace separated token (B-1, M-1, A-1 or X-0) is a column:
+//
+//	# floor 0
+//	A-1 A-1 M-1 X-0
+//	B-1 B-1 A-1 A-1
+//	---
+//	# floor 1
+//	M-1 M-1 M-1 M-1
+//
+// Files ending with .json hold the same rows grouped per floor: {"floors": [["A-1 A-1 M-1 X-0", "B-1 B-1 A-1 A-1"], ["M-1 M-1 M-1 M-1"]]}
+func WithLayoutFile(path string) ParkOption {
+	return func(opt *ParkOptions) {
+		opt.LayoutFile = path
+	}
+}
+
+// LoadLayout reads a layout file and returns its spaces indexed by floor, row and column.
+func LoadLayout(path string) ([][][]int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return parseJSONLayout(path, data)
+	}
+
+	return parseTextLayout(path, bytes.NewReader(data))
+}
+
+func parseTextLayout(file string, r io.Reader) ([][][]int, error) {
+	b := &layoutBuilder{file: file}
+	scanner := bufio.NewScanner(r)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := scanner.Text()
+		trimmed := strings.TrimSpace(text)
+
+		switch {
+		case trimmed == "" || strings.HasPrefix(trimmed, "#"):
+			continue
+		case trimmed == floorSeparator:
+			if err := b.endFloor(line, 1); err != nil {
+				return nil, err
+			}
+		default:
+			if err := b.addRow(text, line, 1); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return b.spaces(line + 1)
+}
+
+func parseJSONLayout(file string, data []byte) ([][][]int, error) {
+	b := &layoutBuilder{file: file}
+	dec := json.NewDecoder(bytes.NewReader(data))
+
+	// position converts a byte offset of data to a line and column
+	position := func(offset int64) (int, int) {
+		line, col := 1, 1
+		for _, c := range data[:offset] {
+			if c == '\n' {
+				line++
+				col = 1
+				continue
+			}
+			col++
+		}
+		return line, col
+	}
+
+	errAt := func(offset int64, format string, args ...any) error {
+		line, col := position(offset)
+		return &LayoutError{File: file, Line: line, Column: col, Msg: fmt.Sprintf(format, args...)}
+	}
+
+	// next reads the next token, offset is the position where the token starts
+	next := func() (json.Token, int64, error) {
+		offset := dec.InputOffset()
+		for int(offset) < len(data) && (unicode.IsSpace(rune(data[offset])) || data[offset] == ',' || data[offset] == ':') {
+			offset++
+		}
+
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, offset, errAt(offset, "invalid json: %v", err)
+		}
+		return tok, offset, nil
+	}
+
+	expectDelim := func(delim json.Delim) error {
+		tok, offset, err := next()
+		if err != nil {
+			return err
+		}
+		if tok != delim {
+			return errAt(offset, "expected %q, got %v", delim, tok)
+		}
+		return nil
+	}
+
+	if err := expectDelim('{'); err != nil {
+		return nil, err
+	}
+
+	for dec.More() {
+		key, offset, err := next()
+		if err != nil {
+			return nil, err
+		}
+		if key != "floors" {
+			return nil, errAt(offset, "unknown field %v", key)
+		}
+
+		if err := expectDelim('['); err != nil {
+			return nil, err
+		}
+
+		for dec.More() {
+			if err := expectDelim('['); err != nil {
+				return nil, err
+			}
+
+			for dec.More() {
+				tok, offset, err := next()
+				if err != nil {
+					return nil, err
+				}
+
+				row, ok := tok.(string)
+				if !ok {
+					return nil, errAt(offset, "expected a row string, got %v", tok)
+				}
+
+				// the row starts after the opening quote
+				line, col := position(offset + 1)
+				if err := b.addRow(row, line, col); err != nil {
+					return nil, err
+				}
+			}
+
+			// closing bracket of the floor
+			_, offset, err := next()
+			if err != nil {
+				return nil, err
+			}
+
+			line, col := position(offset)
+			if err := b.endFloor(line, col); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := expectDelim(']'); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := expectDelim('}'); err != nil {
+		return nil, err
+	}
+
+	if len(b.floors) == 0 {
+		return nil, errAt(int64(len(data)), "layout has no floors")
+	}
+
+	return b.floors, nil
+}
+
+// layoutBuilder collects rows into floors and validates them.
+type layoutBuilder struct {
+	file   string
+	floors [][][]int
+	floor  [][]int
+}
+
+// addRow parses a row of tokens, line and col are the position of the first character of text.
+func (b *layoutBuilder) addRow(text string, line, col int) error {
+	row := make([]int, 0)
+
+	for i := 0; i < len(text); {
+		if unicode.IsSpace(rune(text[i])) {
+			i++
+			continue
+		}
+
+		start := i
+		for i < len(text) && !unicode.IsSpace(rune(text[i])) {
+			i++
+		}
+		token := text[start:i]
+
+		spot, err := parseSpotToken(token)
+		if err != nil {
+			return &LayoutError{File: b.file, Line: line, Column: col + start, Msg: err.Error()}
+		}
+
+		if len(b.floor) > 0 && len(row) == len(b.floor[0]) {
+			return &LayoutError{File: b.file, Line: line, Column: col + start, Msg: fmt.Sprintf("row has more than %d columns", len(b.floor[0]))}
+		}
+
+		row = append(row, int(spot))
+	}
+
+	if len(row) == 0 {
+		return &LayoutError{File: b.file, Line: line, Column: col, Msg: "row is empty"}
+	}
+
+	if len(b.floor) > 0 && len(row) != len(b.floor[0]) {
+		return &LayoutError{File: b.file, Line: line, Column: col + len(text), Msg: fmt.Sprintf("row has %d columns, expected %d", len(row), len(b.floor[0]))}
+	}
+
+	b.floor = append(b.floor, row)
+	return nil
+}
+
+// endFloor closes the current floor, line and col are the position of the separator.
+func (b *layoutBuilder) endFloor(line, col int) error {
+	if len(b.floor) == 0 {
+		return &LayoutError{File: b.file, Line: line, Column: col, Msg: fmt.Sprintf("floor %d has no rows", len(b.floors))}
+	}
+
+	b.floors = append(b.floors, b.floor)
+	b.floor = nil
+	return nil
+}
+
+// spaces closes the last floor and returns every floor, line is the position after the last line.
+func (b *layoutBuilder) spaces(line int) ([][][]int, error) {
+	if err := b.endFloor(line, 1); err != nil {
+		return nil, err
+	}
+
+	return b.floors, nil
+}
+
+func parseSpotToken(token string) (parkingentity.VehicleType, error) {
+	var spot parkingentity.VehicleType
+	if err := spot.UnmarshalText([]byte(token)); err != nil {
+		return 0, fmt.Errorf("unknown spot %q, expected one of B-1, M-1, A-1 or X-0", token)
+	}
+
+	return spot, nil
+}
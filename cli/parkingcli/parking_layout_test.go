@@ -0,0 +1,142 @@
+package parkingcli
+
+import (
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeLayout(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestLayoutFile(t *testing.T) {
+	var (
+		a1 = int(parkingentity.A1)
+		b1 = int(parkingentity.B1)
+		m1 = int(parkingentity.M1)
+		x0 = int(parkingentity.X0)
+	)
+	expected := [][][]int{
+		{
+			{a1, a1, m1, x0},
+			{b1, b1, a1, a1},
+		},
+		{
+			{m1, m1, m1, m1},
+		},
+	}
+
+	t.Run("text layout", func(t *testing.T) {
+		path := writeLayout(t, "lot.txt", "# floor 0\nA-1 A-1 M-1 X-0\nB-1  b-1 A-1 A-1\n---\n\n# floor 1\nM-1 M-1 M-1 M-1\n")
+
+		p, err := newParkForDebug(WithLayoutFile(path))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(p.GetSpaces(), expected) {
+			t.Fatalf("Expected spaces %v, got %v", expected, p.GetSpaces())
+		}
+
+		a1Spots := p.GetAvailableSpots()[parkingentity.A1].Spots()
+		expectedA1 := []parkingentity.Spot{{Floor: 0, Row: 0, Col: 0}, {Floor: 0, Row: 0, Col: 1}, {Floor: 0, Row: 1, Col: 2}, {Floor: 0, Row: 1, Col: 3}}
+		if !reflect.DeepEqual(a1Spots, expectedA1) {
+			t.Errorf("Expected A1 spots %v, got %v", expectedA1, a1Spots)
+		}
+
+		if size := len(p.GetAvailableSpots()[parkingentity.M1].Spots()); size != 5 {
+			t.Errorf("Expected 5 M1 spots, got %d", size)
+		}
+	})
+
+	t.Run("json layout", func(t *testing.T) {
+		path := writeLayout(t, "lot.json", `{"floors": [
+  ["A-1 A-1 M-1 X-0", "B-1 B-1 A-1 A-1"],
+  ["M-1 M-1 M-1 M-1"]
+]}`)
+
+		p, err := newParkForDebug(WithLayoutFile(path))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(p.GetSpaces(), expected) {
+			t.Fatalf("Expected spaces %v, got %v", expected, p.GetSpaces())
+		}
+	})
+
+	testCases := []struct {
+		name     string
+		file     string
+		content  string
+		expected string
+	}{
+		{
+			name:     "unknown token",
+			file:     "lot.txt",
+			content:  "A-1 A-1\nA-1 Q-7\n",
+			expected: ":2:5: unknown spot \"Q-7\", expected one of B-1, M-1, A-1 or X-0",
+		},
+		{
+			name:     "row too long",
+			file:     "lot.txt",
+			content:  "A-1 A-1\nA-1 A-1 A-1\n",
+			expected: ":2:9: row has more than 2 columns",
+		},
+		{
+			name:     "row too short",
+			file:     "lot.txt",
+			content:  "A-1 A-1\n  A-1\n",
+			expected: ":2:6: row has 1 columns, expected 2",
+		},
+		{
+			name:     "empty floor",
+			file:     "lot.txt",
+			content:  "A-1\n---\n---\nA-1\n",
+			expected: ":3:1: floor 1 has no rows",
+		},
+		{
+			name:     "json unknown token",
+			file:     "lot.json",
+			content:  "{\"floors\": [\n  [\"A-1 A-1\", \"A-1 Z-1\"]\n]}",
+			expected: ":2:20: unknown spot \"Z-1\", expected one of B-1, M-1, A-1 or X-0",
+		},
+		{
+			name:     "json empty floor",
+			file:     "lot.json",
+			content:  "{\"floors\": [\n  []\n]}",
+			expected: ":2:4: floor 0 has no rows",
+		},
+		{
+			name:     "json unknown field",
+			file:     "lot.json",
+			content:  "{\n  \"levels\": []\n}",
+			expected: ":2:3: unknown field levels",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := writeLayout(t, tc.file, tc.content)
+
+			_, err := NewPark(WithLayoutFile(path))
+			if err == nil {
+				t.Fatal("Expected an error for a malformed layout, but got none")
+			}
+
+			if err.Error() != path+tc.expected {
+				t.Errorf("Expected error %q, got %q", path+tc.expected, err.Error())
+			}
+		})
+	}
+}
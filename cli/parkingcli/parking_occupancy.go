@@ -0,0 +1,75 @@
+package parkingcli
+
+import (
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
+	"maps"
+	"slices"
+)
+
+// Occupancy copies the counters kept up to date by every change, it doesn't scan the spots.
+func (p *parking) Occupancy() parkingentity.Occupancy {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	occupancy := parkingentity.Occupancy{Floors: make([]parkingentity.FloorOccupancy, len(p.occupancy.Floors))}
+	for i, floor := range p.occupancy.Floors {
+		occupancy.Floors[i] = parkingentity.FloorOccupancy{Floor: floor.Floor, Types: maps.Clone(floor.Types), Rows: slices.Clone(floor.Rows)}
+	}
+
+	return occupancy
+}
+
+// countOccupancy counts every spot, parked vehicle and reservation of the lot, it must be called while holding the lock.
+// It builds the counters when the spaces are loaded or restored, the changes then update them with count.
+func (p *parking) countOccupancy() parkingentity.Occupancy {
+	occupancy := parkingentity.Occupancy{Floors: make([]parkingentity.FloorOccupancy, len(p.Spaces))}
+	for floor, rows := range p.Spaces {
+		occupancy.Floors[floor] = parkingentity.FloorOccupancy{
+			Floor: floor,
+			Types: make(map[parkingentity.VehicleType]parkingentity.SpotCount),
+			Rows:  make([]parkingentity.SpotCount, len(rows)),
+		}
+
+		// tallied in arrays first, a floor may hold a million spots
+		var totals [parkingentity.X0 + 1]int
+		for row, cols := range rows {
+			for _, spotType := range cols {
+				totals[spotType]++
+				if parkingentity.VehicleType(spotType) != parkingentity.X0 {
+					occupancy.Floors[floor].Rows[row].Total++
+				}
+			}
+		}
+		for spotType, total := range totals {
+			if total > 0 {
+				occupancy.Floors[floor].Types[parkingentity.VehicleType(spotType)] = parkingentity.SpotCount{Total: total}
+			}
+		}
+	}
+
+	// counted under the type of the spot, a vehicle may have overflowed into it
+	for _, parked := range p.VehiclesParked {
+		if parked.StillParked {
+			count(&occupancy, parked.SpotID, p.spotType(parked.SpotID), parkingentity.SpotCount{Occupied: 1})
+		}
+	}
+	for _, reservation := range p.Reservations {
+		count(&occupancy, reservation.SpotID, p.spotType(reservation.SpotID), parkingentity.SpotCount{Held: 1})
+	}
+
+	return occupancy
+}
+
+// count adds c to the floor, the spot type and the row of the spot, disabled spots aren't counted in their row.
+// c is negative for a spot leaving a state, a type without spots left is dropped.
+func count(occupancy *parkingentity.Occupancy, spot parkingentity.SpotID, spotType parkingentity.VehicleType, c parkingentity.SpotCount) {
+	floor := &occupancy.Floors[spot.Floor]
+	if sum := floor.Types[spotType].Add(c); sum != (parkingentity.SpotCount{}) {
+		floor.Types[spotType] = sum
+	} else {
+		delete(floor.Types, spotType)
+	}
+	if spotType != parkingentity.X0 {
+		floor.Rows[spot.Row] = floor.Rows[spot.Row].Add(c)
+	}
+}
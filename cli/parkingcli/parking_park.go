@@ -1,40 +1,69 @@
 package parkingcli
 
 import (
+	parkingpkg "github.com/mtfiqh/DoiT-parking-system/parking"
 	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
-	"github.com/mtfiqh/DoiT-parking-system/pkg/queuex"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingmetrics"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingstore"
+	"time"
 )
 
-func (p *parking) Park(vehicleType parkingentity.VehicleType, vehicleNumber int) (*parkingentity.SpotID, error) {
-	// Check if the vehicle is already parked
-	p.mutex.RLock()
-	parked, exists := p.VehiclesParked[vehicleNumber]
-	p.mutex.RUnlock()
-	if exists && parked.StillParked {
-		return nil, parkingentity.ErrVehicleAlreadyParked
-	}
-
-	var qfunc *queuex.Queue[parkingentity.Spot]
-	// Find an available spot for the vehicle type
-	switch vehicleType {
-	case parkingentity.A1:
-		qfunc = p.AvailableSpots.A1
-	case parkingentity.B1:
-		qfunc = p.AvailableSpots.B1
-	case parkingentity.M1:
-		qfunc = p.AvailableSpots.M1
-	default:
+func (p *parking) Park(vehicleType parkingentity.VehicleType, vehicleNumber parkingentity.Plate) (*parkingentity.SpotID, error) {
+	return p.park(nil, vehicleType, vehicleNumber)
+}
+
+func (p *parking) ParkAt(gateID string, vehicleType parkingentity.VehicleType, vehicleNumber parkingentity.Plate) (*parkingentity.SpotID, error) {
+	gate, err := p.gate(gateID)
+	if err != nil {
+		return nil, err
+	}
+	if !gate.Entry() {
+		return nil, parkingentity.ErrGateDirection
+	}
+
+	return p.park(&gate, vehicleType, vehicleNumber)
+}
+
+// park parks the vehicle entering at the gate, nil when unknown.
+func (p *parking) park(gate *parkingentity.Gate, vehicleType parkingentity.VehicleType, vehicleNumber parkingentity.Plate) (_ *parkingentity.SpotID, err error) {
+	start := time.Now()
+	defer func() { p.metrics.Observe(parkingmetrics.OpPark, vehicleType, err, time.Since(start)) }()
+
+	vehicleNumber, err = parkingentity.ParsePlate(string(vehicleNumber), p.plateRule)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.allocator(vehicleType) == nil {
 		return nil, parkingentity.ErrInvalidVehicleType
 	}
 
-	// Dequeue a spot from the available spots queue
-	spot, ok := qfunc.Dequeue()
+	// checking the vehicle and recording its spot are critical sections, taking the spot isn't: the vehicle counts as parked
+	// while it is entering, so the same vehicle entering at two gates can't pass the check twice and take two spots,
+	// and the gates only wait on each other in the allocator
+	if err := p.enter(vehicleNumber); err != nil {
+		return nil, err
+	}
+
+	// Allocate a free spot, overflowing into the fallback spot types when there is none
+	spot, allocator, ok := p.allocate(vehicleType, gate)
+
+	p.mutex.Lock()
+	defer p.unlock()
+	delete(p.entering, vehicleNumber)
+
 	if !ok {
+		p.events.Publish(parkingpkg.Event{Type: parkingpkg.LotFull, Time: p.clock.Now(), VehicleNumber: vehicleNumber, VehicleType: vehicleType, Gate: gateID(gate)})
 		return nil, parkingentity.ErrSpotNotFound
 	}
 
-	p.mutex.Lock()
-	defer p.mutex.Unlock()
+	now := p.clock.Now()
+	err = p.persist(parkingstore.Record{Op: parkingstore.OpPark, VehicleNumber: vehicleNumber, VehicleType: vehicleType, Spot: spot, Gate: gateID(gate), Time: now})
+	if err != nil {
+		// the spot is free again
+		allocator.Release(spot)
+		return nil, err
+	}
 
 	spotID := parkingentity.SpotID{
 		Floor: spot.Floor,
@@ -42,11 +71,85 @@ func (p *parking) Park(vehicleType parkingentity.VehicleType, vehicleNumber int)
 		Row:   spot.Row,
 	}
 
+	p.occupy(vehicleType, vehicleNumber, spotID, gateID(gate), now)
+	p.events.Publish(parkingpkg.Event{Type: parkingpkg.VehicleParked, Time: now, VehicleNumber: vehicleNumber, VehicleType: vehicleType, SpotID: &spotID, Gate: gateID(gate)})
+
+	p.snapshotIfDue()
+
+	return &spotID, nil
+}
+
+// enter checks the vehicle may park and marks it as entering, releasing its expired reservation first.
+func (p *parking) enter(vehicleNumber parkingentity.Plate) error {
+	p.mutex.Lock()
+	defer p.unlock()
+
+	if p.parked(vehicleNumber) {
+		return parkingentity.ErrVehicleAlreadyParked
+	}
+
+	// a pre-booked vehicle claims its held spot instead
+	if reservation, reserved := p.Reservations[vehicleNumber]; reserved {
+		now := p.clock.Now()
+		if !reservation.Expired(now) {
+			return parkingentity.ErrVehicleReserved
+		}
+
+		// the worker didn't get to it yet, the held spot goes back before the vehicle takes one
+		if err := p.release(reservation, now); err != nil {
+			return err
+		}
+	}
+
+	p.entering[vehicleNumber] = struct{}{}
+	return nil
+}
+
+// parked reports whether the vehicle is parked or entering, it must be called while holding the lock.
+func (p *parking) parked(vehicleNumber parkingentity.Plate) bool {
+	if _, entering := p.entering[vehicleNumber]; entering {
+		return true
+	}
+
+	parked, exists := p.VehiclesParked[vehicleNumber]
+	return exists && parked.StillParked
+}
+
+// allocate takes a free spot of the first spot type with one that the vehicle type may park in,
+// gate aware allocators pick it for the gate. The allocators are safe for concurrent use, it is called without the lock.
+func (p *parking) allocate(vehicleType parkingentity.VehicleType, gate *parkingentity.Gate) (parkingentity.Spot, parkingpkg.Allocator, bool) {
+	for _, spotType := range p.fallback.SpotTypes(vehicleType) {
+		allocator := p.allocator(spotType)
+
+		spot, ok := parkingentity.Spot{}, false
+		if gateAllocator, aware := allocator.(parkingpkg.GateAllocator); aware && gate != nil {
+			spot, ok = gateAllocator.AllocateAt(*gate)
+		} else {
+			spot, ok = allocator.Allocate()
+		}
+		if ok {
+			return spot, allocator, true
+		}
+	}
+
+	return parkingentity.Spot{}, nil, false
+}
+
+// occupy records the vehicle at the spot and opens its session, it must be called while holding the write lock.
+func (p *parking) occupy(vehicleType parkingentity.VehicleType, vehicleNumber parkingentity.Plate, spotID parkingentity.SpotID, gate string, now time.Time) {
+	count(&p.occupancy, spotID, p.spotType(spotID), parkingentity.SpotCount{Occupied: 1})
 	p.VehiclesParked[vehicleNumber] = parkingentity.VehicleSpot{
 		SpotID:      spotID,
 		Type:        vehicleType,
 		StillParked: true,
 	}
 
-	return &spotID, nil
+	p.Sessions = append(p.Sessions, parkingentity.Session{
+		VehicleNumber: vehicleNumber,
+		VehicleType:   vehicleType,
+		SpotID:        spotID,
+		Gate:          gate,
+		EntryAt:       now,
+	})
+	p.VehicleSessions[vehicleNumber] = append(p.VehicleSessions[vehicleNumber], len(p.Sessions)-1)
 }
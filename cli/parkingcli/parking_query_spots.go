@@ -0,0 +1,21 @@
+package parkingcli
+
+import (
+	parkingpkg "github.com/mtfiqh/DoiT-parking-system/parking"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingmetrics"
+	"time"
+)
+
+// QuerySpots walks the free spots of the vehicle type in place, only the spots of the page are copied.
+func (p *parking) QuerySpots(query parkingentity.SpotQuery) (page parkingentity.SpotPage, err error) {
+	start := time.Now()
+	defer func() { p.metrics.Observe(parkingmetrics.OpQuerySpots, query.VehicleType, err, time.Since(start)) }()
+
+	allocator := p.allocator(query.VehicleType)
+	if allocator == nil {
+		return parkingentity.SpotPage{}, parkingentity.ErrInvalidVehicleType
+	}
+
+	return query.Page(parkingpkg.SpotsOf(allocator))
+}
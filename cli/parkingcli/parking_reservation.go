@@ -0,0 +1,208 @@
+package parkingcli
+
+import (
+	parkingpkg "github.com/mtfiqh/DoiT-parking-system/parking"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingstore"
+	"log"
+	"time"
+)
+
+func (p *parking) Reserve(vehicleType parkingentity.VehicleType, vehicleNumber parkingentity.Plate, window time.Duration) (*parkingentity.Reservation, error) {
+	vehicleNumber, err := parkingentity.ParsePlate(string(vehicleNumber), p.plateRule)
+	if err != nil {
+		return nil, err
+	}
+
+	if window <= 0 {
+		return nil, parkingentity.ErrInvalidWindow
+	}
+
+	allocator := p.allocator(vehicleType)
+	if allocator == nil {
+		return nil, parkingentity.ErrInvalidVehicleType
+	}
+
+	p.mutex.Lock()
+	defer p.unlock()
+
+	if p.parked(vehicleNumber) {
+		return nil, parkingentity.ErrVehicleAlreadyParked
+	}
+
+	now := p.clock.Now()
+	if reservation, reserved := p.Reservations[vehicleNumber]; reserved {
+		if !reservation.Expired(now) {
+			return nil, parkingentity.ErrVehicleReserved
+		}
+
+		// the worker didn't get to it yet
+		if err := p.release(reservation, now); err != nil {
+			return nil, err
+		}
+	}
+
+	spot, ok := allocator.Allocate()
+	if !ok {
+		return nil, parkingentity.ErrSpotNotFound
+	}
+
+	reservation := parkingentity.Reservation{
+		VehicleNumber: vehicleNumber,
+		VehicleType:   vehicleType,
+		SpotID:        parkingentity.SpotID(spot),
+		ReservedAt:    now,
+		ExpiresAt:     now.Add(window),
+	}
+
+	err = p.persist(parkingstore.Record{Op: parkingstore.OpReserve, VehicleNumber: vehicleNumber, VehicleType: vehicleType, Spot: spot, Time: now, ExpiresAt: reservation.ExpiresAt})
+	if err != nil {
+		// the spot is free again
+		allocator.Release(spot)
+		return nil, err
+	}
+
+	p.Reservations[vehicleNumber] = reservation
+	count(&p.occupancy, reservation.SpotID, p.spotType(reservation.SpotID), parkingentity.SpotCount{Held: 1})
+	p.startReservationWorker()
+
+	p.snapshotIfDue()
+
+	return &reservation, nil
+}
+
+func (p *parking) ClaimReservation(vehicleNumber parkingentity.Plate) (*parkingentity.SpotID, error) {
+	vehicleNumber = vehicleNumber.Normalize()
+
+	p.mutex.Lock()
+	defer p.unlock()
+
+	reservation, reserved := p.Reservations[vehicleNumber]
+	if !reserved {
+		return nil, parkingentity.ErrReservationNotFound
+	}
+
+	now := p.clock.Now()
+	if reservation.Expired(now) {
+		if err := p.release(reservation, now); err != nil {
+			return nil, err
+		}
+		p.snapshotIfDue()
+		return nil, parkingentity.ErrReservationExpired
+	}
+
+	// the held spot is already out of the queue, parking there claims the reservation
+	err := p.persist(parkingstore.Record{Op: parkingstore.OpPark, VehicleNumber: vehicleNumber, VehicleType: reservation.VehicleType, Spot: parkingentity.Spot(reservation.SpotID), Time: now})
+	if err != nil {
+		return nil, err
+	}
+
+	delete(p.Reservations, vehicleNumber)
+	count(&p.occupancy, reservation.SpotID, p.spotType(reservation.SpotID), parkingentity.SpotCount{Held: -1})
+	p.occupy(reservation.VehicleType, vehicleNumber, reservation.SpotID, "", now)
+	p.events.Publish(parkingpkg.Event{Type: parkingpkg.VehicleParked, Time: now, VehicleNumber: vehicleNumber, VehicleType: reservation.VehicleType, SpotID: &reservation.SpotID})
+
+	p.snapshotIfDue()
+
+	spotID := reservation.SpotID
+	return &spotID, nil
+}
+
+func (p *parking) CancelReservation(vehicleNumber parkingentity.Plate) error {
+	vehicleNumber = vehicleNumber.Normalize()
+
+	p.mutex.Lock()
+	defer p.unlock()
+
+	reservation, reserved := p.Reservations[vehicleNumber]
+	if !reserved {
+		return parkingentity.ErrReservationNotFound
+	}
+
+	if err := p.release(reservation, p.clock.Now()); err != nil {
+		return err
+	}
+
+	p.snapshotIfDue()
+
+	return nil
+}
+
+// releaseExpired returns the spots of every expired reservation to their queues.
+func (p *parking) releaseExpired() {
+	p.mutex.Lock()
+	defer p.unlock()
+
+	now := p.clock.Now()
+	released := false
+	for _, reservation := range p.Reservations {
+		if !reservation.Expired(now) {
+			continue
+		}
+
+		if err := p.release(reservation, now); err != nil {
+			// kept held, the next sweep retries
+			log.Println("Error releasing expired reservation:", err)
+			continue
+		}
+		released = true
+	}
+
+	// the sweep is one operation however many holds it released
+	if released {
+		p.snapshotIfDue()
+	}
+}
+
+// release frees the held spot, it must be called while holding the write lock.
+// It is part of the operation calling it, which counts itself towards the next snapshot.
+func (p *parking) release(reservation parkingentity.Reservation, now time.Time) error {
+	err := p.persist(parkingstore.Record{Op: parkingstore.OpRelease, VehicleNumber: reservation.VehicleNumber, VehicleType: reservation.VehicleType, Spot: parkingentity.Spot(reservation.SpotID), Time: now})
+	if err != nil {
+		return err
+	}
+
+	delete(p.Reservations, reservation.VehicleNumber)
+	count(&p.occupancy, reservation.SpotID, p.spotType(reservation.SpotID), parkingentity.SpotCount{Held: -1})
+	p.allocator(reservation.VehicleType).Release(parkingentity.Spot(reservation.SpotID))
+	p.events.Publish(parkingpkg.Event{Type: parkingpkg.SpotFreed, Time: now, VehicleType: reservation.VehicleType, SpotID: &reservation.SpotID})
+
+	return nil
+}
+
+// startReservationWorker starts releasing expired reservations every reservationSweep until Close, only the first call starts it.
+func (p *parking) startReservationWorker() {
+	if p.reservationSweep <= 0 {
+		return
+	}
+
+	p.workerOnce.Do(func() {
+		go func() {
+			ticker := time.NewTicker(p.reservationSweep)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-p.done:
+					return
+				case <-ticker.C:
+					p.releaseExpired()
+				}
+			}
+		}()
+	})
+}
+
+// Close stops the reservation worker.
+func (p *parking) Close() error {
+	p.closeOnce.Do(func() {
+		close(p.done)
+	})
+
+	return nil
+}
+
+// allocator returns the allocator of the free spots of the type, nil when it can't be parked.
+func (p *parking) allocator(spotType parkingentity.VehicleType) parkingpkg.Allocator {
+	return p.AvailableSpots[spotType]
+}
@@ -0,0 +1,83 @@
+package parkingcli
+
+import (
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
+	"github.com/mtfiqh/DoiT-parking-system/pkg/clockx"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestReservationWorker(t *testing.T) {
+	clock := clockx.NewFake(time.Date(2025, 1, 1, 8, 0, 0, 0, time.UTC))
+	p, err := NewPark(WithRandomizeParkingSpots(2, 20, 20), WithClock(clock), WithReservationSweep(time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	park := p.(*parking)
+	defer park.Close()
+
+	total, _ := park.AvailableSpot(parkingentity.A1)
+	held := total / 2
+	for i := 0; i < held; i++ {
+		if _, err := park.Reserve(parkingentity.A1, parkingentity.PlateFromNumber(1000+i), time.Minute); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// gates keep parking while the worker releases the expired holds
+	clock.Advance(time.Minute)
+
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		parked = make(map[parkingentity.SpotID]parkingentity.Plate)
+	)
+	for gate := 0; gate < 8; gate++ {
+		wg.Add(1)
+		go func(gate int) {
+			defer wg.Done()
+
+			for i := 0; i < total; i++ {
+				vehicleNumber := parkingentity.PlateFromNumber(10000*(gate+1) + i)
+				spotID, err := park.Park(parkingentity.A1, vehicleNumber)
+				if err != nil {
+					continue
+				}
+
+				mu.Lock()
+				if other, ok := parked[*spotID]; ok {
+					t.Errorf("Spot %s given to both vehicle %s and %s", spotID.ID(), other, vehicleNumber)
+				}
+				parked[*spotID] = vehicleNumber
+				mu.Unlock()
+			}
+		}(gate)
+	}
+	wg.Wait()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		park.mutex.RLock()
+		reservations := len(park.Reservations)
+		park.mutex.RUnlock()
+		if reservations == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the worker to release every expired hold, %d left", reservations)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// every spot is either parked or back in the queue exactly once
+	available, spots := park.AvailableSpot(parkingentity.A1)
+	if available+len(parked) != total {
+		t.Errorf("Expected %d spots, got %d available and %d parked", total, available, len(parked))
+	}
+	for _, spot := range spots {
+		if _, ok := parked[parkingentity.SpotID(spot)]; ok {
+			t.Errorf("Spot %v is both parked and available", spot)
+		}
+	}
+}
@@ -2,9 +2,26 @@ package parkingcli
 
 import (
 	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingmetrics"
+	"time"
 )
 
-func (p *parking) SearchVehicle(vehicleNumber int) (*parkingentity.SpotID, error) {
+func (p *parking) SearchVehicle(vehicleNumber parkingentity.Plate) (*parkingentity.SpotID, error) {
+	vehicleSpot, err := p.LocateVehicle(vehicleNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	return &vehicleSpot.SpotID, nil
+}
+
+func (p *parking) LocateVehicle(vehicleNumber parkingentity.Plate) (_ *parkingentity.VehicleSpot, err error) {
+	vehicleType := parkingentity.X0
+	start := time.Now()
+	defer func() { p.metrics.Observe(parkingmetrics.OpSearchVehicle, vehicleType, err, time.Since(start)) }()
+
+	vehicleNumber = vehicleNumber.Normalize()
+
 	p.mutex.RLock()
 	defer p.mutex.RUnlock()
 
@@ -12,6 +29,7 @@ func (p *parking) SearchVehicle(vehicleNumber int) (*parkingentity.SpotID, error
 	if !exists {
 		return nil, parkingentity.ErrVehicleNotFound
 	}
+	vehicleType = vehicleSpot.Type
 
-	return &vehicleSpot.SpotID, nil
+	return &vehicleSpot, nil
 }
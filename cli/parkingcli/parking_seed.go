@@ -1,45 +1,46 @@
 package parkingcli
 
 import (
-	"context"
 	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
 	"github.com/mtfiqh/DoiT-parking-system/pkg/randomizer"
-	"golang.org/x/sync/errgroup"
+	"math/rand"
 )
 
 func (p *parking) Seed(maxFloor, maxCol, maxRow int) error {
-	wg, _ := errgroup.WithContext(context.Background())
-	wg.SetLimit(10)
+	return p.seed(nil, maxFloor, maxCol, maxRow)
+}
 
-	p.Spaces = make([][][]int, maxFloor)
+// seed fills the lot with spot types drawn from r, the global source when nil.
+func (p *parking) seed(r *rand.Rand, maxFloor, maxCol, maxRow int) error {
+	spaces := make([][][]int, maxFloor)
 	for i := 0; i < maxFloor; i++ {
-		p.Spaces[i] = make([][]int, maxRow)
+		spaces[i] = make([][]int, maxRow)
 		for j := 0; j < maxRow; j++ {
-			p.Spaces[i][j] = make([]int, maxCol)
+			spaces[i][j] = make([]int, maxCol)
+			for k := 0; k < maxCol; k++ {
+				spaces[i][j][k] = int(randomizer.RandomizeEnumFrom(r, parkingentity.A1, parkingentity.B1, parkingentity.M1, parkingentity.X0))
+			}
 		}
 	}
 
-	for i := 0; i < maxFloor; i++ {
-		for j := 0; j < maxRow; j++ {
-			for k := 0; k < maxCol; k++ {
-				floor, row, col := i, j, k
-				spot := randomizer.RandomizeEnum(parkingentity.A1, parkingentity.B1, parkingentity.M1, parkingentity.X0)
+	p.loadSpaces(spaces)
 
-				p.Spaces[floor][row][col] = int(spot)
-				switch spot {
-				case parkingentity.B1:
-					p.AvailableSpots.B1.Enqueue(parkingentity.Spot{Floor: floor, Col: col, Row: row})
-				case parkingentity.M1:
-					p.AvailableSpots.M1.Enqueue(parkingentity.Spot{Floor: floor, Col: col, Row: row})
-				case parkingentity.A1:
-					p.AvailableSpots.A1.Enqueue(parkingentity.Spot{Floor: floor, Col: col, Row: row})
-				default:
-					// do nothing
-				}
+	return nil
+}
+
+// loadSpaces replaces the spaces and releases every usable spot to its allocator in floor, row, column order.
+func (p *parking) loadSpaces(spaces [][][]int) {
+	p.Spaces = spaces
+	p.occupancy = p.countOccupancy()
 
+	for floor := range spaces {
+		for row := range spaces[floor] {
+			for col := range spaces[floor][row] {
+				// inactive spots have no allocator
+				if allocator := p.allocator(parkingentity.VehicleType(spaces[floor][row][col])); allocator != nil {
+					allocator.Release(parkingentity.Spot{Floor: floor, Col: col, Row: row})
+				}
 			}
 		}
 	}
-
-	return nil
 }
@@ -0,0 +1,175 @@
+package parkingcli
+
+import (
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingstore"
+	"github.com/pkg/errors"
+	"log"
+	"slices"
+	"sync"
+)
+
+// persist appends the record to the store, it must be called while holding the write lock before the change is applied.
+// The logged record is queued for the snapshot state as well, so it takes the same changes as the log.
+func (p *parking) persist(r parkingstore.Record) error {
+	if p.store == nil {
+		return nil
+	}
+
+	if err := p.store.Append(r); err != nil {
+		return errors.Wrap(err, "persisting "+string(r.Op))
+	}
+
+	if p.snapshots != nil {
+		// appends are serialised by the write lock, the last sequence is the one of this record
+		r.Seq = p.store.LastSeq()
+		p.snapshots.add(r)
+	}
+
+	return nil
+}
+
+// snapshotIfDue marks a snapshot as due every snapshotEvery operations, it must be called while holding the write lock after the change is applied.
+// The snapshot is stored by unlock, so neither applying the records nor encoding the state holds up the lot.
+func (p *parking) snapshotIfDue() {
+	if p.store == nil {
+		return
+	}
+
+	p.ops++
+	if p.snapshots != nil && p.ops%p.snapshotEvery == 0 {
+		p.snapshotDue = true
+	}
+}
+
+// unlock releases the write lock, then stores the snapshot marked as due while holding it.
+// A failed snapshot is only logged, the records are still in the log and the next snapshot retries.
+func (p *parking) unlock() {
+	due := p.snapshotDue
+	p.snapshotDue = false
+	p.mutex.Unlock()
+
+	if !due {
+		return
+	}
+	if err := p.snapshots.store(p.store); err != nil {
+		log.Println("Error storing parking snapshot:", err)
+	}
+}
+
+// snapshotter keeps its own state updated from the records written to the log, a snapshot is encoded from it
+// instead of copying the lot while holding its lock.
+type snapshotter struct {
+	// pending are the records logged since the last snapshot, in log order
+	pending      []parkingstore.Record
+	pendingMutex *sync.Mutex
+
+	state *parkingstore.State
+	mutex *sync.Mutex
+}
+
+func newSnapshotter(state *parkingstore.State) *snapshotter {
+	return &snapshotter{
+		pendingMutex: new(sync.Mutex),
+		state:        state,
+		mutex:        new(sync.Mutex),
+	}
+}
+
+// add queues a logged record for the next snapshot.
+func (s *snapshotter) add(r parkingstore.Record) {
+	s.pendingMutex.Lock()
+	s.pending = append(s.pending, r)
+	s.pendingMutex.Unlock()
+}
+
+// store applies the pending records to the state and stores it, only one snapshot is applied and stored at a time.
+func (s *snapshotter) store(store parkingstore.Store) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.pendingMutex.Lock()
+	records := s.pending
+	s.pending = nil
+	s.pendingMutex.Unlock()
+
+	for _, r := range records {
+		if err := s.state.Apply(r); err != nil {
+			return errors.Wrapf(err, "applying record %d to the snapshot", r.Seq)
+		}
+	}
+
+	return store.Snapshot(s.state)
+}
+
+// state copies the parking lot into a storable state, it must be called while holding the lock.
+func (p *parking) state() *parkingstore.State {
+	spaces := make([][][]int, len(p.Spaces))
+	for floor := range p.Spaces {
+		spaces[floor] = make([][]int, len(p.Spaces[floor]))
+		for row := range p.Spaces[floor] {
+			spaces[floor][row] = slices.Clone(p.Spaces[floor][row])
+		}
+	}
+
+	vehicles := make(map[parkingentity.Plate]parkingentity.VehicleSpot, len(p.VehiclesParked))
+	for k, v := range p.VehiclesParked {
+		vehicles[k] = v
+	}
+
+	reservations := make(map[parkingentity.Plate]parkingentity.Reservation, len(p.Reservations))
+	for k, v := range p.Reservations {
+		reservations[k] = v
+	}
+
+	available := make(map[parkingentity.VehicleType][]parkingentity.Spot, len(p.AvailableSpots))
+	for vehicleType, allocator := range p.AvailableSpots {
+		available[vehicleType] = allocator.Spots()
+	}
+
+	return &parkingstore.State{
+		LastSeq:        p.store.LastSeq(),
+		Spaces:         spaces,
+		AvailableSpots: available,
+		VehiclesParked: vehicles,
+		Sessions:       append([]parkingentity.Session(nil), p.Sessions...),
+		Reservations:   reservations,
+	}
+}
+
+// restore rebuilds the parking lot from a stored state releasing the available spots in their stored order.
+// The order only matters where the allocator keeps the release order, e.g. FIFO or between spots of one gate of NearestGate,
+// and the stored order keeps it there.
+func (p *parking) restore(state *parkingstore.State) {
+	p.Spaces = state.Spaces
+	p.VehiclesParked = state.VehiclesParked
+	p.Sessions = state.Sessions
+	for i, session := range p.Sessions {
+		p.VehicleSessions[session.VehicleNumber] = append(p.VehicleSessions[session.VehicleNumber], i)
+	}
+
+	for vehicleNumber, reservation := range state.Reservations {
+		p.Reservations[vehicleNumber] = reservation
+	}
+
+	for vehicleType, allocator := range p.AvailableSpots {
+		for _, spot := range state.AvailableSpots[vehicleType] {
+			allocator.Release(spot)
+		}
+	}
+
+	p.occupancy = p.countOccupancy()
+	p.startSnapshots()
+
+	// reservations that expired while the lot was down are released by the worker
+	if len(p.Reservations) > 0 {
+		p.startReservationWorker()
+	}
+}
+
+// startSnapshots copies the lot once for the snapshotter, later snapshots only apply the logged records to the copy.
+func (p *parking) startSnapshots() {
+	if p.snapshotEvery > 0 {
+		p.snapshots = newSnapshotter(p.state())
+	}
+}
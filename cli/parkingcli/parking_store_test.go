@@ -0,0 +1,214 @@
+package parkingcli
+
+import (
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingstore"
+	"github.com/mtfiqh/DoiT-parking-system/pkg/clockx"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestStoreRecovery(t *testing.T) {
+	dir := t.TempDir()
+
+	store, err := parkingstore.OpenFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clock := clockx.NewFake(time.Date(2025, 1, 1, 8, 0, 0, 0, time.UTC))
+	park, err := newParkForDebug(WithRandomizeParkingSpots(2, 10, 10), WithStore(store, 7), WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// park and unpark every other vehicle so freed spots go back to the tail of the queues
+	spots := make(map[parkingentity.Plate]string)
+	for i := 0; i < 30; i++ {
+		vehicleType := []parkingentity.VehicleType{parkingentity.A1, parkingentity.B1, parkingentity.M1}[i%3]
+		clock.Advance(time.Minute)
+		spotID, err := park.Park(vehicleType, parkingentity.PlateFromNumber(1000+i))
+		if err != nil {
+			continue
+		}
+		spots[parkingentity.PlateFromNumber(1000+i)] = spotID.ID()
+	}
+	for i := 0; i < 30; i += 2 {
+		clock.Advance(time.Minute)
+		if spotID, ok := spots[parkingentity.PlateFromNumber(1000+i)]; ok {
+			unpark := park.Unpark
+			if i == 0 {
+				// the first vehicle leaves without its ticket, its session keeps the lost ticket
+				unpark = func(_ string, vehicleNumber parkingentity.Plate) (*parkingentity.Receipt, error) {
+					return park.(*parking).UnparkLostTicket("", vehicleNumber)
+				}
+			}
+			if _, err := unpark(spotID, parkingentity.PlateFromNumber(1000+i)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	// hold, claim and cancel spots, the held ones stay out of the queues
+	reserver := park.(*parking)
+	for i := 0; i < 3; i++ {
+		if _, err := reserver.Reserve(parkingentity.B1, parkingentity.PlateFromNumber(2000+i), time.Hour); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := reserver.ClaimReservation(parkingentity.PlateFromNumber(2000)); err != nil {
+		t.Fatal(err)
+	}
+	if err := reserver.CancelReservation(parkingentity.PlateFromNumber(2001)); err != nil {
+		t.Fatal(err)
+	}
+
+	// close one free spot and turn another into a motorcycle spot
+	free := park.GetAvailableSpots()[parkingentity.A1].Spots()
+	if err := reserver.DisableSpot(parkingentity.SpotID(free[0]).ID()); err != nil {
+		t.Fatal(err)
+	}
+	if err := reserver.RetypeSpot(parkingentity.SpotID(free[1]).ID(), parkingentity.M1); err != nil {
+		t.Fatal(err)
+	}
+
+	// crash: the store is never closed
+	store, err = parkingstore.OpenFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	// the seeding option is ignored, the lot comes back from the store
+	recovered, err := newParkForDebug(WithRandomizeParkingSpots(1, 1, 1), WithStore(store, 7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer recovered.(*parking).Close()
+
+	if !reflect.DeepEqual(recovered.GetSpaces(), park.GetSpaces()) {
+		t.Error("Expected recovered spaces to match")
+	}
+
+	if !reflect.DeepEqual(recovered.GetVehiclesParked(), park.GetVehiclesParked()) {
+		t.Errorf("Expected recovered vehicles %v, got %v", park.GetVehiclesParked(), recovered.GetVehiclesParked())
+	}
+
+	if !reflect.DeepEqual(recovered.(*parking).Reservations, reserver.Reservations) {
+		t.Errorf("Expected recovered reservations %v, got %v", reserver.Reservations, recovered.(*parking).Reservations)
+	}
+
+	if sessions, _ := recovered.(*parking).History("1000"); len(sessions) != 1 || !sessions[0].LostTicket {
+		t.Errorf("Expected the recovered session of 1000 to keep its lost ticket, got %+v", sessions)
+	}
+
+	for vehicleNumber := range spots {
+		expected, _ := park.(*parking).History(vehicleNumber)
+		got, _ := recovered.(*parking).History(vehicleNumber)
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("Expected vehicle %s history %v, got %v", vehicleNumber, expected, got)
+		}
+	}
+
+	for _, vehicleType := range []parkingentity.VehicleType{parkingentity.A1, parkingentity.B1, parkingentity.M1} {
+		_, expected := park.AvailableSpot(vehicleType)
+		_, got := recovered.AvailableSpot(vehicleType)
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("Expected %v available spots in order %v, got %v", vehicleType, expected, got)
+		}
+	}
+}
+
+// lockCheckStore counts the snapshots stored while the write lock of the lot is held.
+type lockCheckStore struct {
+	parkingstore.Store
+	park           *parking
+	stored, locked int
+}
+
+func (s *lockCheckStore) Snapshot(state *parkingstore.State) error {
+	if s.park != nil {
+		s.stored++
+		if s.park.mutex.TryLock() {
+			s.park.mutex.Unlock()
+		} else {
+			s.locked++
+		}
+	}
+
+	return s.Store.Snapshot(state)
+}
+
+func TestSnapshotOutsideLock(t *testing.T) {
+	fileStore, err := parkingstore.OpenFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fileStore.Close()
+
+	store := &lockCheckStore{Store: fileStore}
+	p, err := NewPark(WithRandomizeParkingSpots(1, 10, 10), WithStore(store, 5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.park = p.(*parking)
+
+	for i := 0; i < 20; i++ {
+		spotID, err := p.Park(parkingentity.A1, parkingentity.PlateFromNumber(1000+i))
+		if err != nil {
+			continue
+		}
+		if _, err := p.Unpark(spotID.ID(), parkingentity.PlateFromNumber(1000+i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if store.stored == 0 || store.locked != 0 {
+		t.Errorf("Expected every snapshot stored after releasing the lock, %d of %d were not", store.locked, store.stored)
+	}
+}
+
+func TestSnapshotCountsOperationsOnce(t *testing.T) {
+	store, err := parkingstore.OpenFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	clock := clockx.NewFake(time.Date(2025, 1, 1, 8, 0, 0, 0, time.UTC))
+	p, err := NewPark(WithRandomizeParkingSpots(1, 10, 10), WithStore(store, 100), WithClock(clock), WithReservationSweep(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	park := p.(*parking)
+
+	// every operation counts once, releasing a hold inside it doesn't count again
+	steps := []struct {
+		name string
+		op   func() error
+	}{
+		{"reserve", func() error { _, err := park.Reserve(parkingentity.A1, "1000", time.Minute); return err }},
+		{"park releasing the expired hold", func() error {
+			clock.Advance(time.Hour)
+			_, err := park.Park(parkingentity.A1, "1000")
+			return err
+		}},
+		{"reserve again", func() error { _, err := park.Reserve(parkingentity.A1, "2000", time.Minute); return err }},
+		{"cancel", func() error { return park.CancelReservation("2000") }},
+		{"reserve a third time", func() error { _, err := park.Reserve(parkingentity.A1, "3000", time.Minute); return err }},
+		{"sweep", func() error {
+			clock.Advance(time.Hour)
+			park.releaseExpired()
+			return nil
+		}},
+	}
+	for i, step := range steps {
+		if err := step.op(); err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+		if park.ops != i+1 {
+			t.Errorf("%s: expected %d operations counted, got %d", step.name, i+1, park.ops)
+		}
+	}
+}
@@ -1,16 +1,26 @@
 package parkingcli
 
 import (
+	"fmt"
+	"github.com/mtfiqh/DoiT-parking-system/billing"
 	parkingpkg "github.com/mtfiqh/DoiT-parking-system/parking"
 	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingtest"
+	"github.com/mtfiqh/DoiT-parking-system/pkg/clockx"
+	"github.com/mtfiqh/DoiT-parking-system/pkg/queuex"
+	"reflect"
+	"slices"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 type parkingForDebug interface {
 	parkingpkg.ParkingSystem
 	GetSpaces() [][][]int
-	GetAvailableSpots() *parkingentity.AvailableSpots
-	GetVehiclesParked() map[int]parkingentity.VehicleSpot
+	GetAvailableSpots() map[parkingentity.VehicleType]parkingpkg.Allocator
+	GetVehiclesParked() map[parkingentity.Plate]parkingentity.VehicleSpot
 }
 
 func newParkForDebug(opts ...ParkOption) (parkingForDebug, error) {
@@ -26,11 +36,11 @@ func (p *parking) GetSpaces() [][][]int {
 	return p.Spaces
 }
 
-func (p *parking) GetAvailableSpots() *parkingentity.AvailableSpots {
+func (p *parking) GetAvailableSpots() map[parkingentity.VehicleType]parkingpkg.Allocator {
 	return p.AvailableSpots
 }
 
-func (p *parking) GetVehiclesParked() map[int]parkingentity.VehicleSpot {
+func (p *parking) GetVehiclesParked() map[parkingentity.Plate]parkingentity.VehicleSpot {
 	return p.VehiclesParked
 }
 
@@ -79,14 +89,14 @@ func TestSeedParkingSpots(t *testing.T) {
 
 	avSpots := p.GetAvailableSpots()
 
-	if countA1 != avSpots.A1.Size {
-		t.Fatalf("Available A1 spots mismatch: expected %d, got %d", countA1, avSpots.A1.Size)
+	if countA1 != len(avSpots[parkingentity.A1].Spots()) {
+		t.Fatalf("Available A1 spots mismatch: expected %d, got %d", countA1, len(avSpots[parkingentity.A1].Spots()))
 	}
-	if countB1 != avSpots.B1.Size {
-		t.Fatalf("Available B1 spots mismatch: expected %d, got %d", countB1, avSpots.B1.Size)
+	if countB1 != len(avSpots[parkingentity.B1].Spots()) {
+		t.Fatalf("Available B1 spots mismatch: expected %d, got %d", countB1, len(avSpots[parkingentity.B1].Spots()))
 	}
-	if countM1 != avSpots.M1.Size {
-		t.Fatalf("Available M1 spots mismatch: expected %d, got %d", countM1, avSpots.M1.Size)
+	if countM1 != len(avSpots[parkingentity.M1].Spots()) {
+		t.Fatalf("Available M1 spots mismatch: expected %d, got %d", countM1, len(avSpots[parkingentity.M1].Spots()))
 	}
 
 	//	assertion each spots
@@ -95,7 +105,7 @@ func TestSeedParkingSpots(t *testing.T) {
 			for k := 0; k < maxRows; k++ {
 				switch spaces[i][j][k] {
 				case int(parkingentity.M1):
-					if f, ok := avSpots.M1.Dequeue(); ok {
+					if f, ok := avSpots[parkingentity.M1].Allocate(); ok {
 						if f.Floor != i || f.Row != j || f.Col != k {
 							t.Fatalf("Expected M1 spot at (%d, %d, %d), got (%d, %d, %d)", i, j, k, f.Floor, f.Row, f.Col)
 						}
@@ -104,7 +114,7 @@ func TestSeedParkingSpots(t *testing.T) {
 					}
 
 				case int(parkingentity.B1):
-					if f, ok := avSpots.B1.Dequeue(); ok {
+					if f, ok := avSpots[parkingentity.B1].Allocate(); ok {
 						if f.Floor != i || f.Row != j || f.Col != k {
 							t.Fatalf("Expected B1 spot at (%d, %d, %d), got (%d, %d, %d)", i, j, k, f.Floor, f.Row, f.Col)
 						}
@@ -112,7 +122,7 @@ func TestSeedParkingSpots(t *testing.T) {
 						t.Fatalf("Expected B1 spot to be available, but it was not")
 					}
 				case int(parkingentity.A1):
-					if f, ok := avSpots.A1.Dequeue(); ok {
+					if f, ok := avSpots[parkingentity.A1].Allocate(); ok {
 						if f.Floor != i || f.Row != j || f.Col != k {
 							t.Fatalf("Expected A1 spot at (%d, %d, %d), got (%d, %d, %d)", i, j, k, f.Floor, f.Row, f.Col)
 						}
@@ -125,6 +135,23 @@ func TestSeedParkingSpots(t *testing.T) {
 	}
 }
 
+func TestSeedParkingSpotsWithSeed(t *testing.T) {
+	build := func(seed int64) [][][]int {
+		p, err := newParkForDebug(WithRandomizeParkingSpots(2, 10, 10), WithSeed(seed))
+		if err != nil {
+			t.Fatalf("Failed to create parking: %v", err)
+		}
+		return p.GetSpaces()
+	}
+
+	if !reflect.DeepEqual(build(1), build(1)) {
+		t.Error("Expected the same seed to build the same lot")
+	}
+	if reflect.DeepEqual(build(1), build(2)) {
+		t.Error("Expected different seeds to build different lots")
+	}
+}
+
 func TestPark(t *testing.T) {
 	const (
 		maxFloors = 5
@@ -142,31 +169,31 @@ func TestPark(t *testing.T) {
 
 		testCases := []struct {
 			name          string
-			vehicleNumber int
+			vehicleNumber parkingentity.Plate
 			vehicleType   parkingentity.VehicleType
 			expectedError bool
 		}{
 			{
 				name:          "parking A1",
-				vehicleNumber: 1001,
+				vehicleNumber: "1001",
 				vehicleType:   parkingentity.A1,
 				expectedError: false,
 			},
 			{
 				name:          "parking A1 already park",
-				vehicleNumber: 1001,
+				vehicleNumber: "1001",
 				vehicleType:   parkingentity.A1,
 				expectedError: true,
 			},
 			{
 				name:          "parking B1",
-				vehicleNumber: 1010,
+				vehicleNumber: "1010",
 				vehicleType:   parkingentity.B1,
 				expectedError: false,
 			},
 			{
 				name:          "parking M1",
-				vehicleNumber: 1100,
+				vehicleNumber: "1100",
 				vehicleType:   parkingentity.M1,
 				expectedError: false,
 			},
@@ -201,10 +228,10 @@ func TestPark(t *testing.T) {
 
 		ftest := func(vehicleType parkingentity.VehicleType) {
 			currentTotalVehicleParked := len(park.GetVehiclesParked())
-			totalAvailableSpaces := park.GetAvailableSpots().A1.Size
+			totalAvailableSpaces := len(park.GetAvailableSpots()[parkingentity.A1].Spots())
 
 			for i := 0; i < totalAvailableSpaces; i++ {
-				spotID, err := park.Park(vehicleType, 1000+i)
+				spotID, err := park.Park(vehicleType, parkingentity.PlateFromNumber(1000+i))
 
 				if err != nil {
 					t.Errorf("Failed to park %v vehicle %d: %v", vehicleType, 1000+i, err)
@@ -216,7 +243,7 @@ func TestPark(t *testing.T) {
 			}
 
 			// Check if all A1 spots are occupied and try 1 more
-			_, err := park.Park(vehicleType, 1000+totalAvailableSpaces)
+			_, err := park.Park(vehicleType, parkingentity.PlateFromNumber(1000+totalAvailableSpaces))
 			if err == nil {
 				t.Errorf("Expected an error when parking %v, but got none", vehicleType)
 			}
@@ -247,37 +274,37 @@ func TestPark(t *testing.T) {
 		}
 
 		t.Run("unpark A1 vehicle not found", func(t *testing.T) {
-			err := park.Unpark("1-1-1", 1001)
+			_, err := park.Unpark("1-1-1", "1001")
 			if err == nil {
 				t.Error("Expected an error when unparking a vehicle that is not parked, but got none")
 			}
 		})
 
 		t.Run("unpark A1 vehicle found but spotID not match", func(t *testing.T) {
-			_, err := park.Park(parkingentity.A1, 2001)
+			_, err := park.Park(parkingentity.A1, "2001")
 			if err != nil {
 				t.Fatalf("Failed to park A1 vehicle: %v", err)
 			}
 
-			err = park.Unpark("1000-1000-1000", 2001)
+			_, err = park.Unpark("1000-1000-1000", "2001")
 			if err == nil {
 				t.Error("Expected an error when unparking with a mismatched spotID, but got none")
 			}
 		})
 
 		t.Run("unpark A1, validate state park back to available spaces", func(t *testing.T) {
-			spotID, err := park.Park(parkingentity.A1, 2002)
+			spotID, err := park.Park(parkingentity.A1, "2002")
 			if err != nil {
 				t.Fatalf("Failed to park A1 vehicle: %v", err)
 			}
 
-			err = park.Unpark(spotID.ID(), 2002)
+			_, err = park.Unpark(spotID.ID(), "2002")
 			if err != nil {
 				t.Error("Expected no error when unparking a vehicle, but got:", err)
 			}
 
 			// Check if the spot is now available
-			avSpots := park.GetAvailableSpots().A1.Print()
+			avSpots := park.GetAvailableSpots()[parkingentity.A1].Spots()
 			avSpot := avSpots[len(avSpots)-1]
 
 			spotLastID := parkingentity.SpotID{
@@ -301,19 +328,19 @@ func TestPark(t *testing.T) {
 		}
 
 		t.Run("search vehicle not found", func(t *testing.T) {
-			_, err := park.SearchVehicle(9999)
+			_, err := park.SearchVehicle("9999")
 			if err == nil {
 				t.Error("Expected an error when searching for a vehicle that is not parked, but got none")
 			}
 		})
 
 		t.Run("search parked vehicle", func(t *testing.T) {
-			parkSpotID, err := park.Park(parkingentity.A1, 1001)
+			parkSpotID, err := park.Park(parkingentity.A1, "1001")
 			if err != nil {
 				t.Fatalf("Failed to park A1 vehicle: %v", err)
 			}
 
-			spotID, err := park.SearchVehicle(1001)
+			spotID, err := park.SearchVehicle("1001")
 			if err != nil {
 				t.Fatalf("Failed to search parked vehicle: %v", err)
 			}
@@ -328,17 +355,17 @@ func TestPark(t *testing.T) {
 		})
 
 		t.Run("search parked after unpark should be return last park", func(t *testing.T) {
-			parkSpotID, err := park.Park(parkingentity.A1, 1003)
+			parkSpotID, err := park.Park(parkingentity.A1, "1003")
 			if err != nil {
 				t.Fatalf("Failed to park A1 vehicle: %v", err)
 			}
 
-			err = park.Unpark(parkSpotID.ID(), 1003)
+			_, err = park.Unpark(parkSpotID.ID(), "1003")
 			if err != nil {
 				t.Fatalf("Failed to unpark A1 vehicle: %v", err)
 			}
 
-			spotID, err := park.SearchVehicle(1003)
+			spotID, err := park.SearchVehicle("1003")
 			if err != nil {
 				t.Error("Expected no error when searching for a parked vehicle, but got:", err)
 			}
@@ -349,27 +376,27 @@ func TestPark(t *testing.T) {
 		})
 
 		t.Run("search parked vehicle after park, unpark, park, unpark should be return last park", func(t *testing.T) {
-			parkSpotID, err := park.Park(parkingentity.A1, 1004)
+			parkSpotID, err := park.Park(parkingentity.A1, "1004")
 			if err != nil {
 				t.Fatalf("Failed to park A1 vehicle: %v", err)
 			}
 
-			err = park.Unpark(parkSpotID.ID(), 1004)
+			_, err = park.Unpark(parkSpotID.ID(), "1004")
 			if err != nil {
 				t.Fatalf("Failed to unpark A1 vehicle: %v", err)
 			}
 
-			parkSpotID, err = park.Park(parkingentity.A1, 1004)
+			parkSpotID, err = park.Park(parkingentity.A1, "1004")
 			if err != nil {
 				t.Fatalf("Failed to park A1 vehicle: %v", err)
 			}
 
-			err = park.Unpark(parkSpotID.ID(), 1004)
+			_, err = park.Unpark(parkSpotID.ID(), "1004")
 			if err != nil {
 				t.Fatalf("Failed to unpark A1 vehicle: %v", err)
 			}
 
-			spotID, err := park.SearchVehicle(1004)
+			spotID, err := park.SearchVehicle("1004")
 			if err != nil {
 				t.Error("Expected no error when searching for a parked vehicle, but got:", err)
 			}
@@ -428,7 +455,7 @@ func TestPark(t *testing.T) {
 				t.Fatalf("Expected %d available A1 spaces before parking, got %d", currentTotal, len(currentSpaces))
 			}
 
-			spotID, err := park.Park(parkingentity.A1, 1005)
+			spotID, err := park.Park(parkingentity.A1, "1005")
 			if err != nil {
 				t.Fatalf("Failed to park A1 vehicle: %v", err)
 			}
@@ -442,7 +469,7 @@ func TestPark(t *testing.T) {
 				t.Errorf("Expected %d available A1 spaces after parking, got %d", currentTotal-1, total)
 			}
 
-			err = park.Unpark(spotID.ID(), 1005)
+			_, err = park.Unpark(spotID.ID(), "1005")
 			if err != nil {
 				t.Fatalf("Failed to unpark A1 vehicle: %v", err)
 			}
@@ -460,3 +487,439 @@ func TestPark(t *testing.T) {
 
 	})
 }
+
+func TestConformance(t *testing.T) {
+	parkingtest.RunConformance(t, func() parkingpkg.ParkingSystem {
+		park, err := NewPark(WithRandomizeParkingSpots(2, 20, 20))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return park
+	})
+}
+
+func TestLockFreeConformance(t *testing.T) {
+	parkingtest.RunConformance(t, func() parkingpkg.ParkingSystem {
+		park, err := NewPark(WithRandomizeParkingSpots(2, 20, 20), WithStrategy(parkingpkg.FIFOOn(queuex.NewLockFree[parkingentity.Spot])))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return park
+	})
+}
+
+func TestSpotQueryConformance(t *testing.T) {
+	parkingtest.RunSpotQueryConformance(t, func() parkingpkg.ParkingSystem {
+		park, err := NewPark(WithRandomizeParkingSpots(2, 5, 5))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return park
+	})
+}
+
+func TestSessionConformance(t *testing.T) {
+	parkingtest.RunSessionConformance(t, func(clock clockx.Clock) parkingpkg.ParkingSystem {
+		park, err := NewPark(WithRandomizeParkingSpots(2, 20, 20), WithClock(clock))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return park
+	})
+}
+
+func TestSpotFormat(t *testing.T) {
+	format := parkingentity.SpotFormat{OneBased: true, Basements: 1, ColumnWidth: 2}
+	park, err := NewPark(WithRandomizeParkingSpots(2, 20, 20), WithSpotFormat(format))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := parkingpkg.SpotFormatOf(park); got != format {
+		t.Fatalf("Expected the lot format %+v, got %+v", format, got)
+	}
+
+	spotID, err := park.Park(parkingentity.A1, "B 1234 XYZ")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the raw ID reads as another floor, row and column in the lot's format
+	if _, err := park.Unpark(spotID.ID(), "B 1234 XYZ"); err == nil {
+		t.Errorf("Expected the raw spot ID %s to be refused", spotID.ID())
+	}
+
+	receipt, err := park.Unpark(format.Format(*spotID), "B 1234 XYZ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if receipt.Session.SpotID != *spotID {
+		t.Errorf("Expected spot %+v, got %+v", *spotID, receipt.Session.SpotID)
+	}
+}
+
+func TestLostTicketConformance(t *testing.T) {
+	parkingtest.RunLostTicketConformance(t, func(clock clockx.Clock, biller parkingpkg.Biller) parkingpkg.ParkingSystem {
+		park, err := NewPark(WithRandomizeParkingSpots(1, 10, 10), WithClock(clock), WithBilling(biller))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return park
+	})
+}
+
+func TestReservationConformance(t *testing.T) {
+	parkingtest.RunReservationConformance(t, func(clock clockx.Clock) parkingpkg.ParkingSystem {
+		park, err := NewPark(WithRandomizeParkingSpots(2, 20, 20), WithClock(clock), WithReservationSweep(0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return park
+	})
+}
+
+func TestFallbackConformance(t *testing.T) {
+	parkingtest.RunFallbackConformance(t, func(fallback parkingentity.Fallback) parkingpkg.ParkingSystem {
+		park, err := NewPark(WithRandomizeParkingSpots(2, 10, 10), WithFallback(fallback))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return park
+	})
+}
+
+func TestStrategy(t *testing.T) {
+	park, err := NewPark(WithRandomizeParkingSpots(2, 10, 10), WithStrategy(parkingpkg.NearestTo(parkingentity.Spot{})))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := park.Park(parkingentity.A1, "1000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := park.Unpark(first.ID(), "1000"); err != nil {
+		t.Fatal(err)
+	}
+
+	// FIFO would send the next vehicle further in, the nearest strategy gives the freed spot again or one as near
+	again, err := park.Park(parkingentity.A1, "2000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Floor != first.Floor || again.Row+again.Col != first.Row+first.Col {
+		t.Errorf("Expected the freed spot %s or one as near, got %s", first.ID(), again.ID())
+	}
+}
+
+func TestGates(t *testing.T) {
+	west := parkingentity.Gate{ID: "west", Kind: parkingentity.GateEntry}
+	east := parkingentity.Gate{ID: "east", Spot: parkingentity.Spot{Col: 19}}
+	south := parkingentity.Gate{ID: "south", Spot: parkingentity.Spot{Row: 19}, Kind: parkingentity.GateExit}
+	gates := []parkingentity.Gate{west, east, south}
+
+	p, err := NewPark(WithRandomizeParkingSpots(1, 20, 20), WithGates(gates...), WithStrategy(parkingpkg.NearestGate(gates)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	park := p.(*parking)
+
+	spotID, err := park.ParkAt("east", parkingentity.A1, "1000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if spotID.Col < 10 {
+		t.Errorf("Expected a spot on the east side, got %s", spotID.ID())
+	}
+
+	if _, err := park.ParkAt("south", parkingentity.A1, "2000"); err != parkingentity.ErrGateDirection {
+		t.Errorf("Expected %v entering at an exit, got %v", parkingentity.ErrGateDirection, err)
+	}
+	if _, err := park.ParkAt("north", parkingentity.A1, "2000"); err != parkingentity.ErrUnknownGate {
+		t.Errorf("Expected %v, got %v", parkingentity.ErrUnknownGate, err)
+	}
+	if _, err := park.UnparkAt("west", spotID.ID(), "1000"); err != parkingentity.ErrGateDirection {
+		t.Errorf("Expected %v leaving at an entry, got %v", parkingentity.ErrGateDirection, err)
+	}
+
+	if _, err := park.UnparkAt("south", spotID.ID(), "1000"); err != nil {
+		t.Fatal(err)
+	}
+
+	sessions, _ := park.History("1000")
+	if len(sessions) != 1 || sessions[0].Gate != "east" || sessions[0].ExitGate != "south" {
+		t.Errorf("Expected a session from east to south, got %+v", sessions)
+	}
+}
+
+func TestLostTicket(t *testing.T) {
+	clock := clockx.NewFake(time.Date(2025, 1, 1, 8, 0, 0, 0, time.UTC))
+	tariffs := billing.New(billing.Config{Tariffs: map[parkingentity.VehicleType]billing.Tariff{
+		parkingentity.A1: {FirstHour: 5000, Hourly: 3000, LostTicket: 25000},
+	}}, billing.WithClock(clock))
+	gates := []parkingentity.Gate{{ID: "entry", Kind: parkingentity.GateEntry}, {ID: "exit", Kind: parkingentity.GateExit}}
+
+	p, err := NewPark(WithRandomizeParkingSpots(1, 10, 10), WithGates(gates...), WithClock(clock), WithBilling(tariffs))
+	if err != nil {
+		t.Fatal(err)
+	}
+	park := p.(*parking)
+
+	spotID, err := park.ParkAt("entry", parkingentity.A1, "B 1234 XYZ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(time.Hour)
+
+	if _, err := park.UnparkLostTicket("entry", "B1234XYZ"); err != parkingentity.ErrGateDirection {
+		t.Errorf("Expected %v leaving at an entry, got %v", parkingentity.ErrGateDirection, err)
+	}
+
+	// the vehicle is found by its plate, the first hour and the penalty are charged
+	receipt, err := park.UnparkLostTicket("exit", "b-1234-xyz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if receipt.Amount != 5000+25000 || receipt.Session.SpotID != *spotID || !receipt.Session.LostTicket {
+		t.Errorf("Expected 30000 for a lost ticket at %s, got %+v", spotID.ID(), receipt)
+	}
+
+	if _, err := park.UnparkLostTicket("", "B1234XYZ"); err != parkingentity.ErrVehicleNotFound {
+		t.Errorf("Expected %v unparking twice, got %v", parkingentity.ErrVehicleNotFound, err)
+	}
+
+	sessions, _ := park.History("B1234XYZ")
+	if len(sessions) != 1 || !sessions[0].LostTicket || sessions[0].ExitGate != "exit" {
+		t.Errorf("Expected a lost ticket session leaving at exit, got %+v", sessions)
+	}
+	if _, free := park.AvailableSpot(parkingentity.A1); !slices.Contains(free, parkingentity.Spot(*spotID)) {
+		t.Errorf("Expected spot %s to be free again", spotID.ID())
+	}
+}
+
+func TestSpotAdmin(t *testing.T) {
+	p, err := NewPark(WithRandomizeParkingSpots(1, 10, 10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	park := p.(*parking)
+
+	total, spots := park.AvailableSpot(parkingentity.A1)
+	closed := parkingentity.SpotID(spots[0])
+	if err := park.DisableSpot(closed.ID()); err != nil {
+		t.Fatal(err)
+	}
+	if err := park.DisableSpot(closed.ID()); err != nil {
+		t.Errorf("Expected disabling twice to succeed, got %v", err)
+	}
+	if park.Spaces[closed.Floor][closed.Row][closed.Col] != int(parkingentity.X0) {
+		t.Errorf("Expected %s to be laid out as X-0", closed.ID())
+	}
+
+	// every other spot fills up, the closed one is never given out
+	for i := 0; i < total-1; i++ {
+		spotID, err := park.Park(parkingentity.A1, parkingentity.PlateFromNumber(1000+i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if *spotID == closed {
+			t.Errorf("Expected the disabled spot %s never to be allocated", closed.ID())
+		}
+	}
+	if _, err := park.Park(parkingentity.A1, "9999"); err != parkingentity.ErrSpotNotFound {
+		t.Errorf("Expected %v, got %v", parkingentity.ErrSpotNotFound, err)
+	}
+
+	occupied, _ := park.SearchVehicle(parkingentity.PlateFromNumber(1000))
+	if err := park.DisableSpot(occupied.ID()); err != parkingentity.ErrSpotOccupied {
+		t.Errorf("Expected %v, got %v", parkingentity.ErrSpotOccupied, err)
+	}
+	if err := park.RetypeSpot(closed.ID(), parkingentity.M1); err != parkingentity.ErrSpotDisabled {
+		t.Errorf("Expected %v, got %v", parkingentity.ErrSpotDisabled, err)
+	}
+	if err := park.EnableSpot(occupied.ID(), parkingentity.A1); err != parkingentity.ErrSpotEnabled {
+		t.Errorf("Expected %v, got %v", parkingentity.ErrSpotEnabled, err)
+	}
+	if err := park.EnableSpot(closed.ID(), parkingentity.X0); err != parkingentity.ErrInvalidVehicleType {
+		t.Errorf("Expected %v, got %v", parkingentity.ErrInvalidVehicleType, err)
+	}
+
+	if err := park.EnableSpot(closed.ID(), parkingentity.A1); err != nil {
+		t.Fatal(err)
+	}
+	if spotID, err := park.Park(parkingentity.A1, "9999"); err != nil || *spotID != closed {
+		t.Errorf("Expected the enabled spot %s, got %v, %v", closed.ID(), spotID, err)
+	}
+
+	// a freed A-1 spot turns into a motorcycle spot
+	if _, err := park.Unpark(occupied.ID(), parkingentity.PlateFromNumber(1000)); err != nil {
+		t.Fatal(err)
+	}
+	motorcycles, _ := park.AvailableSpot(parkingentity.M1)
+	if err := park.RetypeSpot(occupied.ID(), parkingentity.M1); err != nil {
+		t.Fatal(err)
+	}
+	if cars, _ := park.AvailableSpot(parkingentity.A1); cars != 0 {
+		t.Errorf("Expected no A-1 spot left, got %d", cars)
+	}
+	if after, spots := park.AvailableSpot(parkingentity.M1); after != motorcycles+1 || spots[len(spots)-1] != parkingentity.Spot(*occupied) {
+		t.Errorf("Expected %s at the tail of %d M-1 spots, got %d %v", occupied.ID(), motorcycles+1, after, spots)
+	}
+
+	if err := park.DisableSpot("5-0-0"); err != parkingentity.ErrSpotOutOfRange {
+		t.Errorf("Expected %v, got %v", parkingentity.ErrSpotOutOfRange, err)
+	}
+}
+
+func TestOccupancy(t *testing.T) {
+	path := writeLayout(t, "lot.txt", "A-1 A-1 M-1 X-0\nB-1 B-1 A-1 A-1\n---\nM-1 M-1\n")
+	p, err := NewPark(WithLayoutFile(path), WithFallback(parkingentity.FallbackBySize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	park := p.(*parking)
+
+	// the motorcycle spots of floor 0 and 1 run out, the fourth motorcycle overflows into an A-1 spot
+	for i := 0; i < 4; i++ {
+		if _, err := park.Park(parkingentity.M1, parkingentity.PlateFromNumber(1000+i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := park.Reserve(parkingentity.B1, "2000", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	occupancy := park.Occupancy()
+	expected := []parkingentity.FloorOccupancy{
+		{
+			Floor: 0,
+			Types: map[parkingentity.VehicleType]parkingentity.SpotCount{
+				parkingentity.A1: {Total: 4, Occupied: 1},
+				parkingentity.B1: {Total: 2, Held: 1},
+				parkingentity.M1: {Total: 1, Occupied: 1},
+				parkingentity.X0: {Total: 1},
+			},
+			Rows: []parkingentity.SpotCount{{Total: 3, Occupied: 2}, {Total: 4, Held: 1}},
+		},
+		{
+			Floor: 1,
+			Types: map[parkingentity.VehicleType]parkingentity.SpotCount{parkingentity.M1: {Total: 2, Occupied: 2}},
+			Rows:  []parkingentity.SpotCount{{Total: 2, Occupied: 2}},
+		},
+	}
+	if !reflect.DeepEqual(occupancy.Floors, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, occupancy.Floors)
+	}
+
+	// the counters follow every change the way a full count of the lot does
+	steps := []func() error{
+		func() error { _, err := park.Unpark("1-0-0", "1001"); return err },
+		func() error { _, err := park.ClaimReservation("2000"); return err },
+		func() error { _, err := park.Reserve(parkingentity.A1, "3000", time.Hour); return err },
+		func() error { return park.CancelReservation("3000") },
+		func() error { return park.DisableSpot("1-0-0") },
+		func() error { return park.EnableSpot("0-0-3", parkingentity.B1) },
+		func() error { return park.RetypeSpot("0-1-3", parkingentity.M1) },
+	}
+	for i, step := range steps {
+		if err := step(); err != nil {
+			t.Fatalf("Step %d: %v", i, err)
+		}
+		if counted := park.countOccupancy(); !reflect.DeepEqual(park.Occupancy(), counted) {
+			t.Fatalf("Step %d: expected %+v, got %+v", i, counted, park.Occupancy())
+		}
+	}
+}
+
+func TestEvents(t *testing.T) {
+	path := writeLayout(t, "lot.txt", "A-1 A-1\n")
+	bus := parkingpkg.NewBus()
+	events := bus.Subscribe(100)
+
+	p, err := NewPark(WithLayoutFile(path), WithEvents(bus))
+	if err != nil {
+		t.Fatal(err)
+	}
+	park := p.(*parking)
+
+	first, err := park.Park(parkingentity.A1, "1000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := park.Park(parkingentity.A1, "2000"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := park.Park(parkingentity.A1, "3000"); err != parkingentity.ErrSpotNotFound {
+		t.Fatalf("Expected %v, got %v", parkingentity.ErrSpotNotFound, err)
+	}
+	if _, err := park.Unpark(first.ID(), "1000"); err != nil {
+		t.Fatal(err)
+	}
+	if err := park.DisableSpot(first.ID()); err != nil {
+		t.Fatal(err)
+	}
+	bus.Close()
+
+	var got []string
+	for event := range events.C {
+		got = append(got, string(event.Type)+" "+string(event.VehicleNumber))
+	}
+
+	expected := []string{
+		"vehicle_parked 1000",
+		"vehicle_parked 2000",
+		"lot_full 3000",
+		"vehicle_unparked 1000",
+		"spot_freed ",
+		"spot_disabled ",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected events %v, got %v", expected, got)
+	}
+}
+
+// BenchmarkPark parks and unparks with gates goroutines on the queues of the strategies,
+// parks take their spot without the lock of the lot, unparks still free it under the lock.
+func BenchmarkPark(b *testing.B) {
+	for _, name := range []string{"fifo", "lock-free", "sharded"} {
+		strategy, err := parkingpkg.StrategyFor(name)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		for _, gates := range []int{1, 10, 100, 1000} {
+			b.Run(fmt.Sprintf("%s/gates=%d", name, gates), func(b *testing.B) {
+				park, err := NewPark(WithRandomizeParkingSpots(8, 50, 50), WithStrategy(strategy))
+				if err != nil {
+					b.Fatal(err)
+				}
+
+				var (
+					wg   sync.WaitGroup
+					next atomic.Int64
+				)
+				b.ResetTimer()
+				for gate := 0; gate < gates; gate++ {
+					ops := b.N / gates
+					if gate < b.N%gates {
+						ops++
+					}
+
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						for i := 0; i < ops; i++ {
+							vehicleNumber := parkingentity.PlateFromNumber(int(next.Add(1)))
+							spotID, err := park.Park(parkingentity.A1, vehicleNumber)
+							if err != nil {
+								continue
+							}
+							_, _ = park.Unpark(spotID.ID(), vehicleNumber)
+						}
+					}()
+				}
+				wg.Wait()
+			})
+		}
+	}
+}
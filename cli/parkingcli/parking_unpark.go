@@ -1,49 +1,150 @@
 package parkingcli
 
 import (
+	parkingpkg "github.com/mtfiqh/DoiT-parking-system/parking"
 	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
-	"github.com/mtfiqh/DoiT-parking-system/pkg/queuex"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingmetrics"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingstore"
+	"time"
 )
 
-func (p *parking) Unpark(spotID string, vehicleNumber int) error {
-	p.mutex.RLock()
-	vehicleSpot, exists := p.VehiclesParked[vehicleNumber]
-	p.mutex.RUnlock()
-	if !exists {
-		return parkingentity.ErrVehicleNotFound
+func (p *parking) Unpark(spotID string, vehicleNumber parkingentity.Plate) (*parkingentity.Receipt, error) {
+	return p.unpark(nil, spotID, vehicleNumber, false)
+}
+
+func (p *parking) UnparkAt(gateID string, spotID string, vehicleNumber parkingentity.Plate) (*parkingentity.Receipt, error) {
+	gate, err := p.gate(gateID)
+	if err != nil {
+		return nil, err
 	}
+	if !gate.Exit() {
+		return nil, parkingentity.ErrGateDirection
+	}
+
+	return p.unpark(&gate, spotID, vehicleNumber, false)
+}
 
-	if vehicleSpot.SpotID.ID() != spotID {
-		return parkingentity.ErrVehicleNotFound
+func (p *parking) UnparkLostTicket(gateID string, vehicleNumber parkingentity.Plate) (*parkingentity.Receipt, error) {
+	if gateID == "" {
+		return p.unpark(nil, "", vehicleNumber, true)
 	}
 
-	if vehicleSpot.StillParked == false {
-		return parkingentity.ErrVehicleNotFound
+	gate, err := p.gate(gateID)
+	if err != nil {
+		return nil, err
 	}
+	if !gate.Exit() {
+		return nil, parkingentity.ErrGateDirection
+	}
+
+	return p.unpark(&gate, "", vehicleNumber, true)
+}
 
+// unpark unparks the vehicle leaving at the gate, nil when unknown.
+// Without its ticket the vehicle is found by its plate instead of the spot ID, and charged the lost ticket penalty.
+func (p *parking) unpark(gate *parkingentity.Gate, spotID string, vehicleNumber parkingentity.Plate, lostTicket bool) (_ *parkingentity.Receipt, err error) {
+	// unknown until the vehicle is found
+	vehicleType := parkingentity.X0
+	start := time.Now()
+	defer func() { p.metrics.Observe(parkingmetrics.OpUnpark, vehicleType, err, time.Since(start)) }()
+
+	vehicleNumber = vehicleNumber.Normalize()
+
+	var id parkingentity.SpotID
+	if !lostTicket {
+		id, err = p.spotFormat.Parse(spotID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// one critical section, so the same vehicle leaving at two gates frees its spot once
 	p.mutex.Lock()
-	defer p.mutex.Unlock()
-	vehicleSpot.StillParked = false
-	p.VehiclesParked[vehicleNumber] = vehicleSpot
+	defer p.unlock()
+
+	vehicleSpot, exists := p.VehiclesParked[vehicleNumber]
+	if lostTicket {
+		id = vehicleSpot.SpotID
+	} else if !p.inLot(id) {
+		return nil, parkingentity.ErrSpotOutOfRange
+	}
 
-	var qfunc *queuex.Queue[parkingentity.Spot]
-	// Find an available spot for the vehicle type
-	switch vehicleSpot.Type {
-	case parkingentity.A1:
-		qfunc = p.AvailableSpots.A1
-	case parkingentity.B1:
-		qfunc = p.AvailableSpots.B1
-	case parkingentity.M1:
-		qfunc = p.AvailableSpots.M1
-	default:
-		return parkingentity.ErrInvalidVehicleType
+	if !exists || vehicleSpot.StillParked == false {
+		return nil, parkingentity.ErrVehicleNotFound
 	}
+	vehicleType = vehicleSpot.Type
 
-	qfunc.Enqueue(parkingentity.Spot{
+	if vehicleSpot.SpotID != id {
+		return nil, parkingentity.ErrVehicleNotAtSpot
+	}
+
+	spot := parkingentity.Spot{
 		Floor: vehicleSpot.Floor,
 		Col:   vehicleSpot.Col,
 		Row:   vehicleSpot.Row,
-	})
+	}
+
+	// the spot goes back to the allocator of its own type, the vehicle may have overflowed into it
+	allocator := p.allocator(p.spotType(vehicleSpot.SpotID))
+	if allocator == nil {
+		return nil, parkingentity.ErrInvalidVehicleType
+	}
+
+	now := p.clock.Now()
+
+	// charge before anything changes, a failed charge keeps the vehicle parked
+	session := parkingentity.Session{VehicleNumber: vehicleNumber, VehicleType: vehicleSpot.Type, SpotID: vehicleSpot.SpotID, EntryAt: now}
+	sessions := p.VehicleSessions[vehicleNumber]
+	if len(sessions) > 0 {
+		session = p.Sessions[sessions[len(sessions)-1]]
+	}
+	session.ExitAt = now
+	session.ExitGate = gateID(gate)
+	session.LostTicket = lostTicket
+
+	receipt, err := p.charge(session)
+	if err != nil {
+		return nil, err
+	}
+
+	err = p.persist(parkingstore.Record{Op: parkingstore.OpUnpark, VehicleNumber: vehicleNumber, VehicleType: vehicleSpot.Type, Spot: spot, Gate: gateID(gate), Time: now, LostTicket: lostTicket})
+	if err != nil {
+		return nil, err
+	}
+
+	vehicleSpot.StillParked = false
+	p.VehiclesParked[vehicleNumber] = vehicleSpot
+	count(&p.occupancy, vehicleSpot.SpotID, p.spotType(vehicleSpot.SpotID), parkingentity.SpotCount{Occupied: -1})
+
+	if len(sessions) > 0 {
+		p.Sessions[sessions[len(sessions)-1]] = session
+	}
+
+	allocator.Release(spot)
+
+	p.events.Publish(parkingpkg.Event{Type: parkingpkg.VehicleUnparked, Time: now, VehicleNumber: vehicleNumber, VehicleType: vehicleSpot.Type, SpotID: &vehicleSpot.SpotID, Gate: gateID(gate)})
+	p.events.Publish(parkingpkg.Event{Type: parkingpkg.SpotFreed, Time: now, VehicleType: p.spotType(vehicleSpot.SpotID), SpotID: &vehicleSpot.SpotID})
+
+	p.snapshotIfDue()
+
+	return &receipt, nil
+}
+
+// charge computes the receipt of a closed session, without a biller only the duration is filled.
+func (p *parking) charge(session parkingentity.Session) (parkingentity.Receipt, error) {
+	if p.biller == nil {
+		return parkingentity.Receipt{Session: session, Duration: session.ExitAt.Sub(session.EntryAt)}, nil
+	}
+
+	return p.biller.Charge(session)
+}
+
+// spotType returns the vehicle type the spot is laid out for.
+func (p *parking) spotType(spot parkingentity.SpotID) parkingentity.VehicleType {
+	return parkingentity.VehicleType(p.Spaces[spot.Floor][spot.Row][spot.Col])
+}
 
-	return nil
+// inLot reports whether the spot is inside the spaces of the lot.
+func (p *parking) inLot(spot parkingentity.SpotID) bool {
+	return spot.Floor < len(p.Spaces) && spot.Row < len(p.Spaces[spot.Floor]) && spot.Col < len(p.Spaces[spot.Floor][spot.Row])
 }
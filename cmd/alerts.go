@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"github.com/mtfiqh/DoiT-parking-system/alerting"
+	"github.com/spf13/cobra"
+	"time"
+)
+
+var alertsPath string
+
+// addAlertsFlag registers the flag of the alert file.
+func addAlertsFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&alertsPath, "alerts", "", "JSON alert file with the occupancy levels to alert at and the notifiers, off when empty")
+}
+
+// loadAlerts returns the monitor of --alerts and how often it checks the lot, nil when it's empty.
+// Without notifiers in the file the alerts are logged.
+func loadAlerts() (*alerting.Monitor, time.Duration, error) {
+	if alertsPath == "" {
+		return nil, 0, nil
+	}
+
+	file, err := alerting.LoadConfig(alertsPath)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(file.Notifiers) == 0 {
+		file.Notifiers = []alerting.Notifier{alerting.LogNotifier{}}
+	}
+
+	return alerting.NewMonitor(file.Config, file.Notifiers...), file.Interval, nil
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"github.com/mtfiqh/DoiT-parking-system/api"
+	"github.com/spf13/cobra"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+var addr string
+
+var serveCmd = &cobra.Command{
+	Use:   "api:serve",
+	Short: "Serve the parking system over HTTP",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		park, closePark, err := newPark()
+		if err != nil {
+			return err
+		}
+		defer closePark()
+
+		srv := &http.Server{
+			Addr:    addr,
+			Handler: api.NewServer(park),
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		go func() {
+			<-ctx.Done()
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			_ = srv.Shutdown(shutdownCtx)
+		}()
+
+		fmt.Printf("🚗 Parking API listening on %s\n", addr)
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			return err
+		}
+
+		log.Println("Parking API stopped.")
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().StringVar(&addr, "addr", ":8080", "Address to listen on")
+	addParkFlags(serveCmd)
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"github.com/mtfiqh/DoiT-parking-system/cli"
+	"github.com/mtfiqh/DoiT-parking-system/cli/parkingcli"
+	parkingpkg "github.com/mtfiqh/DoiT-parking-system/parking"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"io"
+	"os"
+	"time"
+)
+
+// the settings of bench:report have their own defaults, so reports of different runs compare
+var (
+	benchGates    int
+	benchFloor    int
+	benchRows     int
+	benchColumn   int
+	benchDuration time.Duration
+	benchStrategy string
+	benchSeed     int64
+	benchOut      string
+)
+
+var benchReportCmd = &cobra.Command{
+	Use:   "bench:report",
+	Short: "Run the parking operations at fixed settings and report throughput, latencies and allocations as JSON",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		allocation, err := parkingpkg.StrategyForGates(benchStrategy, nil)
+		if err != nil {
+			return err
+		}
+
+		report, err := cli.RunBenchmark(cli.BenchConfig{
+			Gates:       benchGates,
+			Duration:    benchDuration,
+			ParkOptions: []parkingcli.ParkOption{parkingcli.WithRandomizeParkingSpots(benchFloor, benchColumn, benchRows), parkingcli.WithStrategy(allocation)},
+			Seed:        benchSeed,
+		})
+		if err != nil {
+			return err
+		}
+
+		var out io.Writer = os.Stdout
+		if benchOut != "-" {
+			file, err := os.Create(benchOut)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+			out = file
+		}
+
+		if err := report.WriteJSON(out); err != nil {
+			return errors.Wrap(err, "writing the report")
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(benchReportCmd)
+
+	benchReportCmd.Flags().IntVar(&benchGates, "gates", 100, "Number of gates running operations at the same time")
+	benchReportCmd.Flags().IntVar(&benchFloor, "floor", 4, "Number of floors")
+	benchReportCmd.Flags().IntVar(&benchRows, "rows", 100, "Number of rows per floor")
+	benchReportCmd.Flags().IntVar(&benchColumn, "column", 100, "Number of columns per row")
+	benchReportCmd.Flags().DurationVar(&benchDuration, "duration", 10*time.Second, "How long the operations run")
+	benchReportCmd.Flags().StringVar(&benchStrategy, "strategy", "fifo", "Spot allocation strategy: fifo, lock-free, sharded, nearest, lowest-floor, fill-floor or random")
+	benchReportCmd.Flags().Int64Var(&benchSeed, "seed", 1, "Seed of the lot layout and of the operations of every gate")
+	benchReportCmd.Flags().StringVar(&benchOut, "out", "-", "File to write the JSON report to, - for stdout")
+}
@@ -3,7 +3,13 @@ package cmd
 import (
 	"fmt"
 	"github.com/mtfiqh/DoiT-parking-system/cli"
+	"github.com/mtfiqh/DoiT-parking-system/cli/parkingcli"
+	parkingpkg "github.com/mtfiqh/DoiT-parking-system/parking"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingmetrics"
+	"github.com/mtfiqh/DoiT-parking-system/pkg/metricsx"
 	"github.com/spf13/cobra"
+	"io"
+	"os"
 	"time"
 )
 
@@ -13,6 +19,9 @@ var (
 	rows     int
 	column   int
 	duration time.Duration
+	layout   string
+	strategy string
+	tui      bool
 )
 
 var simulateCmd = &cobra.Command{
@@ -21,13 +30,74 @@ var simulateCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		fmt.Println("🚗 Simulating parking system with:")
 		fmt.Printf("Gates  : %d\n", gates)
-		fmt.Printf("Floors : %d\n", floor)
-		fmt.Printf("Rows   : %d\n", rows)
-		fmt.Printf("Columns: %d\n", column)
+		if layout != "" {
+			fmt.Printf("Layout : %s\n", layout)
+		} else {
+			fmt.Printf("Floors : %d\n", floor)
+			fmt.Printf("Rows   : %d\n", rows)
+			fmt.Printf("Columns: %d\n", column)
+		}
 		fmt.Printf("Duration: %v\n", duration.String())
+		fmt.Printf("Strategy: %s\n", strategy)
+
+		// the gates spread along the first row of the lot, a layout has its own width instead of --column
+		parkOpt, width := parkingcli.WithRandomizeParkingSpots(floor, column, rows), column
+		if layout != "" {
+			spaces, err := parkingcli.LoadLayout(layout)
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+
+			parkOpt, width = parkingcli.WithLayoutFile(layout), 0
+			if len(spaces) > 0 && len(spaces[0]) > 0 {
+				width = len(spaces[0][0])
+			}
+		}
+
+		// every simulated gate is an entry and exit point, nearest-gate gives each vehicle the spot nearest to its gate
+		gateList := cli.SpreadGates(gates, width)
+		allocation, err := parkingpkg.StrategyForGates(strategy, gateList)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		var metrics *parkingmetrics.Metrics
+		if metricsAddr != "" {
+			registry := metricsx.NewRegistry()
+			metrics = parkingmetrics.New(registry)
+			defer serveMetrics(registry)()
+		}
+
+		bus, stopEvents, err := openEvents()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		defer stopEvents()
+
+		monitor, alertInterval, err := loadAlerts()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		var dashboard io.Writer
+		if tui {
+			dashboard = os.Stdout
+		}
 
 		// You can run your simulation logic here
-		err := cli.RunParkingSimulation(floor, column, rows, gates, duration)
+		err = cli.RunParkingSimulation(cli.SimulationConfig{
+			Gates:         gates,
+			Duration:      duration,
+			ParkOptions:   []parkingcli.ParkOption{parkOpt, parkingcli.WithSpotFormat(spotFormat), parkingcli.WithGates(gateList...), parkingcli.WithStrategy(allocation), parkingcli.WithEvents(bus)},
+			Dashboard:     dashboard,
+			Metrics:       metrics,
+			Alerts:        monitor,
+			AlertInterval: alertInterval,
+		})
 		if err != nil {
 			return
 		}
@@ -42,4 +112,11 @@ func init() {
 	simulateCmd.Flags().IntVar(&rows, "rows", 1000, "Number of column per floor")
 	simulateCmd.Flags().IntVar(&column, "column", 1000, "Number of columns per row")
 	simulateCmd.Flags().DurationVar(&duration, "duration", 15*time.Second, "Duration of simulation")
+	simulateCmd.Flags().StringVar(&layout, "layout", "", "Layout file to build the parking spots from instead of random seeding")
+	simulateCmd.Flags().StringVar(&strategy, "strategy", "nearest-gate", "Spot allocation strategy, the simulated gates only take nearest-gate, compare the others with bench:report")
+	simulateCmd.Flags().BoolVar(&tui, "tui", false, "Show a live occupancy dashboard per floor and vehicle type instead of logging every operation")
+	addSpotFormatFlags(simulateCmd)
+	addMetricsFlag(simulateCmd)
+	addEventsFlag(simulateCmd)
+	addAlertsFlag(simulateCmd)
 }
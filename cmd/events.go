@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	parkingpkg "github.com/mtfiqh/DoiT-parking-system/parking"
+	"github.com/spf13/cobra"
+	"io"
+	"log"
+	"os"
+)
+
+// eventsBuffer is how many events may wait to be written before new ones are dropped.
+const eventsBuffer = 4096
+
+var eventsPath string
+
+// addEventsFlag registers the flag of the event stream.
+func addEventsFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&eventsPath, "events", "", "File to append the parking events to as JSON lines, - for stdout, off when empty")
+}
+
+// openEvents returns the bus whose events are written to --events, nil when it's empty.
+// stop closes the bus and waits until the last events are written.
+func openEvents() (bus *parkingpkg.Bus, stop func(), err error) {
+	if eventsPath == "" {
+		return nil, func() {}, nil
+	}
+
+	var out io.WriteCloser = os.Stdout
+	if eventsPath != "-" {
+		out, err = os.OpenFile(eventsPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	bus = parkingpkg.NewBus()
+	subscription := bus.Subscribe(eventsBuffer)
+	written := make(chan struct{})
+	go func() {
+		defer close(written)
+
+		if err := parkingpkg.WriteJSONLines(out, subscription.C); err != nil {
+			log.Println("Error writing events:", err)
+		}
+	}()
+
+	return bus, func() {
+		bus.Close()
+		<-written
+		if dropped := subscription.Dropped(); dropped > 0 {
+			log.Printf("%d events dropped, the events file couldn't keep up", dropped)
+		}
+		if out != os.Stdout {
+			_ = out.Close()
+		}
+	}, nil
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/mtfiqh/DoiT-parking-system/cli"
+	"github.com/spf13/cobra"
+	"io"
+	"os"
+)
+
+var script string
+
+var interactiveCmd = &cobra.Command{
+	Use:   "cli:interactive",
+	Short: "Operate the parking lot by typing commands",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		park, closePark, err := newPark()
+		if err != nil {
+			return err
+		}
+		defer closePark()
+
+		var in io.Reader = os.Stdin
+		prompt := true
+		if script != "" {
+			f, err := os.Open(script)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			in = f
+			prompt = false
+		} else {
+			fmt.Println("🚗 Parking lot ready, type help for the list of commands")
+		}
+
+		return cli.RunInteractive(park, in, os.Stdout, prompt)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(interactiveCmd)
+
+	interactiveCmd.Flags().StringVar(&script, "script", "", "Replay commands from a file instead of stdin")
+	addParkFlags(interactiveCmd)
+}
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"context"
+	"github.com/mtfiqh/DoiT-parking-system/pkg/metricsx"
+	"github.com/spf13/cobra"
+	"log"
+	"net/http"
+	"time"
+)
+
+var metricsAddr string
+
+// addMetricsFlag registers the flag of the metrics endpoint.
+func addMetricsFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&metricsAddr, "metrics-addr", "", "Address to serve Prometheus metrics on at /metrics, e.g. :9090, off when empty")
+}
+
+// serveMetrics serves the registry at /metrics on --metrics-addr until stop is called.
+func serveMetrics(registry *metricsx.Registry) (stop func()) {
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", registry)
+	srv := &http.Server{Addr: metricsAddr, Handler: mux}
+
+	go func() {
+		log.Printf("Metrics listening on %s/metrics", metricsAddr)
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Println("Error serving metrics:", err)
+		}
+	}()
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(ctx)
+	}
+}
@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"github.com/mtfiqh/DoiT-parking-system/billing"
+	"github.com/mtfiqh/DoiT-parking-system/cli/parkingcli"
+	parkingpkg "github.com/mtfiqh/DoiT-parking-system/parking"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingmetrics"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingstore"
+	"github.com/mtfiqh/DoiT-parking-system/pkg/metricsx"
+	"github.com/spf13/cobra"
+	"io"
+)
+
+var (
+	dataDir       string
+	snapshotEvery int
+	tariffs       string
+	plateRegion   string
+	fallback      string
+	gateSpecs     []string
+	spotFormat    parkingentity.SpotFormat
+)
+
+// addParkFlags registers the flags used by newPark.
+func addParkFlags(cmd *cobra.Command) {
+	cmd.Flags().IntVar(&floor, "floor", 8, "Number of floors")
+	cmd.Flags().IntVar(&rows, "rows", 1000, "Number of column per floor")
+	cmd.Flags().IntVar(&column, "column", 1000, "Number of columns per row")
+	cmd.Flags().StringVar(&layout, "layout", "", "Layout file to build the parking spots from instead of random seeding")
+	cmd.Flags().StringVar(&dataDir, "data-dir", "", "Directory to persist the parking state in, the lot is recovered from it on start")
+	cmd.Flags().IntVar(&snapshotEvery, "snapshot-every", 10000, "Number of operations between snapshots when --data-dir is set")
+	cmd.Flags().StringVar(&tariffs, "tariffs", "", "JSON tariff file to charge every unpark with")
+	cmd.Flags().StringVar(&plateRegion, "plate-region", "any", "Region whose plate rule validates parked vehicles: any or id")
+	cmd.Flags().StringArrayVar(&gateSpecs, "gate", nil, "Gate as id:floor-row-col[:entry|exit|both], repeat for every gate, vehicles get the spot nearest to their gate")
+	cmd.Flags().StringVar(&strategy, "strategy", "", "Spot allocation strategy: fifo, lock-free, sharded, nearest, nearest-gate, lowest-floor, fill-floor or random (default nearest-gate with --gate, fifo without)")
+	cmd.Flags().StringVar(&fallback, "fallback", "", "Spot types each vehicle type may overflow into when its own are full, e.g. B-1:M-1,A-1;M-1:A-1")
+	addSpotFormatFlags(cmd)
+	addMetricsFlag(cmd)
+	addEventsFlag(cmd)
+	addAlertsFlag(cmd)
+}
+
+// addSpotFormatFlags registers the flags of the spot ID display format.
+func addSpotFormatFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&spotFormat.OneBased, "spot-one-based", false, "Display floors, rows and columns counted from 1")
+	cmd.Flags().IntVar(&spotFormat.Basements, "spot-basements", 0, "Number of lowest floors displayed as basements B1, B2, ...")
+	cmd.Flags().IntVar(&spotFormat.ColumnWidth, "spot-column-width", 0, "Zero pad displayed columns to this many digits")
+	cmd.Flags().StringVar(&spotFormat.Separator, "spot-separator", "-", "Separator between floor, row and column of displayed spot IDs")
+}
+
+// newPark builds the parking lot from the flags, closeFn stops the reservation worker, the alerts, the metrics endpoint
+// and the event stream, and releases the store when --data-dir is set.
+func newPark() (park parkingpkg.ParkingSystem, closeFn func() error, err error) {
+	opts := []parkingcli.ParkOption{parkingcli.WithRandomizeParkingSpots(floor, column, rows)}
+	if layout != "" {
+		opts = []parkingcli.ParkOption{parkingcli.WithLayoutFile(layout)}
+	}
+
+	plateRule, err := parkingentity.PlateRuleFor(plateRegion)
+	if err != nil {
+		return nil, nil, err
+	}
+	overflow, err := parkingentity.ParseFallback(fallback)
+	if err != nil {
+		return nil, nil, err
+	}
+	opts = append(opts, parkingcli.WithPlateRule(plateRule), parkingcli.WithSpotFormat(spotFormat), parkingcli.WithFallback(overflow))
+
+	gates := make([]parkingentity.Gate, 0, len(gateSpecs))
+	for _, spec := range gateSpecs {
+		gate, err := parkingentity.ParseGate(spec)
+		if err != nil {
+			return nil, nil, err
+		}
+		gates = append(gates, gate)
+	}
+	allocation, err := parkingpkg.StrategyForGates(strategy, gates)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(gates) > 0 {
+		opts = append(opts, parkingcli.WithGates(gates...))
+	}
+	opts = append(opts, parkingcli.WithStrategy(allocation))
+
+	if tariffs != "" {
+		cfg, err := billing.LoadConfig(tariffs)
+		if err != nil {
+			return nil, nil, err
+		}
+		opts = append(opts, parkingcli.WithBilling(billing.New(cfg)))
+	}
+
+	monitor, alertInterval, err := loadAlerts()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		registry *metricsx.Registry
+		metrics  *parkingmetrics.Metrics
+	)
+	if metricsAddr != "" {
+		registry = metricsx.NewRegistry()
+		metrics = parkingmetrics.New(registry)
+		opts = append(opts, parkingcli.WithMetrics(metrics))
+	}
+
+	bus, stopEvents, err := openEvents()
+	if err != nil {
+		return nil, nil, err
+	}
+	opts = append(opts, parkingcli.WithEvents(bus))
+
+	closeFn = func() error {
+		stopEvents()
+		return nil
+	}
+	if dataDir != "" {
+		store, err := parkingstore.OpenFileStore(dataDir)
+		if err != nil {
+			stopEvents()
+			return nil, nil, err
+		}
+
+		opts = append(opts, parkingcli.WithStore(store, snapshotEvery))
+		closeFn = func() error {
+			stopEvents()
+			return store.Close()
+		}
+	}
+
+	park, err = parkingcli.NewPark(opts...)
+	if err != nil {
+		_ = closeFn()
+		return nil, nil, err
+	}
+
+	stopMetrics := func() {}
+	if metrics != nil {
+		if reporter, ok := park.(parkingpkg.OccupancyReporter); ok {
+			metrics.Watch(reporter)
+		}
+		stopMetrics = serveMetrics(registry)
+	}
+
+	stopAlerts := func() {}
+	if reporter, ok := park.(parkingpkg.OccupancyReporter); ok && monitor != nil {
+		stopAlerts = monitor.Start(reporter, alertInterval)
+	}
+
+	closeStore := closeFn
+	closeFn = func() error {
+		stopAlerts()
+		stopMetrics()
+		if closer, ok := park.(io.Closer); ok {
+			_ = closer.Close()
+		}
+		return closeStore()
+	}
+
+	return park, closeFn, nil
+}
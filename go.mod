@@ -3,6 +3,7 @@ module github.com/mtfiqh/DoiT-parking-system
 go 1.24
 
 require (
+	github.com/mattn/go-sqlite3 v1.14.28
 	github.com/pkg/errors v0.9.1
 	github.com/spf13/cobra v1.9.1
 	golang.org/x/sync v0.14.0
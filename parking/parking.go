@@ -2,12 +2,148 @@ package parking
 
 import (
 	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
+	"slices"
+	"time"
 )
 
 // ParkingSystem interface defines the methods for parking operations.
+// Implementations normalize the plate numbers, so every spelling of a plate resolves to the same vehicle.
 type ParkingSystem interface {
-	Park(vehicleType parkingentity.VehicleType, vehicleNumber int) (*parkingentity.SpotID, error)
-	Unpark(spotID string, vehicleNumber int) error
+	Park(vehicleType parkingentity.VehicleType, vehicleNumber parkingentity.Plate) (*parkingentity.SpotID, error)
+	Unpark(spotID string, vehicleNumber parkingentity.Plate) (*parkingentity.Receipt, error)
 	AvailableSpot(vehicleType parkingentity.VehicleType) (int, []parkingentity.Spot)
-	SearchVehicle(vehicleNumber int) (*parkingentity.SpotID, error)
+	SearchVehicle(vehicleNumber parkingentity.Plate) (*parkingentity.SpotID, error)
+}
+
+// SpotFormatter is implemented by parking systems that display their spot IDs in a custom format,
+// Unpark then takes the spot ID in that format.
+type SpotFormatter interface {
+	SpotFormat() parkingentity.SpotFormat
+}
+
+// SpotFormatOf returns the display format of the parking system's spot IDs, the raw floor-row-col when it has none.
+func SpotFormatOf(park ParkingSystem) parkingentity.SpotFormat {
+	if formatter, ok := park.(SpotFormatter); ok {
+		return formatter.SpotFormat()
+	}
+
+	return parkingentity.SpotFormat{}
+}
+
+// VehicleLocator is implemented by parking systems that tell whether the vehicle is still at the spot SearchVehicle returns.
+type VehicleLocator interface {
+	// LocateVehicle returns the last spot of the vehicle and whether it is still parked there, read at once.
+	LocateVehicle(vehicleNumber parkingentity.Plate) (*parkingentity.VehicleSpot, error)
+}
+
+// LocateVehicleOf returns the last spot of the vehicle and whether it is still parked there.
+// Without a VehicleLocator the spot is searched and the last session read apart, so an unpark may fall in between,
+// the type is left out, and without a SessionHistory either the vehicle found is taken as still parked.
+func LocateVehicleOf(park ParkingSystem, vehicleNumber parkingentity.Plate) (*parkingentity.VehicleSpot, error) {
+	if locator, ok := park.(VehicleLocator); ok {
+		return locator.LocateVehicle(vehicleNumber)
+	}
+
+	spotID, err := park.SearchVehicle(vehicleNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	vehicleSpot := parkingentity.VehicleSpot{SpotID: *spotID, StillParked: true}
+	if history, ok := park.(SessionHistory); ok {
+		sessions, err := history.History(vehicleNumber)
+		if err != nil {
+			return nil, err
+		}
+
+		vehicleSpot.StillParked = len(sessions) > 0 && sessions[len(sessions)-1].Active()
+	}
+
+	return &vehicleSpot, nil
+}
+
+// SessionHistory is implemented by parking systems that keep every parking session.
+type SessionHistory interface {
+	// History returns every session of the vehicle, oldest first.
+	History(vehicleNumber parkingentity.Plate) ([]parkingentity.Session, error)
+	// SessionsBetween returns the sessions that were in the lot at any time in [from, to), oldest first.
+	SessionsBetween(from, to time.Time) ([]parkingentity.Session, error)
+}
+
+// Reserver is implemented by parking systems that hold spots for pre-booked arrivals.
+type Reserver interface {
+	// Reserve takes the next available spot of the vehicle type out of the free spots and holds it for the vehicle during window,
+	// the spot is freed again when the hold expires.
+	Reserve(vehicleType parkingentity.VehicleType, vehicleNumber parkingentity.Plate, window time.Duration) (*parkingentity.Reservation, error)
+	// ClaimReservation parks the arriving vehicle at its held spot.
+	ClaimReservation(vehicleNumber parkingentity.Plate) (*parkingentity.SpotID, error)
+	// CancelReservation frees the held spot.
+	CancelReservation(vehicleNumber parkingentity.Plate) error
+}
+
+// GateParker is implemented by parking systems that know their gates,
+// the gate is recorded on the session and may pick the spot, e.g. with NearestGate.
+type GateParker interface {
+	// Gates returns the gates of the lot.
+	Gates() []parkingentity.Gate
+	// ParkAt parks the vehicle entering at the gate.
+	ParkAt(gateID string, vehicleType parkingentity.VehicleType, vehicleNumber parkingentity.Plate) (*parkingentity.SpotID, error)
+	// UnparkAt unparks the vehicle leaving at the gate.
+	UnparkAt(gateID string, spotID string, vehicleNumber parkingentity.Plate) (*parkingentity.Receipt, error)
+}
+
+// LostTicketUnparker is implemented by parking systems that let a vehicle leave without its ticket,
+// the vehicle is found by its plate and the session is charged the lost ticket penalty.
+type LostTicketUnparker interface {
+	// UnparkLostTicket unparks the vehicle leaving at the gate, an empty gate ID when the lot has no gates.
+	UnparkLostTicket(gateID string, vehicleNumber parkingentity.Plate) (*parkingentity.Receipt, error)
+}
+
+// SpotAdmin is implemented by parking systems whose spots can be changed at runtime,
+// only free spots can be changed, occupied and reserved ones are refused with ErrSpotOccupied.
+type SpotAdmin interface {
+	// DisableSpot closes the spot, e.g. for maintenance, it becomes X-0.
+	DisableSpot(spotID string) error
+	// EnableSpot reopens a disabled spot for the spot type.
+	EnableSpot(spotID string, spotType parkingentity.VehicleType) error
+	// RetypeSpot changes the type of an enabled spot.
+	RetypeSpot(spotID string, spotType parkingentity.VehicleType) error
+}
+
+// OccupancyReporter is implemented by parking systems that can report how full every floor is, e.g. for a dashboard.
+type OccupancyReporter interface {
+	Occupancy() parkingentity.Occupancy
+}
+
+// SpotQuerier is implemented by parking systems that can filter and page their free spots without copying all of them.
+type SpotQuerier interface {
+	QuerySpots(query parkingentity.SpotQuery) (parkingentity.SpotPage, error)
+}
+
+// QuerySpotsOf returns the page of the free spots matching the query, filtering AvailableSpot when the parking system isn't a SpotQuerier.
+func QuerySpotsOf(park ParkingSystem, query parkingentity.SpotQuery) (parkingentity.SpotPage, error) {
+	if querier, ok := park.(SpotQuerier); ok {
+		return querier.QuerySpots(query)
+	}
+
+	_, spots := park.AvailableSpot(query.VehicleType)
+	return query.Page(slices.Values(spots))
+}
+
+// GateOf returns the gate of the ID, ErrUnknownGate when the parking system doesn't know it.
+func GateOf(park ParkingSystem, id string) (parkingentity.Gate, error) {
+	if parker, ok := park.(GateParker); ok {
+		for _, gate := range parker.Gates() {
+			if gate.ID == id {
+				return gate, nil
+			}
+		}
+	}
+
+	return parkingentity.Gate{}, parkingentity.ErrUnknownGate
+}
+
+// Biller charges a completed parking session.
+type Biller interface {
+	Charge(session parkingentity.Session) (parkingentity.Receipt, error)
 }
@@ -0,0 +1,611 @@
+package parking
+
+import (
+	"cmp"
+	"container/heap"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
+	"github.com/mtfiqh/DoiT-parking-system/pkg/queuex"
+	"iter"
+	"math/rand"
+	"slices"
+	"sort"
+	"sync"
+)
+
+// Allocator holds the free spots of one spot type and picks the spot each vehicle parks at.
+// Implementations must be safe for concurrent use.
+type Allocator interface {
+	// Allocate removes and returns the spot the next vehicle parks at, false when no spot is free.
+	Allocate() (parkingentity.Spot, bool)
+	// Release adds a freed spot.
+	Release(spot parkingentity.Spot)
+	// Remove takes the spot out of the free spots, false when it isn't free.
+	Remove(spot parkingentity.Spot) bool
+	// Spots returns the free spots in the order Allocate would take them, gate allocators take them in another order
+	// for each gate; allocators without one order, e.g. Random and ShardedByFloor, return them in any order.
+	Spots() []parkingentity.Spot
+}
+
+// SpotWalker is implemented by allocators that can walk their free spots in allocation order without copying them,
+// the allocator stays locked until the loop ends.
+type SpotWalker interface {
+	WalkSpots() iter.Seq[parkingentity.Spot]
+}
+
+// SpotsOf walks the free spots of the allocator in allocation order, over a copy from Spots when it isn't a SpotWalker.
+func SpotsOf(allocator Allocator) iter.Seq[parkingentity.Spot] {
+	if walker, ok := allocator.(SpotWalker); ok {
+		return walker.WalkSpots()
+	}
+	return slices.Values(allocator.Spots())
+}
+
+// Strategy builds an empty allocator, a parking lot builds one for every spot type.
+type Strategy func() Allocator
+
+// StrategyFor returns the strategy of a name: fifo, lock-free (fifo on a lock-free queue), sharded (fifo within each floor only),
+// nearest (to a gate at 0-0-0), lowest-floor, fill-floor or random.
+func StrategyFor(name string) (Strategy, error) {
+	switch name {
+	case "fifo":
+		return FIFO, nil
+	case "lock-free":
+		return FIFOOn(queuex.NewLockFree[parkingentity.Spot]), nil
+	case "sharded":
+		return ShardedByFloor, nil
+	case "nearest":
+		return NearestTo(parkingentity.Spot{}), nil
+	case "lowest-floor":
+		return LowestFloor, nil
+	case "fill-floor":
+		return FillFloor, nil
+	case "random":
+		return Random, nil
+	default:
+		return nil, parkingentity.ErrUnknownStrategy
+	}
+}
+
+// StrategyForGates returns the strategy of a name for a lot with the gates: nearest-gate with gates and fifo without them
+// when the name is empty, ErrStrategyIgnoresGates for any other strategy than nearest-gate with gates
+// and ErrStrategyNeedsGates for nearest-gate without them.
+func StrategyForGates(name string, gates []parkingentity.Gate) (Strategy, error) {
+	switch {
+	case name == "" && len(gates) > 0, name == "nearest-gate" && len(gates) > 0:
+		return NearestGate(gates), nil
+	case name == "nearest-gate":
+		return nil, parkingentity.ErrStrategyNeedsGates
+	case name == "":
+		return FIFO, nil
+	}
+
+	strategy, err := StrategyFor(name)
+	if err != nil {
+		return nil, err
+	}
+	if len(gates) > 0 {
+		return nil, parkingentity.ErrStrategyIgnoresGates
+	}
+	return strategy, nil
+}
+
+// FIFO allocates the spot that has been free the longest, a freed spot goes to the tail of the queue.
+func FIFO() Allocator {
+	return fifo{queue: queuex.NewQueue[parkingentity.Spot]()}
+}
+
+// FIFOOn is FIFO on the queues built by newQueue, e.g. queuex.NewLockFree.
+// parkingcli takes spots without holding its own lock, so its gates only contend on the queue while parking.
+func FIFOOn[Q queuex.Interface[parkingentity.Spot]](newQueue func() Q) Strategy {
+	return func() Allocator {
+		return fifo{queue: newQueue()}
+	}
+}
+
+// ShardedByFloor keeps a FIFO queue per floor of the lot, added as the spots of the floor are released,
+// the parks take turns between the queues and a vehicle gets the spot free the longest on the queue of its turn,
+// or of the next one with a free spot.
+// It isn't a drop-in for FIFO: only the spots of one floor are allocated in the order they were freed,
+// and Spots lists the floors one after the other rather than in allocation order.
+func ShardedByFloor() Allocator {
+	return sharded{queue: queuex.NewSharded(func(spot parkingentity.Spot) int { return spot.Floor })}
+}
+
+// sharded isn't a SpotWalker, its walk wouldn't be in allocation order.
+type sharded struct {
+	queue *queuex.Sharded[parkingentity.Spot]
+}
+
+func (a sharded) Allocate() (parkingentity.Spot, bool) {
+	return a.queue.Dequeue()
+}
+
+func (a sharded) Release(spot parkingentity.Spot) {
+	a.queue.Enqueue(spot)
+}
+
+func (a sharded) Remove(spot parkingentity.Spot) bool {
+	return a.queue.Remove(func(s parkingentity.Spot) bool { return s == spot })
+}
+
+func (a sharded) Spots() []parkingentity.Spot {
+	_, spots := a.queue.Snapshot()
+	return spots
+}
+
+type fifo struct {
+	queue queuex.Interface[parkingentity.Spot]
+}
+
+func (a fifo) Allocate() (parkingentity.Spot, bool) {
+	return a.queue.Dequeue()
+}
+
+func (a fifo) Release(spot parkingentity.Spot) {
+	a.queue.Enqueue(spot)
+}
+
+func (a fifo) Remove(spot parkingentity.Spot) bool {
+	return a.queue.Remove(func(s parkingentity.Spot) bool { return s == spot })
+}
+
+func (a fifo) Spots() []parkingentity.Spot {
+	_, spots := a.queue.Snapshot()
+	return spots
+}
+
+func (a fifo) WalkSpots() iter.Seq[parkingentity.Spot] {
+	return a.queue.Values()
+}
+
+// NearestTo allocates the free spot nearest to the gate: on the closest floor, then the fewest rows and columns away.
+func NearestTo(gate parkingentity.Spot) Strategy {
+	return func() Allocator {
+		return newOrdered(func(a, b parkingentity.Spot) bool {
+			return closer(gate, a, b)
+		})
+	}
+}
+
+// LowestFloor allocates a free spot on the lowest floor, spots of the same floor in FIFO order.
+func LowestFloor() Allocator {
+	return newOrdered(func(a, b parkingentity.Spot) bool {
+		return a.Floor < b.Floor
+	})
+}
+
+// ordered allocates the free spot that sorts first by less, ties in FIFO order.
+type ordered struct {
+	less  func(a, b parkingentity.Spot) bool
+	spots orderedHeap
+	seq   uint64
+	mutex sync.Mutex
+}
+
+type orderedSpot struct {
+	spot parkingentity.Spot
+	seq  uint64
+}
+
+func newOrdered(less func(a, b parkingentity.Spot) bool) *ordered {
+	a := &ordered{less: less}
+	a.spots.less = a.before
+	return a
+}
+
+func (a *ordered) before(x, y orderedSpot) bool {
+	if a.less(x.spot, y.spot) {
+		return true
+	}
+	if a.less(y.spot, x.spot) {
+		return false
+	}
+	return x.seq < y.seq
+}
+
+func (a *ordered) Allocate() (parkingentity.Spot, bool) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if a.spots.Len() == 0 {
+		return parkingentity.Spot{}, false
+	}
+
+	return heap.Pop(&a.spots).(orderedSpot).spot, true
+}
+
+func (a *ordered) Release(spot parkingentity.Spot) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.seq++
+	heap.Push(&a.spots, orderedSpot{spot: spot, seq: a.seq})
+}
+
+func (a *ordered) Remove(spot parkingentity.Spot) bool {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	for i, s := range a.spots.items {
+		if s.spot == spot {
+			heap.Remove(&a.spots, i)
+			return true
+		}
+	}
+
+	return false
+}
+
+func (a *ordered) Spots() []parkingentity.Spot {
+	a.mutex.Lock()
+	sorted := slices.Clone(a.spots.items)
+	a.mutex.Unlock()
+
+	sort.Slice(sorted, func(i, j int) bool { return a.before(sorted[i], sorted[j]) })
+
+	spots := make([]parkingentity.Spot, len(sorted))
+	for i, s := range sorted {
+		spots[i] = s.spot
+	}
+	return spots
+}
+
+// WalkSpots pops the spots off a copy of the heap as the loop goes, so a page of the first spots doesn't sort them all.
+// The allocator is only locked while the heap is copied.
+func (a *ordered) WalkSpots() iter.Seq[parkingentity.Spot] {
+	return func(yield func(parkingentity.Spot) bool) {
+		a.mutex.Lock()
+		walk := orderedHeap{items: slices.Clone(a.spots.items), less: a.before}
+		a.mutex.Unlock()
+
+		for walk.Len() > 0 {
+			if !yield(heap.Pop(&walk).(orderedSpot).spot) {
+				return
+			}
+		}
+	}
+}
+
+// orderedHeap implements heap.Interface for ordered.
+type orderedHeap struct {
+	items []orderedSpot
+	less  func(x, y orderedSpot) bool
+}
+
+func (h orderedHeap) Len() int           { return len(h.items) }
+func (h orderedHeap) Less(i, j int) bool { return h.less(h.items[i], h.items[j]) }
+func (h orderedHeap) Swap(i, j int)      { h.items[i], h.items[j] = h.items[j], h.items[i] }
+func (h *orderedHeap) Push(x any)        { h.items = append(h.items, x.(orderedSpot)) }
+func (h *orderedHeap) Pop() any {
+	last := h.items[len(h.items)-1]
+	h.items = h.items[:len(h.items)-1]
+	return last
+}
+
+// FillFloor keeps filling the floor with the fewest free spots, lowest floor first on ties, spots of a floor in FIFO order.
+// Vehicles gather on as few floors as possible, so the empty ones can be closed at night.
+func FillFloor() Allocator {
+	return &fillFloor{floors: make(map[int]*floorSpots)}
+}
+
+// fillFloor keeps the floors with free spots in a heap by their number of free spots, updated on every change.
+type fillFloor struct {
+	floors map[int]*floorSpots
+	order  floorHeap
+	mutex  sync.Mutex
+}
+
+// floorSpots are the free spots of one floor in FIFO order, index is its position in the heap.
+type floorSpots struct {
+	floor int
+	spots *queuex.Queue[parkingentity.Spot]
+	free  int
+	index int
+}
+
+func (a *fillFloor) Allocate() (parkingentity.Spot, bool) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if len(a.order) == 0 {
+		return parkingentity.Spot{}, false
+	}
+
+	floor := a.order[0]
+	spot, _ := floor.spots.Dequeue()
+	a.update(floor, -1)
+
+	return spot, true
+}
+
+func (a *fillFloor) Release(spot parkingentity.Spot) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	floor, ok := a.floors[spot.Floor]
+	if !ok {
+		floor = &floorSpots{floor: spot.Floor, spots: queuex.NewQueue[parkingentity.Spot]()}
+		a.floors[spot.Floor] = floor
+		heap.Push(&a.order, floor)
+	}
+
+	floor.spots.Enqueue(spot)
+	a.update(floor, 1)
+}
+
+func (a *fillFloor) Remove(spot parkingentity.Spot) bool {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	floor, ok := a.floors[spot.Floor]
+	if !ok || !floor.spots.Remove(func(s parkingentity.Spot) bool { return s == spot }) {
+		return false
+	}
+
+	a.update(floor, -1)
+	return true
+}
+
+// update moves the floor in the heap after its free spots changed by delta, dropping it once it has none.
+// It must be called while holding the lock.
+func (a *fillFloor) update(floor *floorSpots, delta int) {
+	floor.free += delta
+	if floor.free > 0 {
+		heap.Fix(&a.order, floor.index)
+		return
+	}
+
+	heap.Remove(&a.order, floor.index)
+	delete(a.floors, floor.floor)
+}
+
+func (a *fillFloor) Spots() []parkingentity.Spot {
+	return slices.Collect(a.WalkSpots())
+}
+
+func (a *fillFloor) WalkSpots() iter.Seq[parkingentity.Spot] {
+	return func(yield func(parkingentity.Spot) bool) {
+		a.mutex.Lock()
+		defer a.mutex.Unlock()
+
+		// the floor allocated from only loses spots, so it is emptied before the next one in the heap order
+		floors := slices.Clone(a.order)
+		slices.SortFunc(floors, compareFloors)
+
+		for _, floor := range floors {
+			for spot := range floor.spots.Values() {
+				if !yield(spot) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// compareFloors sorts the floor with fewer free spots first, then the lower one.
+func compareFloors(x, y *floorSpots) int {
+	return cmp.Or(cmp.Compare(x.free, y.free), cmp.Compare(x.floor, y.floor))
+}
+
+// floorHeap implements heap.Interface for fillFloor.
+type floorHeap []*floorSpots
+
+func (h floorHeap) Len() int           { return len(h) }
+func (h floorHeap) Less(i, j int) bool { return compareFloors(h[i], h[j]) < 0 }
+func (h floorHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index, h[j].index = i, j
+}
+func (h *floorHeap) Push(x any) {
+	floor := x.(*floorSpots)
+	floor.index = len(*h)
+	*h = append(*h, floor)
+}
+func (h *floorHeap) Pop() any {
+	old := *h
+	last := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return last
+}
+
+// Random allocates any free spot with the same chance.
+func Random() Allocator {
+	return &random{}
+}
+
+type random struct {
+	spots []parkingentity.Spot
+	mutex sync.Mutex
+}
+
+func (a *random) Allocate() (parkingentity.Spot, bool) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if len(a.spots) == 0 {
+		return parkingentity.Spot{}, false
+	}
+
+	i := rand.Intn(len(a.spots))
+	spot := a.spots[i]
+	a.spots[i] = a.spots[len(a.spots)-1]
+	a.spots = a.spots[:len(a.spots)-1]
+
+	return spot, true
+}
+
+func (a *random) Release(spot parkingentity.Spot) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.spots = append(a.spots, spot)
+}
+
+func (a *random) Remove(spot parkingentity.Spot) bool {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	i := slices.Index(a.spots, spot)
+	if i < 0 {
+		return false
+	}
+
+	a.spots[i] = a.spots[len(a.spots)-1]
+	a.spots = a.spots[:len(a.spots)-1]
+	return true
+}
+
+func (a *random) Spots() []parkingentity.Spot {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	return slices.Clone(a.spots)
+}
+
+func (a *random) WalkSpots() iter.Seq[parkingentity.Spot] {
+	return func(yield func(parkingentity.Spot) bool) {
+		a.mutex.Lock()
+		defer a.mutex.Unlock()
+
+		for _, spot := range a.spots {
+			if !yield(spot) {
+				return
+			}
+		}
+	}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// GateAllocator is an Allocator that picks the spot for the gate the vehicle enters at.
+type GateAllocator interface {
+	Allocator
+	AllocateAt(gate parkingentity.Gate) (parkingentity.Spot, bool)
+}
+
+// NearestGate splits the free spots between the entry gates, every spot belongs to the gate nearest to it,
+// and allocates the spot nearest to the entering gate, from the next nearest gates once its own spots run out.
+// Allocate without a gate allocates for the first entry gate.
+func NearestGate(gates []parkingentity.Gate) Strategy {
+	var entries []parkingentity.Gate
+	for _, gate := range gates {
+		if gate.Entry() {
+			entries = append(entries, gate)
+		}
+	}
+
+	// the gates of each gate's spots, nearest first
+	nearest := make(map[string][]int, len(entries))
+	for _, gate := range entries {
+		order := make([]int, len(entries))
+		for i := range order {
+			order[i] = i
+		}
+		sort.SliceStable(order, func(i, j int) bool {
+			return closer(gate.Spot, entries[order[i]].Spot, entries[order[j]].Spot)
+		})
+		nearest[gate.ID] = order
+	}
+
+	return func() Allocator {
+		a := &nearestGate{gates: entries, nearest: nearest}
+		for _, gate := range entries {
+			a.zones = append(a.zones, NearestTo(gate.Spot)())
+		}
+		if len(a.zones) == 0 {
+			a.zones = append(a.zones, FIFO())
+		}
+		return a
+	}
+}
+
+type nearestGate struct {
+	gates   []parkingentity.Gate
+	nearest map[string][]int
+	zones   []Allocator
+}
+
+func (a *nearestGate) Allocate() (parkingentity.Spot, bool) {
+	for _, zone := range a.zones {
+		if spot, ok := zone.Allocate(); ok {
+			return spot, true
+		}
+	}
+
+	return parkingentity.Spot{}, false
+}
+
+func (a *nearestGate) AllocateAt(gate parkingentity.Gate) (parkingentity.Spot, bool) {
+	order, ok := a.nearest[gate.ID]
+	if !ok {
+		return a.Allocate()
+	}
+
+	for _, i := range order {
+		if spot, ok := a.zones[i].Allocate(); ok {
+			return spot, true
+		}
+	}
+
+	return parkingentity.Spot{}, false
+}
+
+func (a *nearestGate) Release(spot parkingentity.Spot) {
+	zone := 0
+	for i, gate := range a.gates {
+		if closer(spot, gate.Spot, a.gates[zone].Spot) {
+			zone = i
+		}
+	}
+
+	a.zones[zone].Release(spot)
+}
+
+func (a *nearestGate) Remove(spot parkingentity.Spot) bool {
+	for _, zone := range a.zones {
+		if zone.Remove(spot) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Spots lists the zones of the gates one after the other, the order Allocate without a gate takes them in.
+// A gate allocates its own zone first, so AllocateAt takes them in another order for each gate.
+// Releasing the spots in this order keeps the order within every zone, which is the one that decides allocations.
+func (a *nearestGate) Spots() []parkingentity.Spot {
+	var spots []parkingentity.Spot
+	for _, zone := range a.zones {
+		spots = append(spots, zone.Spots()...)
+	}
+	return spots
+}
+
+// WalkSpots walks the zones in the order of Spots.
+func (a *nearestGate) WalkSpots() iter.Seq[parkingentity.Spot] {
+	return func(yield func(parkingentity.Spot) bool) {
+		for _, zone := range a.zones {
+			for spot := range SpotsOf(zone) {
+				if !yield(spot) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// closer reports whether a is nearer to from than b: on a closer floor, then fewer rows and columns away.
+func closer(from, a, b parkingentity.Spot) bool {
+	floorA, floorB := abs(a.Floor-from.Floor), abs(b.Floor-from.Floor)
+	if floorA != floorB {
+		return floorA < floorB
+	}
+	return abs(a.Row-from.Row)+abs(a.Col-from.Col) < abs(b.Row-from.Row)+abs(b.Col-from.Col)
+}
@@ -0,0 +1,309 @@
+package parking_test
+
+import (
+	parkingpkg "github.com/mtfiqh/DoiT-parking-system/parking"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
+	"reflect"
+	"slices"
+	"sort"
+	"sync"
+	"testing"
+)
+
+func TestAllocator(t *testing.T) {
+	// two floors of 2x2 spots released in floor, row, column order
+	var spots []parkingentity.Spot
+	for floor := 0; floor < 2; floor++ {
+		for row := 0; row < 2; row++ {
+			for col := 0; col < 2; col++ {
+				spots = append(spots, parkingentity.Spot{Floor: floor, Row: row, Col: col})
+			}
+		}
+	}
+
+	newAllocator := func(strategy parkingpkg.Strategy) parkingpkg.Allocator {
+		allocator := strategy()
+		for _, spot := range spots {
+			allocator.Release(spot)
+		}
+		return allocator
+	}
+
+	allocate := func(t *testing.T, allocator parkingpkg.Allocator, n int) []parkingentity.Spot {
+		t.Helper()
+
+		allocated := make([]parkingentity.Spot, 0, n)
+		for i := 0; i < n; i++ {
+			spot, ok := allocator.Allocate()
+			if !ok {
+				t.Fatalf("Expected a free spot at allocation %d", i+1)
+			}
+			allocated = append(allocated, spot)
+		}
+		return allocated
+	}
+
+	t.Run("fifo", func(t *testing.T) {
+		allocator := newAllocator(parkingpkg.FIFO)
+		first := allocate(t, allocator, 2)
+		allocator.Release(first[0])
+
+		expected := append(append([]parkingentity.Spot{}, spots[2:]...), first[0])
+		if got := allocator.Spots(); !reflect.DeepEqual(got, expected) {
+			t.Errorf("Expected the freed spot at the tail %v, got %v", expected, got)
+		}
+	})
+
+	t.Run("lock-free", func(t *testing.T) {
+		strategy, _ := parkingpkg.StrategyFor("lock-free")
+		allocator := newAllocator(strategy)
+		first := allocate(t, allocator, 2)
+		allocator.Release(first[0])
+
+		expected := append(append([]parkingentity.Spot{}, spots[2:]...), first[0])
+		if got := allocator.Spots(); !reflect.DeepEqual(got, expected) {
+			t.Errorf("Expected the freed spot at the tail %v, got %v", expected, got)
+		}
+	})
+
+	t.Run("sharded", func(t *testing.T) {
+		allocator := newAllocator(parkingpkg.ShardedByFloor)
+
+		// the floors are taken in turns, each floor in FIFO order
+		got := allocate(t, allocator, len(spots))
+		sort.SliceStable(got, func(i, j int) bool { return got[i].Floor < got[j].Floor })
+		if !reflect.DeepEqual(got, spots) {
+			t.Errorf("Expected the spots of each floor in order %v, got %v", spots, got)
+		}
+
+		// a freed spot goes to the tail of its floor, the free spots are listed floor by floor
+		allocator.Release(spots[0])
+		allocator.Release(spots[len(spots)-1])
+		allocator.Release(spots[1])
+		expected := []parkingentity.Spot{spots[0], spots[1], spots[len(spots)-1]}
+		if got := allocator.Spots(); !reflect.DeepEqual(got, expected) {
+			t.Errorf("Expected the free spots floor by floor %v, got %v", expected, got)
+		}
+
+		// it isn't walked in place, the walk wouldn't be in allocation order
+		if _, ok := allocator.(parkingpkg.SpotWalker); ok {
+			t.Error("Expected the sharded allocator not to be a SpotWalker")
+		}
+	})
+
+	t.Run("nearest", func(t *testing.T) {
+		gate := parkingentity.Spot{Floor: 1, Row: 1, Col: 1}
+		allocator := newAllocator(parkingpkg.NearestTo(gate))
+
+		got := allocate(t, allocator, 4)
+		if got[0] != gate || got[1].Floor != 1 || got[2].Floor != 1 || got[3] != (parkingentity.Spot{Floor: 1}) {
+			t.Errorf("Expected floor 1 spots nearest to %v first, got %v", gate, got)
+		}
+
+		allocator.Release(gate)
+		if spot, _ := allocator.Allocate(); spot != gate {
+			t.Errorf("Expected the freed spot at the gate, got %v", spot)
+		}
+	})
+
+	t.Run("lowest floor", func(t *testing.T) {
+		allocator := newAllocator(parkingpkg.LowestFloor)
+		allocate(t, allocator, 5)
+
+		freed := parkingentity.Spot{Floor: 0, Row: 1, Col: 1}
+		allocator.Release(freed)
+		if got := allocator.Spots(); got[0] != freed {
+			t.Errorf("Expected the freed floor 0 spot first, got %v", got)
+		}
+	})
+
+	t.Run("fill floor", func(t *testing.T) {
+		allocator := newAllocator(parkingpkg.FillFloor)
+		allocate(t, allocator, 4)
+
+		// floor 1 is empty, a spot freed on floor 0 is filled again before opening it
+		freed := parkingentity.Spot{Floor: 0, Row: 0, Col: 1}
+		allocator.Release(freed)
+		if spot, _ := allocator.Allocate(); spot != freed {
+			t.Errorf("Expected the freed floor 0 spot, got %v", spot)
+		}
+
+		if spot, _ := allocator.Allocate(); spot.Floor != 1 {
+			t.Errorf("Expected floor 1 once floor 0 is full, got %v", spot)
+		}
+	})
+
+	t.Run("random", func(t *testing.T) {
+		allocator := newAllocator(parkingpkg.Random)
+		got := allocate(t, allocator, len(spots))
+
+		sort.Slice(got, func(i, j int) bool { return parkingentity.SpotID(got[i]).ID() < parkingentity.SpotID(got[j]).ID() })
+		if !reflect.DeepEqual(got, spots) {
+			t.Errorf("Expected every spot once, got %v", got)
+		}
+		if _, ok := allocator.Allocate(); ok {
+			t.Error("Expected no free spot")
+		}
+	})
+
+	t.Run("nearest gate", func(t *testing.T) {
+		west := parkingentity.Gate{ID: "west", Spot: parkingentity.Spot{Floor: 0, Row: 0, Col: 0}}
+		east := parkingentity.Gate{ID: "east", Spot: parkingentity.Spot{Floor: 0, Row: 0, Col: 1}}
+		exit := parkingentity.Gate{ID: "exit", Spot: parkingentity.Spot{Floor: 1, Row: 1, Col: 1}, Kind: parkingentity.GateExit}
+		allocator := newAllocator(parkingpkg.NearestGate([]parkingentity.Gate{west, east, exit})).(parkingpkg.GateAllocator)
+
+		if spot, _ := allocator.AllocateAt(east); spot != east.Spot {
+			t.Errorf("Expected the spot at the east gate, got %v", spot)
+		}
+		if spot, _ := allocator.AllocateAt(west); spot != west.Spot {
+			t.Errorf("Expected the spot at the west gate, got %v", spot)
+		}
+
+		// the east spots run out, the nearest west one is next
+		var got []parkingentity.Spot
+		for i := 0; i < 4; i++ {
+			spot, _ := allocator.AllocateAt(east)
+			got = append(got, spot)
+		}
+		if got[0] != (parkingentity.Spot{Row: 1, Col: 1}) || got[2].Floor != 1 || got[3] != (parkingentity.Spot{Row: 1}) {
+			t.Errorf("Expected the spots of the east gate nearest first then the west ones, got %v", got)
+		}
+
+		if left := allocator.Spots(); len(left) != len(spots)-6 {
+			t.Errorf("Expected %d free spots, got %v", len(spots)-6, left)
+		}
+	})
+
+	t.Run("walk in allocation order", func(t *testing.T) {
+		west := parkingentity.Gate{ID: "west", Spot: parkingentity.Spot{Floor: 0, Row: 0, Col: 0}}
+		east := parkingentity.Gate{ID: "east", Spot: parkingentity.Spot{Floor: 1, Row: 1, Col: 1}}
+
+		for name, strategy := range map[string]parkingpkg.Strategy{
+			"fifo":         parkingpkg.FIFO,
+			"nearest":      parkingpkg.NearestTo(east.Spot),
+			"lowest-floor": parkingpkg.LowestFloor,
+			"fill-floor":   parkingpkg.FillFloor,
+			"nearest-gate": parkingpkg.NearestGate([]parkingentity.Gate{west, east}),
+		} {
+			allocator := newAllocator(strategy)
+			// one spot taken and freed again, so the order isn't the release order
+			spot, _ := allocator.Allocate()
+			allocator.Release(spot)
+
+			if _, ok := allocator.(parkingpkg.SpotWalker); !ok {
+				t.Errorf("%s: expected a SpotWalker", name)
+			}
+			walked := slices.Collect(parkingpkg.SpotsOf(allocator))
+			if spots := allocator.Spots(); !reflect.DeepEqual(walked, spots) {
+				t.Errorf("%s: expected the walk %v to match Spots %v", name, walked, spots)
+			}
+			if got := allocate(t, allocator, len(spots)); !reflect.DeepEqual(got, walked) {
+				t.Errorf("%s: expected to allocate in the walked order %v, got %v", name, walked, got)
+			}
+		}
+	})
+
+	t.Run("nearest gate restored from its spots", func(t *testing.T) {
+		west := parkingentity.Gate{ID: "west", Spot: parkingentity.Spot{Floor: 0, Row: 0, Col: 0}}
+		east := parkingentity.Gate{ID: "east", Spot: parkingentity.Spot{Floor: 1, Row: 1, Col: 1}}
+		strategy := parkingpkg.NearestGate([]parkingentity.Gate{west, east})
+
+		allocator := newAllocator(strategy).(parkingpkg.GateAllocator)
+		spot, _ := allocator.AllocateAt(east)
+		allocator.Release(spot)
+
+		// a store releases the spots in the order of Spots, every gate allocates the same ones after
+		restored := strategy().(parkingpkg.GateAllocator)
+		for _, spot := range allocator.Spots() {
+			restored.Release(spot)
+		}
+		for i := range spots {
+			gate := []parkingentity.Gate{west, east}[i%2]
+			want, _ := allocator.AllocateAt(gate)
+			if got, _ := restored.AllocateAt(gate); got != want {
+				t.Fatalf("Expected %v at the %s gate after restoring, got %v", want, gate.ID, got)
+			}
+		}
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		for _, name := range []string{"fifo", "lock-free", "sharded", "nearest", "lowest-floor", "fill-floor", "random"} {
+			strategy, _ := parkingpkg.StrategyFor(name)
+			allocator := newAllocator(strategy)
+
+			if !allocator.Remove(spots[3]) || allocator.Remove(spots[3]) {
+				t.Errorf("%s: expected %v to be removed once", name, spots[3])
+			}
+			for _, spot := range allocate(t, allocator, len(spots)-1) {
+				if spot == spots[3] {
+					t.Errorf("%s: expected the removed spot never to be allocated", name)
+				}
+			}
+		}
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		for _, name := range []string{"fifo", "lock-free", "sharded", "nearest", "lowest-floor", "fill-floor", "random"} {
+			strategy, err := parkingpkg.StrategyFor(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			allocator := newAllocator(strategy)
+
+			var wg sync.WaitGroup
+			for i := 0; i < 8; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+
+					for j := 0; j < 100; j++ {
+						if spot, ok := allocator.Allocate(); ok {
+							allocator.Release(spot)
+						}
+					}
+				}()
+			}
+			wg.Wait()
+
+			if got := allocator.Spots(); len(got) != len(spots) {
+				t.Errorf("%s: expected %d free spots, got %d", name, len(spots), len(got))
+			}
+		}
+	})
+
+	if _, err := parkingpkg.StrategyFor("closest"); err != parkingentity.ErrUnknownStrategy {
+		t.Errorf("Expected %v, got %v", parkingentity.ErrUnknownStrategy, err)
+	}
+
+	t.Run("strategy for gates", func(t *testing.T) {
+		gates := []parkingentity.Gate{{ID: "north", Spot: parkingentity.Spot{Row: 1}}}
+		cases := []struct {
+			name  string
+			gates []parkingentity.Gate
+			err   error
+		}{
+			{name: "", gates: gates},
+			{name: "", gates: nil},
+			{name: "nearest-gate", gates: gates},
+			{name: "fifo", gates: nil},
+			{name: "nearest-gate", gates: nil, err: parkingentity.ErrStrategyNeedsGates},
+			{name: "fifo", gates: gates, err: parkingentity.ErrStrategyIgnoresGates},
+			{name: "closest", gates: gates, err: parkingentity.ErrUnknownStrategy},
+		}
+		for _, c := range cases {
+			strategy, err := parkingpkg.StrategyForGates(c.name, c.gates)
+			if err != c.err {
+				t.Errorf("%q with %d gates: expected %v, got %v", c.name, len(c.gates), c.err, err)
+				continue
+			}
+			if err != nil {
+				continue
+			}
+
+			_, gated := strategy().(parkingpkg.GateAllocator)
+			if gated != (len(c.gates) > 0) {
+				t.Errorf("%q with %d gates: expected a gate allocator %v, got %v", c.name, len(c.gates), len(c.gates) > 0, gated)
+			}
+		}
+	})
+}
@@ -0,0 +1,143 @@
+package parking
+
+import (
+	"encoding/json"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// EventType is what happened in the lot.
+type EventType string
+
+const (
+	// VehicleParked is published when a vehicle parks, also when it claims its reservation.
+	VehicleParked EventType = "vehicle_parked"
+	// VehicleUnparked is published when a vehicle leaves.
+	VehicleUnparked EventType = "vehicle_unparked"
+	// LotFull is published when a vehicle is turned away because no spot it may park in is free.
+	LotFull EventType = "lot_full"
+	// SpotFreed is published when a spot becomes available: after an unpark, a released reservation or when it's enabled or retyped.
+	SpotFreed EventType = "spot_freed"
+	// SpotDisabled is published when a spot is closed.
+	SpotDisabled EventType = "spot_disabled"
+)
+
+// Event is one activity of the lot, VehicleType is the type of the vehicle for vehicle events and of the spot for spot events.
+type Event struct {
+	Type          EventType                 `json:"type"`
+	Time          time.Time                 `json:"time"`
+	VehicleNumber parkingentity.Plate       `json:"vehicle_number,omitempty"`
+	VehicleType   parkingentity.VehicleType `json:"vehicle_type"`
+	SpotID        *parkingentity.SpotID     `json:"spot_id,omitempty"`
+	Gate          string                    `json:"gate,omitempty"`
+}
+
+// Bus delivers the events of a lot to its subscribers, it's safe for concurrent use and a nil *Bus drops every event.
+//
+// Delivery never blocks the publisher, so a slow subscriber can't stall parking:
+// every subscription has a buffer, an event published while it's full is dropped for that subscription and counted in Dropped.
+// Subscribers get the events in the order they were published.
+type Bus struct {
+	subscriptions []*Subscription
+	closed        bool
+	mutex         *sync.RWMutex
+}
+
+// NewBus returns a bus without subscribers.
+func NewBus() *Bus {
+	return &Bus{mutex: new(sync.RWMutex)}
+}
+
+// Subscription receives the events published after it subscribed on C, until it unsubscribes or the bus is closed.
+type Subscription struct {
+	C <-chan Event
+
+	events  chan Event
+	dropped atomic.Uint64
+	bus     *Bus
+}
+
+// Subscribe returns a subscription that buffers up to buffer events.
+func (b *Bus) Subscribe(buffer int) *Subscription {
+	events := make(chan Event, buffer)
+	s := &Subscription{C: events, events: events, bus: b}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.closed {
+		close(events)
+		return s
+	}
+
+	b.subscriptions = append(b.subscriptions, s)
+	return s
+}
+
+// Publish sends the event to every subscription that has room for it.
+func (b *Bus) Publish(event Event) {
+	if b == nil {
+		return
+	}
+
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	for _, s := range b.subscriptions {
+		select {
+		case s.events <- event:
+		default:
+			s.dropped.Add(1)
+		}
+	}
+}
+
+// Close closes C of every subscription, events published afterwards are dropped.
+func (b *Bus) Close() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.closed {
+		return
+	}
+
+	b.closed = true
+	for _, s := range b.subscriptions {
+		close(s.events)
+	}
+	b.subscriptions = nil
+}
+
+// Dropped returns how many events didn't fit in the buffer.
+func (s *Subscription) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Unsubscribe stops the delivery and closes C.
+func (s *Subscription) Unsubscribe() {
+	s.bus.mutex.Lock()
+	defer s.bus.mutex.Unlock()
+
+	for i, other := range s.bus.subscriptions {
+		if other == s {
+			s.bus.subscriptions = append(s.bus.subscriptions[:i], s.bus.subscriptions[i+1:]...)
+			close(s.events)
+			return
+		}
+	}
+}
+
+// WriteJSONLines writes every event of the channel to w as one JSON object per line, until the channel is closed.
+func WriteJSONLines(w io.Writer, events <-chan Event) error {
+	encoder := json.NewEncoder(w)
+	for event := range events {
+		if err := encoder.Encode(event); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,53 @@
+package parking_test
+
+import (
+	"bytes"
+	parkingpkg "github.com/mtfiqh/DoiT-parking-system/parking"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
+	"testing"
+	"time"
+)
+
+func TestBus(t *testing.T) {
+	t0 := time.Date(2025, 1, 1, 8, 0, 0, 0, time.UTC)
+	bus := parkingpkg.NewBus()
+
+	slow := bus.Subscribe(1)
+	sink := bus.Subscribe(10)
+
+	spotID := parkingentity.SpotID{Floor: 1, Row: 2, Col: 3}
+	bus.Publish(parkingpkg.Event{Type: parkingpkg.VehicleParked, Time: t0, VehicleNumber: "B1234XYZ", VehicleType: parkingentity.M1, SpotID: &spotID, Gate: "north"})
+	bus.Publish(parkingpkg.Event{Type: parkingpkg.LotFull, Time: t0, VehicleNumber: "B5678XYZ", VehicleType: parkingentity.A1})
+
+	// the full buffer drops the event instead of blocking the publisher
+	if got := slow.Dropped(); got != 1 {
+		t.Errorf("Expected 1 dropped event, got %d", got)
+	}
+	if event := <-slow.C; event.Type != parkingpkg.VehicleParked {
+		t.Errorf("Expected the first event, got %+v", event)
+	}
+
+	slow.Unsubscribe()
+	if _, open := <-slow.C; open {
+		t.Error("Expected an unsubscribed channel to be closed")
+	}
+	bus.Publish(parkingpkg.Event{Type: parkingpkg.SpotFreed, Time: t0, VehicleType: parkingentity.M1, SpotID: &spotID})
+	bus.Close()
+	bus.Publish(parkingpkg.Event{Type: parkingpkg.SpotDisabled, Time: t0})
+
+	var out bytes.Buffer
+	if err := parkingpkg.WriteJSONLines(&out, sink.C); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"type":"vehicle_parked","time":"2025-01-01T08:00:00Z","vehicle_number":"B1234XYZ","vehicle_type":"M-1","spot_id":{"Floor":1,"Col":3,"Row":2},"gate":"north"}
+{"type":"lot_full","time":"2025-01-01T08:00:00Z","vehicle_number":"B5678XYZ","vehicle_type":"A-1"}
+{"type":"spot_freed","time":"2025-01-01T08:00:00Z","vehicle_type":"M-1","spot_id":{"Floor":1,"Col":3,"Row":2}}
+`
+	if out.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+
+	var nilBus *parkingpkg.Bus
+	nilBus.Publish(parkingpkg.Event{Type: parkingpkg.LotFull})
+}
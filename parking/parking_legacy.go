@@ -0,0 +1,35 @@
+package parking
+
+import (
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
+)
+
+// Legacy exposes a ParkingSystem with the old int vehicle number API.
+//
+// Deprecated: pass a parkingentity.Plate to the ParkingSystem, vehicle numbers map to plates with parkingentity.PlateFromNumber.
+type Legacy struct {
+	ParkingSystem ParkingSystem
+}
+
+// NewLegacy wraps the parking system with the old int vehicle number API.
+//
+// Deprecated: use the ParkingSystem directly.
+func NewLegacy(park ParkingSystem) *Legacy {
+	return &Legacy{ParkingSystem: park}
+}
+
+func (l *Legacy) Park(vehicleType parkingentity.VehicleType, vehicleNumber int) (*parkingentity.SpotID, error) {
+	return l.ParkingSystem.Park(vehicleType, parkingentity.PlateFromNumber(vehicleNumber))
+}
+
+func (l *Legacy) Unpark(spotID string, vehicleNumber int) (*parkingentity.Receipt, error) {
+	return l.ParkingSystem.Unpark(spotID, parkingentity.PlateFromNumber(vehicleNumber))
+}
+
+func (l *Legacy) AvailableSpot(vehicleType parkingentity.VehicleType) (int, []parkingentity.Spot) {
+	return l.ParkingSystem.AvailableSpot(vehicleType)
+}
+
+func (l *Legacy) SearchVehicle(vehicleNumber int) (*parkingentity.SpotID, error) {
+	return l.ParkingSystem.SearchVehicle(parkingentity.PlateFromNumber(vehicleNumber))
+}
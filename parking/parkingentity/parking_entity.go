@@ -1,8 +1,10 @@
 package parkingentity
 
 import (
+	"encoding/json"
 	"fmt"
-	"github.com/mtfiqh/DoiT-parking-system/pkg/queuex"
+	"strconv"
+	"strings"
 )
 
 type (
@@ -21,13 +23,6 @@ type (
 	}
 )
 
-// AvailableSpots holds the available parking spots for different vehicle types.
-type AvailableSpots struct {
-	B1 *queuex.Queue[Spot]
-	M1 *queuex.Queue[Spot]
-	A1 *queuex.Queue[Spot]
-}
-
 // SpotID represents a unique identifier for a parking spot with format: floor-row-col.
 type SpotID Spot
 
@@ -35,6 +30,11 @@ func (s SpotID) ID() string {
 	return fmt.Sprintf("%d-%d-%d", s.Floor, s.Row, s.Col)
 }
 
+// ParseSpotID parses a spot ID in the floor-row-col format of ID, e.g. "1-2-10".
+func ParseSpotID(id string) (SpotID, error) {
+	return SpotFormat{}.Parse(id)
+}
+
 // VehicleType represents the type of vehicle that can be parked in the parking lot.
 type VehicleType uint
 
@@ -48,3 +48,66 @@ const (
 	// X0 represents an inactive parking spot.
 	X0
 )
+
+// vehicleTypeCodes are the codes of the vehicle types used by the readme, layouts and the API.
+var vehicleTypeCodes = [...]string{M1: "M-1", B1: "B-1", A1: "A-1", X0: "X-0"}
+
+// ParseVehicleType parses the code of a vehicle type that can be parked: "B-1", "M-1" or "A-1".
+func ParseVehicleType(code string) (VehicleType, error) {
+	var vehicleType VehicleType
+	if err := vehicleType.UnmarshalText([]byte(code)); err != nil || vehicleType == X0 {
+		return 0, ErrInvalidVehicleType
+	}
+
+	return vehicleType, nil
+}
+
+func (t VehicleType) String() string {
+	if int(t) < len(vehicleTypeCodes) {
+		return vehicleTypeCodes[t]
+	}
+
+	return "VehicleType(" + strconv.Itoa(int(t)) + ")"
+}
+
+func (t VehicleType) MarshalText() ([]byte, error) {
+	if int(t) >= len(vehicleTypeCodes) {
+		return nil, ErrInvalidVehicleType
+	}
+
+	return []byte(vehicleTypeCodes[t]), nil
+}
+
+// UnmarshalText parses a vehicle type code, "X-0" included.
+func (t *VehicleType) UnmarshalText(text []byte) error {
+	code := strings.ToUpper(strings.TrimSpace(string(text)))
+	for vehicleType, c := range vehicleTypeCodes {
+		if c == code {
+			*t = VehicleType(vehicleType)
+			return nil
+		}
+	}
+
+	return ErrInvalidVehicleType
+}
+
+// UnmarshalJSON accepts a code or, for data written before vehicle types were marshalled as codes, a number.
+func (t *VehicleType) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] != '"' {
+		var n uint
+		if err := json.Unmarshal(data, &n); err != nil {
+			return err
+		}
+		if int(n) >= len(vehicleTypeCodes) {
+			return ErrInvalidVehicleType
+		}
+		*t = VehicleType(n)
+		return nil
+	}
+
+	var code string
+	if err := json.Unmarshal(data, &code); err != nil {
+		return err
+	}
+	return t.UnmarshalText([]byte(code))
+}
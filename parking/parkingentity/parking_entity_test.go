@@ -0,0 +1,78 @@
+package parkingentity_test
+
+import (
+	"encoding/json"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
+	"testing"
+)
+
+func TestParseSpotID(t *testing.T) {
+	spotID, err := parkingentity.ParseSpotID("1-2-10")
+	if err != nil || spotID != (parkingentity.SpotID{Floor: 1, Row: 2, Col: 10}) {
+		t.Errorf("Expected spot 1-2-10, got %+v, %v", spotID, err)
+	}
+	if spotID.ID() != "1-2-10" {
+		t.Errorf("Expected the ID to round trip, got %s", spotID.ID())
+	}
+
+	for _, id := range []string{"", "1-2", "1-2-3-4", "1-x-3", "-1-2-3", "1-2-+3", "1 -2-3"} {
+		if _, err := parkingentity.ParseSpotID(id); err != parkingentity.ErrMalformedSpotID {
+			t.Errorf("ParseSpotID(%q): expected %v, got %v", id, parkingentity.ErrMalformedSpotID, err)
+		}
+	}
+}
+
+func TestVehicleTypeText(t *testing.T) {
+	for _, vehicleType := range []parkingentity.VehicleType{parkingentity.M1, parkingentity.B1, parkingentity.A1} {
+		parsed, err := parkingentity.ParseVehicleType(vehicleType.String())
+		if err != nil || parsed != vehicleType {
+			t.Errorf("Expected %s to round trip, got %v, %v", vehicleType, parsed, err)
+		}
+	}
+
+	if _, err := parkingentity.ParseVehicleType("X-0"); err != parkingentity.ErrInvalidVehicleType {
+		t.Errorf("Expected X-0 not to be parkable, got %v", err)
+	}
+	if s := parkingentity.VehicleType(9).String(); s != "VehicleType(9)" {
+		t.Errorf("Expected VehicleType(9), got %s", s)
+	}
+
+	data, err := json.Marshal(map[parkingentity.VehicleType]parkingentity.VehicleType{parkingentity.A1: parkingentity.X0})
+	if err != nil || string(data) != `{"A-1":"X-0"}` {
+		t.Errorf(`Expected {"A-1":"X-0"}, got %s, %v`, data, err)
+	}
+
+	var v struct {
+		Code   parkingentity.VehicleType `json:"code"`
+		Number parkingentity.VehicleType `json:"number"`
+	}
+	if err := json.Unmarshal([]byte(`{"code":"b-1","number":2}`), &v); err != nil {
+		t.Fatal(err)
+	}
+	if v.Code != parkingentity.B1 || v.Number != parkingentity.A1 {
+		t.Errorf("Expected B-1 and A-1, got %s and %s", v.Code, v.Number)
+	}
+	if err := json.Unmarshal([]byte(`{"code":"Z-9"}`), &v); err == nil {
+		t.Error("Expected an error for Z-9")
+	}
+}
+
+func TestParseGate(t *testing.T) {
+	gate, err := parkingentity.ParseGate("north:1-0-12:entry")
+	if err != nil || gate != (parkingentity.Gate{ID: "north", Spot: parkingentity.Spot{Floor: 1, Col: 12}, Kind: parkingentity.GateEntry}) {
+		t.Errorf("Expected the north entry gate at 1-0-12, got %+v, %v", gate, err)
+	}
+	if !gate.Entry() || gate.Exit() {
+		t.Errorf("Expected an entry only gate, got %v", gate.Kind)
+	}
+
+	if gate, err := parkingentity.ParseGate("south:0-0-0"); err != nil || gate.Kind != parkingentity.GateBoth {
+		t.Errorf("Expected a gate for both directions, got %+v, %v", gate, err)
+	}
+
+	for _, s := range []string{"", "north", ":0-0-0", "north:0-0", "north:0-0-0:side", "north:0-0-0:exit:x"} {
+		if _, err := parkingentity.ParseGate(s); err != parkingentity.ErrInvalidGate {
+			t.Errorf("ParseGate(%q): expected %v, got %v", s, parkingentity.ErrInvalidGate, err)
+		}
+	}
+}
@@ -7,4 +7,25 @@ var (
 	ErrInvalidVehicleType   = errors.New("invalid vehicle type")
 	ErrSpotNotFound         = errors.New("spot not found")
 	ErrVehicleNotFound      = errors.New("vehicle not found")
+	ErrVehicleNotAtSpot     = errors.New("vehicle is not parked at this spot")
+	ErrMalformedSpotID      = errors.New("malformed spot id, expected floor-row-col")
+	ErrSpotOutOfRange       = errors.New("spot is outside of the parking lot")
+	ErrInvalidPlate         = errors.New("invalid plate number")
+	ErrUnknownPlateRegion   = errors.New("unknown plate region")
+	ErrVehicleReserved      = errors.New("vehicle already has a reservation")
+	ErrReservationNotFound  = errors.New("reservation not found")
+	ErrReservationExpired   = errors.New("reservation has expired")
+	ErrInvalidWindow        = errors.New("reservation window must be positive")
+	ErrInvalidFallback      = errors.New("invalid fallback, expected e.g. B-1:M-1,A-1;M-1:A-1")
+	ErrInvalidGate          = errors.New("invalid gate, expected id:floor-row-col[:entry|exit|both]")
+	ErrUnknownGate          = errors.New("unknown gate")
+	ErrGateDirection        = errors.New("gate does not allow this direction")
+	ErrSpotOccupied         = errors.New("spot is occupied or reserved")
+	ErrSpotDisabled         = errors.New("spot is disabled")
+	ErrSpotEnabled          = errors.New("spot is already enabled")
+	ErrUnknownStrategy      = errors.New("unknown allocation strategy, expected fifo, lock-free, sharded, nearest, lowest-floor, fill-floor or random")
+	ErrStrategyIgnoresGates = errors.New("allocation strategy ignores the gates, use nearest-gate or no strategy with gates")
+	ErrStrategyNeedsGates   = errors.New("nearest-gate allocation strategy needs gates")
+	ErrInvalidSpotQuery     = errors.New("invalid spot query, expected a limit and distance of 0 or more and rows as from-to")
+	ErrInvalidCursor        = errors.New("invalid cursor")
 )
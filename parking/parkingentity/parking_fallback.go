@@ -0,0 +1,54 @@
+package parkingentity
+
+import (
+	"slices"
+	"strings"
+)
+
+// Fallback lists, per vehicle type, the spot types it may overflow into, in order, when its own spots are full.
+// The spot keeps its own type, unparking returns it to the queue of that type.
+type Fallback map[VehicleType][]VehicleType
+
+// FallbackBySize lets bicycles use motorcycle then automobile spots and motorcycles use automobile spots.
+var FallbackBySize = Fallback{
+	B1: {M1, A1},
+	M1: {A1},
+}
+
+// ParseFallback parses a fallback like "B-1:M-1,A-1;M-1:A-1", the empty string is no fallback.
+func ParseFallback(s string) (Fallback, error) {
+	fallback := make(Fallback)
+	if strings.TrimSpace(s) == "" {
+		return fallback, nil
+	}
+
+	for _, rule := range strings.Split(s, ";") {
+		from, to, ok := strings.Cut(rule, ":")
+		if !ok {
+			return nil, ErrInvalidFallback
+		}
+
+		vehicleType, err := ParseVehicleType(strings.TrimSpace(from))
+		if err != nil {
+			return nil, ErrInvalidFallback
+		}
+		if _, exists := fallback[vehicleType]; exists {
+			return nil, ErrInvalidFallback
+		}
+
+		for _, code := range strings.Split(to, ",") {
+			spotType, err := ParseVehicleType(strings.TrimSpace(code))
+			if err != nil || spotType == vehicleType || slices.Contains(fallback[vehicleType], spotType) {
+				return nil, ErrInvalidFallback
+			}
+			fallback[vehicleType] = append(fallback[vehicleType], spotType)
+		}
+	}
+
+	return fallback, nil
+}
+
+// SpotTypes returns the spot types a vehicle type may park in, its own first.
+func (f Fallback) SpotTypes(vehicleType VehicleType) []VehicleType {
+	return append([]VehicleType{vehicleType}, f[vehicleType]...)
+}
@@ -0,0 +1,28 @@
+package parkingentity_test
+
+import (
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
+	"reflect"
+	"testing"
+)
+
+func TestParseFallback(t *testing.T) {
+	fallback, err := parkingentity.ParseFallback(" B-1 : M-1, A-1 ; M-1:A-1")
+	if err != nil || !reflect.DeepEqual(fallback, parkingentity.FallbackBySize) {
+		t.Errorf("Expected %v, got %v, %v", parkingentity.FallbackBySize, fallback, err)
+	}
+
+	if fallback, err := parkingentity.ParseFallback(""); err != nil || len(fallback) != 0 {
+		t.Errorf("Expected no fallback, got %v, %v", fallback, err)
+	}
+
+	for _, s := range []string{"B-1", "B-1:X-0", "B-1:B-1", "B-1:A-1,A-1", "B-1:A-1;B-1:M-1", "Z-9:A-1"} {
+		if _, err := parkingentity.ParseFallback(s); err != parkingentity.ErrInvalidFallback {
+			t.Errorf("ParseFallback(%q): expected %v, got %v", s, parkingentity.ErrInvalidFallback, err)
+		}
+	}
+
+	if spotTypes := parkingentity.FallbackBySize.SpotTypes(parkingentity.B1); !reflect.DeepEqual(spotTypes, []parkingentity.VehicleType{parkingentity.B1, parkingentity.M1, parkingentity.A1}) {
+		t.Errorf("Expected B-1, M-1, A-1, got %v", spotTypes)
+	}
+}
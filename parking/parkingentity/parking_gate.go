@@ -0,0 +1,68 @@
+package parkingentity
+
+import (
+	"slices"
+	"strings"
+)
+
+// GateKind is the direction vehicles may pass a gate in.
+type GateKind uint
+
+const (
+	// GateBoth lets vehicles enter and exit.
+	GateBoth GateKind = iota
+	// GateEntry only lets vehicles enter.
+	GateEntry
+	// GateExit only lets vehicles exit.
+	GateExit
+)
+
+var gateKindNames = [...]string{GateBoth: "both", GateEntry: "entry", GateExit: "exit"}
+
+func (k GateKind) String() string {
+	if int(k) < len(gateKindNames) {
+		return gateKindNames[k]
+	}
+	return "unknown"
+}
+
+// Gate is an entry or exit point of the lot, Spot is where it is.
+type Gate struct {
+	ID string
+	Spot
+	Kind GateKind
+}
+
+// Entry reports whether vehicles may enter at the gate.
+func (g Gate) Entry() bool {
+	return g.Kind != GateExit
+}
+
+// Exit reports whether vehicles may exit at the gate.
+func (g Gate) Exit() bool {
+	return g.Kind != GateEntry
+}
+
+// ParseGate parses a gate written as id:floor-row-col[:kind], e.g. "north:0-0-12:entry", kind defaults to both.
+func ParseGate(s string) (Gate, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) < 2 || len(parts) > 3 || parts[0] == "" {
+		return Gate{}, ErrInvalidGate
+	}
+
+	spotID, err := ParseSpotID(parts[1])
+	if err != nil {
+		return Gate{}, ErrInvalidGate
+	}
+
+	gate := Gate{ID: parts[0], Spot: Spot(spotID)}
+	if len(parts) == 3 {
+		kind := slices.Index(gateKindNames[:], strings.ToLower(parts[2]))
+		if kind < 0 {
+			return Gate{}, ErrInvalidGate
+		}
+		gate.Kind = GateKind(kind)
+	}
+
+	return gate, nil
+}
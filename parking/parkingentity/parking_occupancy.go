@@ -0,0 +1,54 @@
+package parkingentity
+
+// SpotCount counts spots by state, free spots are the ones neither occupied nor held.
+type SpotCount struct {
+	Total    int `json:"total"`
+	Occupied int `json:"occupied"`
+	Held     int `json:"held"`
+}
+
+// Free returns the spots neither occupied nor held.
+func (c SpotCount) Free() int {
+	return c.Total - c.Occupied - c.Held
+}
+
+// Add returns the sum of both counts.
+func (c SpotCount) Add(other SpotCount) SpotCount {
+	return SpotCount{Total: c.Total + other.Total, Occupied: c.Occupied + other.Occupied, Held: c.Held + other.Held}
+}
+
+// FloorOccupancy is how full one floor of the lot is.
+type FloorOccupancy struct {
+	Floor int `json:"floor"`
+	// Types counts the spots laid out for every vehicle type, disabled spots are counted under X-0.
+	Types map[VehicleType]SpotCount `json:"types"`
+	// Rows counts the enabled spots of every row.
+	Rows []SpotCount `json:"rows"`
+}
+
+// Enabled returns the count of every spot of the floor that isn't disabled.
+func (f FloorOccupancy) Enabled() SpotCount {
+	var count SpotCount
+	for spotType, c := range f.Types {
+		if spotType != X0 {
+			count = count.Add(c)
+		}
+	}
+	return count
+}
+
+// Occupancy is a point in time view of how full every floor of the lot is.
+type Occupancy struct {
+	Floors []FloorOccupancy `json:"floors"`
+}
+
+// Types sums the spots of every vehicle type over the floors.
+func (o Occupancy) Types() map[VehicleType]SpotCount {
+	types := make(map[VehicleType]SpotCount)
+	for _, floor := range o.Floors {
+		for spotType, count := range floor.Types {
+			types[spotType] = types[spotType].Add(count)
+		}
+	}
+	return types
+}
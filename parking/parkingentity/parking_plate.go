@@ -0,0 +1,103 @@
+package parkingentity
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Plate is a vehicle plate number normalized to upper case without spaces, dashes or dots,
+// so "b 1234-xyz" read by an ANPR camera and "B 1234 XYZ" typed at the gate are the same vehicle.
+type Plate string
+
+// NormalizePlate normalizes a raw plate number without validating it.
+func NormalizePlate(raw string) Plate {
+	return Plate(strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\t', '-', '.':
+			return -1
+		}
+		return r
+	}, strings.ToUpper(raw)))
+}
+
+// PlateFromNumber converts a vehicle number of the old int API to a plate, normalized like any other plate,
+// so a negative number loses its sign.
+func PlateFromNumber(vehicleNumber int) Plate {
+	return NormalizePlate(strconv.Itoa(vehicleNumber))
+}
+
+// ParsePlate normalizes a raw plate number and validates it with the rule.
+func ParsePlate(raw string, rule PlateRule) (Plate, error) {
+	plate := NormalizePlate(raw)
+	if !rule.Valid(plate) {
+		return "", ErrInvalidPlate
+	}
+
+	return plate, nil
+}
+
+// Normalize returns the normalized plate.
+func (p Plate) Normalize() Plate {
+	return NormalizePlate(string(p))
+}
+
+func (p Plate) String() string {
+	return string(p)
+}
+
+// UnmarshalJSON accepts a string or, for data written by the old int API, a number.
+func (p *Plate) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] != '"' && string(data) != "null" {
+		var vehicleNumber int
+		if err := json.Unmarshal(data, &vehicleNumber); err != nil {
+			return err
+		}
+		*p = PlateFromNumber(vehicleNumber)
+		return nil
+	}
+
+	var raw string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*p = NormalizePlate(raw)
+	return nil
+}
+
+// PlateRule validates normalized plates of a region.
+type PlateRule struct {
+	Region  string
+	Pattern *regexp.Regexp
+}
+
+var (
+	// PlateRuleAny accepts up to 12 letters and digits, numeric plates of the old int API included.
+	PlateRuleAny = PlateRule{Region: "any", Pattern: regexp.MustCompile(`^[A-Z0-9]{1,12}$`)}
+	// PlateRuleID accepts Indonesian plates: region code, number and an optional suffix, e.g. B 1234 XYZ.
+	PlateRuleID = PlateRule{Region: "id", Pattern: regexp.MustCompile(`^[A-Z]{1,2}[0-9]{1,4}[A-Z]{0,3}$`)}
+)
+
+// PlateRuleFor returns the rule of a region, e.g. "id", or "any".
+func PlateRuleFor(region string) (PlateRule, error) {
+	for _, rule := range []PlateRule{PlateRuleAny, PlateRuleID} {
+		if strings.EqualFold(rule.Region, region) {
+			return rule, nil
+		}
+	}
+
+	return PlateRule{}, ErrUnknownPlateRegion
+}
+
+// Valid reports whether the normalized plate matches the rule, the zero rule accepts any non-empty plate.
+func (r PlateRule) Valid(plate Plate) bool {
+	if plate == "" {
+		return false
+	}
+	if r.Pattern == nil {
+		return true
+	}
+
+	return r.Pattern.MatchString(string(plate))
+}
@@ -0,0 +1,52 @@
+package parkingentity_test
+
+import (
+	"encoding/json"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
+	"testing"
+)
+
+func TestParsePlate(t *testing.T) {
+	cases := []struct {
+		raw      string
+		rule     parkingentity.PlateRule
+		expected parkingentity.Plate
+		err      error
+	}{
+		{raw: "B 1234 XYZ", rule: parkingentity.PlateRuleID, expected: "B1234XYZ"},
+		{raw: "b-1234-xyz", rule: parkingentity.PlateRuleID, expected: "B1234XYZ"},
+		{raw: " d 1.2 ", rule: parkingentity.PlateRuleID, expected: "D12"},
+		{raw: "1234", rule: parkingentity.PlateRuleAny, expected: "1234"},
+		{raw: "1234", rule: parkingentity.PlateRuleID, err: parkingentity.ErrInvalidPlate},
+		{raw: "B 12345 XYZ", rule: parkingentity.PlateRuleID, err: parkingentity.ErrInvalidPlate},
+		{raw: " - ", rule: parkingentity.PlateRuleAny, err: parkingentity.ErrInvalidPlate},
+		{raw: "B#1234", rule: parkingentity.PlateRuleAny, err: parkingentity.ErrInvalidPlate},
+		{raw: "B#1234", rule: parkingentity.PlateRule{}, expected: "B#1234"},
+	}
+
+	for _, tc := range cases {
+		plate, err := parkingentity.ParsePlate(tc.raw, tc.rule)
+		if err != tc.err || plate != tc.expected {
+			t.Errorf("ParsePlate(%q, %s): expected %q, %v, got %q, %v", tc.raw, tc.rule.Region, tc.expected, tc.err, plate, err)
+		}
+	}
+}
+
+func TestPlateUnmarshalJSON(t *testing.T) {
+	var v struct {
+		Plate  parkingentity.Plate `json:"plate"`
+		Number parkingentity.Plate `json:"number"`
+		// Negative is normalized without its sign, the way a plate typed as "-5" is
+		Negative parkingentity.Plate `json:"negative"`
+	}
+	if err := json.Unmarshal([]byte(`{"plate":"b 1234 xyz","number":1234,"negative":-5}`), &v); err != nil {
+		t.Fatal(err)
+	}
+
+	if v.Plate != "B1234XYZ" || v.Number != "1234" || v.Negative != "5" {
+		t.Errorf("Expected B1234XYZ, 1234 and 5, got %q, %q and %q", v.Plate, v.Number, v.Negative)
+	}
+	if plate := parkingentity.PlateFromNumber(-5); plate != parkingentity.NormalizePlate("-5") {
+		t.Errorf("Expected PlateFromNumber(-5) to be normalized like \"-5\", got %q", plate)
+	}
+}
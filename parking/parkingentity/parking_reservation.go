@@ -0,0 +1,17 @@
+package parkingentity
+
+import "time"
+
+// Reservation holds a spot for a pre-booked vehicle until it arrives or the hold expires.
+type Reservation struct {
+	VehicleNumber Plate       `json:"vehicle_number"`
+	VehicleType   VehicleType `json:"vehicle_type"`
+	SpotID        SpotID      `json:"spot_id"`
+	ReservedAt    time.Time   `json:"reserved_at"`
+	ExpiresAt     time.Time   `json:"expires_at"`
+}
+
+// Expired reports whether the hold has ended at now.
+func (r Reservation) Expired(now time.Time) bool {
+	return !now.Before(r.ExpiresAt)
+}
@@ -0,0 +1,38 @@
+package parkingentity
+
+import "time"
+
+// Session is a single visit of a vehicle, from park to unpark.
+type Session struct {
+	VehicleNumber Plate       `json:"vehicle_number"`
+	VehicleType   VehicleType `json:"vehicle_type"`
+	SpotID        SpotID      `json:"spot_id"`
+	// Gate is the gate the vehicle entered from, empty when unknown.
+	Gate string `json:"gate,omitempty"`
+	// ExitGate is the gate the vehicle left from, empty while parked or when unknown.
+	ExitGate string    `json:"exit_gate,omitempty"`
+	EntryAt  time.Time `json:"entry_at"`
+	// ExitAt is zero while the vehicle is still parked.
+	ExitAt time.Time `json:"exit_at"`
+	// LostTicket is set when the vehicle left without its ticket, the lost ticket penalty is charged.
+	LostTicket bool `json:"lost_ticket,omitempty"`
+}
+
+// Active reports whether the vehicle is still parked.
+func (s Session) Active() bool {
+	return s.ExitAt.IsZero()
+}
+
+// Overlaps reports whether the session was in the lot at any time in [from, to).
+func (s Session) Overlaps(from, to time.Time) bool {
+	return s.EntryAt.Before(to) && (s.Active() || !s.ExitAt.Before(from))
+}
+
+// Receipt is the charge of a completed session.
+type Receipt struct {
+	Session  Session       `json:"session"`
+	Duration time.Duration `json:"duration"`
+	// Amount is in the smallest unit of Currency, zero when no tariff applies.
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency,omitempty"`
+}
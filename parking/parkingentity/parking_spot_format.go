@@ -0,0 +1,105 @@
+package parkingentity
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SpotFormat renders spot IDs for display and parses them back, the zero value is the raw floor-row-col of SpotID.ID.
+type SpotFormat struct {
+	// OneBased counts floors, rows and columns from 1 like people do.
+	OneBased bool
+	// Basements is the number of lowest floors shown as B1 (nearest to the ground) to B<n>, the next floor is the ground floor.
+	Basements int
+	// ColumnWidth zero pads the column to this many digits.
+	ColumnWidth int
+	// Separator goes between floor, row and column, defaults to "-".
+	Separator string
+}
+
+// Format renders the spot ID, e.g. "B1-3-007" with basements, one based numbers and a column width of 3.
+func (f SpotFormat) Format(spotID SpotID) string {
+	var floor string
+	if spotID.Floor < f.Basements {
+		floor = "B" + strconv.Itoa(f.Basements-spotID.Floor)
+	} else {
+		floor = strconv.Itoa(spotID.Floor - f.Basements + f.base())
+	}
+
+	return fmt.Sprintf("%s%s%d%s%0*d", floor, f.separator(), spotID.Row+f.base(), f.separator(), f.ColumnWidth, spotID.Col+f.base())
+}
+
+// Parse parses a spot ID rendered by Format, basement letters are case-insensitive and padding is optional.
+func (f SpotFormat) Parse(id string) (SpotID, error) {
+	parts := strings.Split(strings.TrimSpace(id), f.separator())
+	if len(parts) != 3 {
+		return SpotID{}, ErrMalformedSpotID
+	}
+
+	var (
+		spotID SpotID
+		err    error
+	)
+	if spotID.Floor, err = f.ParseFloor(parts[0]); err != nil {
+		return SpotID{}, err
+	}
+	if spotID.Row, err = f.ParseRow(parts[1]); err != nil {
+		return SpotID{}, err
+	}
+	if spotID.Col, err = parseSpotNumber(parts[2], f.base()); err != nil {
+		return SpotID{}, err
+	}
+
+	return spotID, nil
+}
+
+// ParseFloor parses a floor rendered by Format, e.g. "B1" or "3", into its index.
+func (f SpotFormat) ParseFloor(s string) (int, error) {
+	if basement, ok := strings.CutPrefix(strings.ToUpper(s), "B"); ok && f.Basements > 0 {
+		// B1 is the basement right below the ground floor
+		below, err := parseSpotNumber(basement, 1)
+		if err != nil {
+			return 0, err
+		}
+		if below >= f.Basements {
+			return 0, ErrSpotOutOfRange
+		}
+		return f.Basements - 1 - below, nil
+	}
+
+	floor, err := parseSpotNumber(s, f.base())
+	if err != nil {
+		return 0, err
+	}
+	return floor + f.Basements, nil
+}
+
+// ParseRow parses a row rendered by Format into its index.
+func (f SpotFormat) ParseRow(s string) (int, error) {
+	return parseSpotNumber(s, f.base())
+}
+
+func (f SpotFormat) base() int {
+	if f.OneBased {
+		return 1
+	}
+	return 0
+}
+
+func (f SpotFormat) separator() string {
+	if f.Separator == "" {
+		return "-"
+	}
+	return f.Separator
+}
+
+// parseSpotNumber parses a displayed number counted from base into a zero based index.
+func parseSpotNumber(s string, base int) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil || s == "" || s[0] < '0' || s[0] > '9' || n < base {
+		return 0, ErrMalformedSpotID
+	}
+
+	return n - base, nil
+}
@@ -0,0 +1,72 @@
+package parkingentity_test
+
+import (
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
+	"testing"
+)
+
+func TestSpotFormat(t *testing.T) {
+	format := parkingentity.SpotFormat{OneBased: true, Basements: 2, ColumnWidth: 3, Separator: "."}
+
+	cases := []struct {
+		spotID    parkingentity.SpotID
+		formatted string
+	}{
+		{spotID: parkingentity.SpotID{Floor: 0, Row: 0, Col: 0}, formatted: "B2.1.001"},
+		{spotID: parkingentity.SpotID{Floor: 1, Row: 2, Col: 9}, formatted: "B1.3.010"},
+		{spotID: parkingentity.SpotID{Floor: 2, Row: 0, Col: 1234}, formatted: "1.1.1235"},
+		{spotID: parkingentity.SpotID{Floor: 4, Row: 1, Col: 1}, formatted: "3.2.002"},
+	}
+	for _, tc := range cases {
+		if formatted := format.Format(tc.spotID); formatted != tc.formatted {
+			t.Errorf("Format(%+v): expected %s, got %s", tc.spotID, tc.formatted, formatted)
+		}
+
+		spotID, err := format.Parse(tc.formatted)
+		if err != nil || spotID != tc.spotID {
+			t.Errorf("Parse(%s): expected %+v, got %+v, %v", tc.formatted, tc.spotID, spotID, err)
+		}
+	}
+
+	t.Run("lenient parse", func(t *testing.T) {
+		spotID, err := format.Parse(" b1.3.10 ")
+		if err != nil || spotID != (parkingentity.SpotID{Floor: 1, Row: 2, Col: 9}) {
+			t.Errorf("Expected floor 1, row 2, col 9, got %+v, %v", spotID, err)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		for id, expected := range map[string]error{
+			"B3.1.1":  parkingentity.ErrSpotOutOfRange,
+			"B0.1.1":  parkingentity.ErrMalformedSpotID,
+			"0.1.1":   parkingentity.ErrMalformedSpotID,
+			"1.0.1":   parkingentity.ErrMalformedSpotID,
+			"1-1-1":   parkingentity.ErrMalformedSpotID,
+			"1.1":     parkingentity.ErrMalformedSpotID,
+			"1.1.x":   parkingentity.ErrMalformedSpotID,
+			"1.1.+10": parkingentity.ErrMalformedSpotID,
+		} {
+			if _, err := format.Parse(id); err != expected {
+				t.Errorf("Parse(%s): expected %v, got %v", id, expected, err)
+			}
+		}
+	})
+
+	t.Run("floor", func(t *testing.T) {
+		for s, expected := range map[string]int{"B2": 0, "b1": 1, "1": 2, "3": 4} {
+			if floor, err := format.ParseFloor(s); err != nil || floor != expected {
+				t.Errorf("ParseFloor(%s): expected %d, got %d, %v", s, expected, floor, err)
+			}
+		}
+		if _, err := format.ParseFloor("0"); err != parkingentity.ErrMalformedSpotID {
+			t.Errorf("ParseFloor(0): expected %v, got %v", parkingentity.ErrMalformedSpotID, err)
+		}
+	})
+
+	t.Run("zero value is the raw ID", func(t *testing.T) {
+		spotID := parkingentity.SpotID{Floor: 1, Row: 2, Col: 10}
+		if formatted := (parkingentity.SpotFormat{}).Format(spotID); formatted != spotID.ID() {
+			t.Errorf("Expected %s, got %s", spotID.ID(), formatted)
+		}
+	})
+}
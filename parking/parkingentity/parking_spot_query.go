@@ -0,0 +1,161 @@
+package parkingentity
+
+import (
+	"iter"
+	"strconv"
+	"strings"
+)
+
+const (
+	// DefaultSpotLimit is the page size of a spot query without a limit.
+	DefaultSpotLimit = 100
+	// DefaultNearDistance is how many rows plus columns away from Near a spot may be when the query has no Within.
+	DefaultNearDistance = 10
+)
+
+// SpotQuery selects a page of the free spots of a vehicle type, in the order they would be allocated.
+type SpotQuery struct {
+	VehicleType VehicleType
+	// Floor only matches spots on the floor, nil for every floor.
+	Floor *int
+	// Rows only matches spots in the rows, nil for every row.
+	Rows *RowRange
+	// Near only matches spots on the floor of the spot, e.g. a gate, at most Within rows plus columns away.
+	Near   *Spot
+	Within int
+	// Limit is the most spots of the page, DefaultSpotLimit when 0.
+	Limit int
+	// Cursor is the Next of the previous page, empty for the first page.
+	// It counts the matching spots already returned, so spots taken or freed meanwhile shift the following pages.
+	Cursor string
+}
+
+// RowRange is the rows From to To, both included.
+type RowRange struct {
+	From int
+	To   int
+}
+
+// ParseRowRange parses zero based rows written as from-to, e.g. "3-8", or a single row.
+func ParseRowRange(s string) (RowRange, error) {
+	return SpotFormat{}.ParseRowRange(s)
+}
+
+// ParseRowRange parses rows written as from-to, e.g. "3-8", or a single row, numbered the way Format renders them.
+func (f SpotFormat) ParseRowRange(s string) (RowRange, error) {
+	from, to, found := strings.Cut(s, "-")
+	if !found {
+		to = from
+	}
+
+	first, err := f.ParseRow(from)
+	if err != nil {
+		return RowRange{}, ErrInvalidSpotQuery
+	}
+	last, err := f.ParseRow(to)
+	if err != nil || last < first {
+		return RowRange{}, ErrInvalidSpotQuery
+	}
+
+	return RowRange{From: first, To: last}, nil
+}
+
+// SpotPage is a page of the spots matching a query.
+type SpotPage struct {
+	Spots []Spot
+	// Total is how many free spots match the query, on every page.
+	Total int
+	// Next is the cursor of the next page, empty on the last one.
+	Next string
+}
+
+// Validate checks the filters and the limit, and returns how many matching spots the cursor skips.
+func (q SpotQuery) Validate() (offset int, err error) {
+	if q.Limit < 0 || q.Within < 0 || (q.Rows != nil && q.Rows.From > q.Rows.To) {
+		return 0, ErrInvalidSpotQuery
+	}
+
+	if q.Cursor == "" {
+		return 0, nil
+	}
+
+	offset, err = strconv.Atoi(q.Cursor)
+	if err != nil || offset < 0 {
+		return 0, ErrInvalidCursor
+	}
+
+	return offset, nil
+}
+
+// PageSize returns the most spots of a page.
+func (q SpotQuery) PageSize() int {
+	if q.Limit == 0 {
+		return DefaultSpotLimit
+	}
+	return q.Limit
+}
+
+// Distance returns how many rows plus columns away from Near a spot may be.
+func (q SpotQuery) Distance() int {
+	if q.Within == 0 {
+		return DefaultNearDistance
+	}
+	return q.Within
+}
+
+// Matches reports whether the spot passes every filter of the query.
+func (q SpotQuery) Matches(spot Spot) bool {
+	if q.Floor != nil && spot.Floor != *q.Floor {
+		return false
+	}
+	if q.Rows != nil && (spot.Row < q.Rows.From || spot.Row > q.Rows.To) {
+		return false
+	}
+	if q.Near != nil {
+		if spot.Floor != q.Near.Floor || abs(spot.Row-q.Near.Row)+abs(spot.Col-q.Near.Col) > q.Distance() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Page walks the free spots in allocation order and keeps the page of the matching ones, only the page is copied.
+func (q SpotQuery) Page(spots iter.Seq[Spot]) (SpotPage, error) {
+	offset, err := q.Validate()
+	if err != nil {
+		return SpotPage{}, err
+	}
+
+	limit := q.PageSize()
+	page := SpotPage{Spots: make([]Spot, 0, min(limit, 64))}
+	for spot := range spots {
+		if !q.Matches(spot) {
+			continue
+		}
+
+		if page.Total >= offset && len(page.Spots) < limit {
+			page.Spots = append(page.Spots, spot)
+		}
+		page.Total++
+	}
+
+	return NewSpotPage(page.Spots, offset, page.Total), nil
+}
+
+// NewSpotPage returns the page of spots found after skipping offset of total matching spots.
+func NewSpotPage(spots []Spot, offset, total int) SpotPage {
+	page := SpotPage{Spots: spots, Total: total}
+	if next := offset + len(spots); len(spots) > 0 && next < total {
+		page.Next = strconv.Itoa(next)
+	}
+
+	return page
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
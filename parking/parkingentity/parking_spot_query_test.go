@@ -0,0 +1,67 @@
+package parkingentity_test
+
+import (
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestParseRowRange(t *testing.T) {
+	for s, expected := range map[string]parkingentity.RowRange{"3-8": {From: 3, To: 8}, "4": {From: 4, To: 4}, "0-0": {}} {
+		if rows, err := parkingentity.ParseRowRange(s); err != nil || rows != expected {
+			t.Errorf("ParseRowRange(%q): expected %v, got %v, %v", s, expected, rows, err)
+		}
+	}
+
+	for _, s := range []string{"", "8-3", "-1", "a-b", "1-", "1-2-3"} {
+		if _, err := parkingentity.ParseRowRange(s); err != parkingentity.ErrInvalidSpotQuery {
+			t.Errorf("ParseRowRange(%q): expected %v, got %v", s, parkingentity.ErrInvalidSpotQuery, err)
+		}
+	}
+
+	// rows are numbered the way the spot IDs show them
+	oneBased := parkingentity.SpotFormat{OneBased: true}
+	if rows, err := oneBased.ParseRowRange("1-3"); err != nil || rows != (parkingentity.RowRange{From: 0, To: 2}) {
+		t.Errorf("Expected one based rows 1-3 to be 0 to 2, got %v, %v", rows, err)
+	}
+	if _, err := oneBased.ParseRowRange("0-3"); err != parkingentity.ErrInvalidSpotQuery {
+		t.Errorf("Expected %v for row 0 of one based rows, got %v", parkingentity.ErrInvalidSpotQuery, err)
+	}
+}
+
+func TestSpotQueryPage(t *testing.T) {
+	var spots []parkingentity.Spot
+	for floor := 0; floor < 2; floor++ {
+		for row := 0; row < 3; row++ {
+			for col := 0; col < 3; col++ {
+				spots = append(spots, parkingentity.Spot{Floor: floor, Row: row, Col: col})
+			}
+		}
+	}
+
+	floor := 1
+	query := parkingentity.SpotQuery{Floor: &floor, Rows: &parkingentity.RowRange{From: 1, To: 2}, Limit: 4}
+
+	first, err := query.Page(slices.Values(spots))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Total != 6 || first.Next != "4" || !reflect.DeepEqual(first.Spots, spots[12:16]) {
+		t.Errorf("Expected the first 4 of 6 spots with a cursor, got %+v", first)
+	}
+
+	query.Cursor = first.Next
+	last, err := query.Page(slices.Values(spots))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last.Total != 6 || last.Next != "" || !reflect.DeepEqual(last.Spots, spots[16:18]) {
+		t.Errorf("Expected the last 2 of 6 spots without a cursor, got %+v", last)
+	}
+
+	near := parkingentity.SpotQuery{Near: &parkingentity.Spot{Floor: 0, Row: 0, Col: 0}, Within: 1}
+	if page, _ := near.Page(slices.Values(spots)); !reflect.DeepEqual(page.Spots, []parkingentity.Spot{{Floor: 0, Row: 0, Col: 0}, {Floor: 0, Row: 0, Col: 1}, {Floor: 0, Row: 1, Col: 0}}) {
+		t.Errorf("Expected the 3 spots next to 0-0-0, got %v", page.Spots)
+	}
+}
@@ -0,0 +1,143 @@
+package parkingmetrics
+
+import (
+	parkingpkg "github.com/mtfiqh/DoiT-parking-system/parking"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
+	"github.com/mtfiqh/DoiT-parking-system/pkg/metricsx"
+	"github.com/pkg/errors"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Operation is the name of an instrumented parking operation.
+type Operation string
+
+const (
+	OpPark          Operation = "park"
+	OpUnpark        Operation = "unpark"
+	OpSearchVehicle Operation = "search_vehicle"
+	OpAvailableSpot Operation = "available_spot"
+	OpQuerySpots    Operation = "query_spots"
+)
+
+// latencyBuckets are the upper bounds in seconds of the latency histogram, from 1µs to 1s.
+var latencyBuckets = []float64{0.000001, 0.000005, 0.00001, 0.00005, 0.0001, 0.0005, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1}
+
+// Metrics counts the parking operations and their latency, a nil *Metrics records nothing.
+type Metrics struct {
+	registry   *metricsx.Registry
+	operations *metricsx.CounterVec
+	latency    *metricsx.HistogramVec
+}
+
+// New registers the operation metrics on the registry.
+func New(registry *metricsx.Registry) *Metrics {
+	return &Metrics{
+		registry: registry,
+		operations: registry.Counter("parking_operations_total",
+			"Parking operations by vehicle type and outcome, empty vehicle type when unknown.", "operation", "vehicle_type", "outcome"),
+		latency: registry.Histogram("parking_operation_duration_seconds",
+			"Latency of the parking operations.", latencyBuckets, "operation"),
+	}
+}
+
+// Observe records an operation that returned err after took.
+func (m *Metrics) Observe(op Operation, vehicleType parkingentity.VehicleType, err error, took time.Duration) {
+	if m == nil {
+		return
+	}
+
+	m.operations.Inc(string(op), vehicleTypeLabel(vehicleType), Outcome(err))
+	m.latency.Observe(took.Seconds(), string(op))
+}
+
+// Count returns how many operations of the vehicle type ended with the outcome.
+func (m *Metrics) Count(op Operation, vehicleType parkingentity.VehicleType, outcome string) int {
+	return int(m.operations.Value(string(op), vehicleTypeLabel(vehicleType), outcome))
+}
+
+// Watch registers the free spots of the lot per vehicle type and per floor, the gauges of a scrape share one read of the occupancy.
+func (m *Metrics) Watch(park parkingpkg.OccupancyReporter) {
+	occupancy := newOccupancyCache(park)
+
+	m.registry.GaugeFunc("parking_free_spots", "Free spots by vehicle type.", []string{"vehicle_type"},
+		func(set func(float64, ...string)) {
+			for spotType, count := range occupancy.get().Types() {
+				if spotType != parkingentity.X0 {
+					set(float64(count.Free()), spotType.String())
+				}
+			}
+		})
+	m.registry.GaugeFunc("parking_floor_free_spots", "Free spots by floor and vehicle type.", []string{"floor", "vehicle_type"},
+		func(set func(float64, ...string)) {
+			for _, floor := range occupancy.get().Floors {
+				for spotType, count := range floor.Types {
+					if spotType != parkingentity.X0 {
+						set(float64(count.Free()), strconv.Itoa(floor.Floor), spotType.String())
+					}
+				}
+			}
+		})
+	m.registry.GaugeFunc("parking_disabled_spots", "Disabled spots.", nil,
+		func(set func(float64, ...string)) {
+			set(float64(occupancy.get().Types()[parkingentity.X0].Total))
+		})
+}
+
+// occupancyCache reads the occupancy of a lot at most once every occupancyTTL, the gauges of one scrape share it.
+type occupancyCache struct {
+	park  parkingpkg.OccupancyReporter
+	last  parkingentity.Occupancy
+	at    time.Time
+	mutex sync.Mutex
+}
+
+const occupancyTTL = time.Second
+
+func newOccupancyCache(park parkingpkg.OccupancyReporter) *occupancyCache {
+	return &occupancyCache{park: park}
+}
+
+func (c *occupancyCache) get() parkingentity.Occupancy {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if time.Since(c.at) >= occupancyTTL {
+		c.last, c.at = c.park.Occupancy(), time.Now()
+	}
+	return c.last
+}
+
+// Outcome names the result of an operation for the outcome label: ok, or the parking error it failed with.
+func Outcome(err error) string {
+	if err == nil {
+		return "ok"
+	}
+
+	switch errors.Cause(err) {
+	case parkingentity.ErrSpotNotFound:
+		return "spot_not_found"
+	case parkingentity.ErrVehicleAlreadyParked:
+		return "already_parked"
+	case parkingentity.ErrVehicleNotFound:
+		return "vehicle_not_found"
+	case parkingentity.ErrVehicleNotAtSpot:
+		return "not_at_spot"
+	case parkingentity.ErrVehicleReserved:
+		return "reserved"
+	case parkingentity.ErrInvalidVehicleType, parkingentity.ErrInvalidPlate, parkingentity.ErrMalformedSpotID, parkingentity.ErrSpotOutOfRange,
+		parkingentity.ErrInvalidSpotQuery, parkingentity.ErrInvalidCursor:
+		return "invalid"
+	default:
+		return "error"
+	}
+}
+
+// vehicleTypeLabel is the code of the vehicle type, empty when it isn't one that parks.
+func vehicleTypeLabel(vehicleType parkingentity.VehicleType) string {
+	if vehicleType >= parkingentity.X0 {
+		return ""
+	}
+	return vehicleType.String()
+}
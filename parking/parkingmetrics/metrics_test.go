@@ -0,0 +1,71 @@
+package parkingmetrics_test
+
+import (
+	"bytes"
+	"github.com/mtfiqh/DoiT-parking-system/cli/parkingcli"
+	parkingpkg "github.com/mtfiqh/DoiT-parking-system/parking"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingmetrics"
+	"github.com/mtfiqh/DoiT-parking-system/pkg/metricsx"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMetrics(t *testing.T) {
+	layout := filepath.Join(t.TempDir(), "lot.txt")
+	if err := os.WriteFile(layout, []byte("A-1 A-1 M-1 X-0\n---\nB-1 A-1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	registry := metricsx.NewRegistry()
+	metrics := parkingmetrics.New(registry)
+	park, err := parkingcli.NewPark(parkingcli.WithLayoutFile(layout), parkingcli.WithMetrics(metrics))
+	if err != nil {
+		t.Fatal(err)
+	}
+	metrics.Watch(park.(parkingpkg.OccupancyReporter))
+
+	// four cars for three spots, the last one is turned away
+	for i := 0; i < 4; i++ {
+		_, err = park.Park(parkingentity.A1, parkingentity.PlateFromNumber(1000+i))
+	}
+	if err != parkingentity.ErrSpotNotFound {
+		t.Fatalf("Expected %v, got %v", parkingentity.ErrSpotNotFound, err)
+	}
+	_, _ = park.Park(parkingentity.M1, "1000")
+	_, _ = park.SearchVehicle("1000")
+	_, _ = park.SearchVehicle("9999")
+	_, _ = park.Unpark("0-0-2", "1000")
+	park.AvailableSpot(parkingentity.B1)
+
+	if got := metrics.Count(parkingmetrics.OpPark, parkingentity.A1, "ok"); got != 3 {
+		t.Errorf("Expected 3 parked cars, got %d", got)
+	}
+
+	var out bytes.Buffer
+	if err := registry.Write(&out); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, line := range []string{
+		`parking_operations_total{operation="park",vehicle_type="A-1",outcome="ok"} 3`,
+		`parking_operations_total{operation="park",vehicle_type="A-1",outcome="spot_not_found"} 1`,
+		`parking_operations_total{operation="park",vehicle_type="M-1",outcome="already_parked"} 1`,
+		`parking_operations_total{operation="search_vehicle",vehicle_type="A-1",outcome="ok"} 1`,
+		`parking_operations_total{operation="search_vehicle",vehicle_type="",outcome="vehicle_not_found"} 1`,
+		`parking_operations_total{operation="unpark",vehicle_type="A-1",outcome="not_at_spot"} 1`,
+		`parking_operations_total{operation="available_spot",vehicle_type="B-1",outcome="ok"} 1`,
+		`parking_operation_duration_seconds_count{operation="park"} 5`,
+		`parking_free_spots{vehicle_type="A-1"} 0`,
+		`parking_free_spots{vehicle_type="M-1"} 1`,
+		`parking_floor_free_spots{floor="0",vehicle_type="A-1"} 0`,
+		`parking_floor_free_spots{floor="1",vehicle_type="B-1"} 1`,
+		`parking_disabled_spots 1`,
+	} {
+		if !strings.Contains(out.String(), line+"\n") {
+			t.Errorf("Expected %q in:\n%s", line, out.String())
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package parkingsql
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Dialect holds the differences between the supported databases.
+type Dialect struct {
+	name string
+	// serial is the column definition of an auto increment primary key.
+	serial string
+	// lockAvailable is appended to the query picking a free spot so concurrent gates skip each other's spots.
+	lockAvailable string
+	// lockRow is appended to queries reading a row that's updated in the same transaction.
+	lockRow     string
+	dollarBinds bool
+}
+
+var (
+	// Postgres locks the picked spot with FOR UPDATE SKIP LOCKED.
+	Postgres = Dialect{
+		name:          "postgres",
+		serial:        "BIGSERIAL PRIMARY KEY",
+		lockAvailable: " FOR UPDATE SKIP LOCKED",
+		lockRow:       " FOR UPDATE",
+		dollarBinds:   true,
+	}
+
+	// SQLite has no row locks, open it with _txlock=immediate so write transactions are serialised.
+	SQLite = Dialect{
+		name:   "sqlite",
+		serial: "INTEGER PRIMARY KEY AUTOINCREMENT",
+	}
+)
+
+func (d Dialect) String() string {
+	return d.name
+}
+
+// rebind rewrites ? placeholders to the dialect's bind variables.
+func (d Dialect) rebind(query string) string {
+	if !d.dollarBinds {
+		return query
+	}
+
+	var (
+		b strings.Builder
+		n int
+	)
+	for _, c := range query {
+		if c == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(c)
+	}
+
+	return b.String()
+}
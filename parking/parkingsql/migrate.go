@@ -0,0 +1,79 @@
+package parkingsql
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"github.com/pkg/errors"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// Migrate applies the migrations that are not applied yet, migration files are named <version>_<name>.sql.
+func Migrate(ctx context.Context, db *sql.DB, dialect Dialect) error {
+	if _, err := db.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS schema_migrations (version BIGINT NOT NULL PRIMARY KEY)"); err != nil {
+		return errors.Wrap(err, "creating schema_migrations")
+	}
+
+	files, err := fs.Glob(migrations, "migrations/*.sql")
+	if err != nil {
+		return err
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		name := strings.TrimPrefix(file, "migrations/")
+		version, err := strconv.ParseInt(name[:strings.IndexByte(name, '_')], 10, 64)
+		if err != nil {
+			return errors.Wrapf(err, "migration %s", name)
+		}
+
+		if err := applyMigration(ctx, db, dialect, file, version); err != nil {
+			return errors.Wrapf(err, "migration %s", name)
+		}
+	}
+
+	return nil
+}
+
+func applyMigration(ctx context.Context, db *sql.DB, dialect Dialect, file string, version int64) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var applied int
+	if err := tx.QueryRowContext(ctx, dialect.rebind("SELECT COUNT(*) FROM schema_migrations WHERE version = ?"), version).Scan(&applied); err != nil {
+		return err
+	}
+	if applied > 0 {
+		return nil
+	}
+
+	content, err := migrations.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	script := strings.ReplaceAll(string(content), "{{serial}}", dialect.serial)
+	for _, stmt := range strings.Split(script, ";") {
+		if strings.TrimSpace(stmt) == "" {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, dialect.rebind("INSERT INTO schema_migrations (version) VALUES (?)"), version); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,40 @@
+CREATE TABLE spots (
+    floor        INTEGER NOT NULL,
+    row_no       INTEGER NOT NULL,
+    col_no       INTEGER NOT NULL,
+    vehicle_type INTEGER NOT NULL,
+    occupied     BOOLEAN NOT NULL DEFAULT FALSE,
+    queue_seq    BIGINT  NOT NULL,
+    PRIMARY KEY (floor, row_no, col_no)
+);
+
+CREATE INDEX spots_available_idx ON spots (vehicle_type, occupied, queue_seq);
+
+CREATE TABLE vehicles (
+    vehicle_number BIGINT  NOT NULL PRIMARY KEY,
+    vehicle_type   INTEGER NOT NULL,
+    floor          INTEGER NOT NULL,
+    row_no         INTEGER NOT NULL,
+    col_no         INTEGER NOT NULL,
+    still_parked   BOOLEAN NOT NULL
+);
+
+CREATE TABLE parking_sessions (
+    id             {{serial}},
+    vehicle_number BIGINT    NOT NULL,
+    vehicle_type   INTEGER   NOT NULL,
+    floor          INTEGER   NOT NULL,
+    row_no         INTEGER   NOT NULL,
+    col_no         INTEGER   NOT NULL,
+    parked_at      TIMESTAMP NOT NULL,
+    unparked_at    TIMESTAMP NULL
+);
+
+CREATE INDEX parking_sessions_vehicle_idx ON parking_sessions (vehicle_number, parked_at);
+
+CREATE TABLE counters (
+    name  VARCHAR(32) NOT NULL PRIMARY KEY,
+    value BIGINT      NOT NULL
+);
+
+INSERT INTO counters (name, value) VALUES ('queue_seq', 0);
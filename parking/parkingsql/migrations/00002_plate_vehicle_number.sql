@@ -0,0 +1,29 @@
+-- vehicle numbers become normalized plates, existing numbers keep their digits as the plate
+CREATE TABLE vehicles_plate (
+    vehicle_number VARCHAR(16) NOT NULL PRIMARY KEY,
+    vehicle_type   INTEGER     NOT NULL,
+    floor          INTEGER     NOT NULL,
+    row_no         INTEGER     NOT NULL,
+    col_no         INTEGER     NOT NULL,
+    still_parked   BOOLEAN     NOT NULL
+);
+
+INSERT INTO vehicles_plate (vehicle_number, vehicle_type, floor, row_no, col_no, still_parked)
+SELECT CAST(vehicle_number AS VARCHAR(16)), vehicle_type, floor, row_no, col_no, still_parked FROM vehicles;
+
+DROP TABLE vehicles;
+
+ALTER TABLE vehicles_plate RENAME TO vehicles;
+
+-- parking_sessions keeps its ids, so the column is swapped instead of rebuilding the table
+DROP INDEX parking_sessions_vehicle_idx;
+
+ALTER TABLE parking_sessions ADD COLUMN vehicle_plate VARCHAR(16) NOT NULL DEFAULT '';
+
+UPDATE parking_sessions SET vehicle_plate = CAST(vehicle_number AS VARCHAR(16));
+
+ALTER TABLE parking_sessions DROP COLUMN vehicle_number;
+
+ALTER TABLE parking_sessions RENAME COLUMN vehicle_plate TO vehicle_number;
+
+CREATE INDEX parking_sessions_vehicle_idx ON parking_sessions (vehicle_number, parked_at);
@@ -0,0 +1,16 @@
+-- plates are normalized without dashes, so the negative numbers cast by 00002 lose their sign the way lookups do.
+-- a number and its negative are the same plate now: the one no longer parked gives way to the other,
+-- and when both are still parked the negative one keeps its old plate.
+DELETE FROM vehicles
+WHERE vehicle_number LIKE '-%' AND still_parked = FALSE
+  AND REPLACE(vehicle_number, '-', '') IN (SELECT vehicle_number FROM vehicles);
+
+DELETE FROM vehicles
+WHERE vehicle_number NOT LIKE '-%' AND still_parked = FALSE
+  AND '-' || vehicle_number IN (SELECT vehicle_number FROM vehicles);
+
+UPDATE vehicles SET vehicle_number = REPLACE(vehicle_number, '-', '')
+WHERE vehicle_number LIKE '-%'
+  AND REPLACE(vehicle_number, '-', '') NOT IN (SELECT vehicle_number FROM vehicles);
+
+UPDATE parking_sessions SET vehicle_number = REPLACE(vehicle_number, '-', '') WHERE vehicle_number LIKE '-%';
@@ -0,0 +1,4 @@
+-- the gates a session entered and left at, empty when unknown
+ALTER TABLE parking_sessions ADD COLUMN gate VARCHAR(64) NOT NULL DEFAULT '';
+
+ALTER TABLE parking_sessions ADD COLUMN exit_gate VARCHAR(64) NOT NULL DEFAULT '';
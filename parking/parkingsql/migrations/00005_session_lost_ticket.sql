@@ -0,0 +1,2 @@
+-- set when the vehicle left without its ticket and was charged the lost ticket penalty
+ALTER TABLE parking_sessions ADD COLUMN lost_ticket BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,150 @@
+package parkingsql
+
+import (
+	"context"
+	"database/sql"
+	parkingpkg "github.com/mtfiqh/DoiT-parking-system/parking"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
+	"github.com/mtfiqh/DoiT-parking-system/pkg/clockx"
+	"github.com/pkg/errors"
+)
+
+// parking implements the parking system on a SQL database, every operation runs in a single transaction.
+type parking struct {
+	db         *sql.DB
+	dialect    Dialect
+	plateRule  parkingentity.PlateRule
+	spotFormat parkingentity.SpotFormat
+	fallback   parkingentity.Fallback
+	clock      clockx.Clock
+	biller     parkingpkg.Biller
+	gates      []parkingentity.Gate
+}
+
+// NewPark migrates the database and returns a parking system backed by it.
+func NewPark(db *sql.DB, opts ...ParkOption) (parkingpkg.ParkingSystem, error) {
+	opt := &ParkOptions{
+		Dialect:   Postgres,
+		PlateRule: parkingentity.PlateRuleAny,
+		Clock:     clockx.New(),
+	}
+	for _, o := range opts {
+		o(opt)
+	}
+
+	park := &parking{
+		db:         db,
+		dialect:    opt.Dialect,
+		plateRule:  opt.PlateRule,
+		spotFormat: opt.SpotFormat,
+		fallback:   opt.Fallback,
+		clock:      opt.Clock,
+		biller:     opt.Biller,
+		gates:      opt.Gates,
+	}
+
+	ctx := context.Background()
+	if err := Migrate(ctx, db, park.dialect); err != nil {
+		return nil, errors.Wrap(err, "migrating parking database")
+	}
+
+	if opt.Spaces != nil {
+		if err := park.Seed(ctx, opt.Spaces); err != nil {
+			return nil, errors.Wrap(err, "seeding parking spots")
+		}
+	}
+
+	return park, nil
+}
+
+// ParkOptions defines the options for initializing a SQL parking instance.
+type ParkOptions struct {
+	Dialect    Dialect
+	Spaces     [][][]int
+	PlateRule  parkingentity.PlateRule
+	SpotFormat parkingentity.SpotFormat
+	Fallback   parkingentity.Fallback
+	Clock      clockx.Clock
+	Biller     parkingpkg.Biller
+	Gates      []parkingentity.Gate
+}
+
+// ParkOption is a function type that modifies the ParkOptions.
+type ParkOption func(*ParkOptions)
+
+// WithDialect sets the database dialect, defaults to Postgres.
+func WithDialect(dialect Dialect) ParkOption {
+	return func(opt *ParkOptions) {
+		opt.Dialect = dialect
+	}
+}
+
+// WithSpaces seeds the spots from spaces indexed by floor, row and column when the database has no spots yet.
+func WithSpaces(spaces [][][]int) ParkOption {
+	return func(opt *ParkOptions) {
+		opt.Spaces = spaces
+	}
+}
+
+// WithPlateRule validates the plates of parked vehicles with the rule of a region, defaults to PlateRuleAny.
+func WithPlateRule(rule parkingentity.PlateRule) ParkOption {
+	return func(opt *ParkOptions) {
+		opt.PlateRule = rule
+	}
+}
+
+// WithSpotFormat displays spot IDs in a custom format, Unpark takes spot IDs in that format.
+func WithSpotFormat(format parkingentity.SpotFormat) ParkOption {
+	return func(opt *ParkOptions) {
+		opt.SpotFormat = format
+	}
+}
+
+// WithFallback lets vehicles overflow into other spot types when their own are full, e.g. parkingentity.FallbackBySize.
+// A spot keeps its vehicle_type, unparking gives it back to the queue of that type.
+func WithFallback(fallback parkingentity.Fallback) ParkOption {
+	return func(opt *ParkOptions) {
+		opt.Fallback = fallback
+	}
+}
+
+// WithClock sets the clock used for session timestamps.
+func WithClock(clock clockx.Clock) ParkOption {
+	return func(opt *ParkOptions) {
+		opt.Clock = clock
+	}
+}
+
+// WithBilling charges every unpark, e.g. with a billing.Engine.
+func WithBilling(biller parkingpkg.Biller) ParkOption {
+	return func(opt *ParkOptions) {
+		opt.Biller = biller
+	}
+}
+
+// WithGates sets the gates vehicles enter and exit at with ParkAt and UnparkAt, they are recorded on the sessions.
+// Spots are still taken from the head of the queue, whatever the gate.
+func WithGates(gates ...parkingentity.Gate) ParkOption {
+	return func(opt *ParkOptions) {
+		opt.Gates = gates
+	}
+}
+
+// inTx runs fn in a transaction and commits it when fn succeeds.
+func (p *parking) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (p *parking) SpotFormat() parkingentity.SpotFormat {
+	return p.spotFormat
+}
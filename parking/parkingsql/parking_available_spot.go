@@ -0,0 +1,25 @@
+package parkingsql
+
+import (
+	"context"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
+)
+
+func (p *parking) AvailableSpot(vehicleType parkingentity.VehicleType) (int, []parkingentity.Spot) {
+	rows, err := p.db.QueryContext(context.Background(), p.dialect.rebind("SELECT floor, row_no, col_no FROM spots WHERE vehicle_type = ? AND occupied = FALSE ORDER BY queue_seq"), vehicleType)
+	if err != nil {
+		return 0, nil
+	}
+	defer rows.Close()
+
+	var spots []parkingentity.Spot
+	for rows.Next() {
+		var spot parkingentity.Spot
+		if err := rows.Scan(&spot.Floor, &spot.Row, &spot.Col); err != nil {
+			return 0, nil
+		}
+		spots = append(spots, spot)
+	}
+
+	return len(spots), spots
+}
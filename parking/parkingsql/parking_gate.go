@@ -0,0 +1,21 @@
+package parkingsql
+
+import (
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
+	"slices"
+)
+
+func (p *parking) Gates() []parkingentity.Gate {
+	return slices.Clone(p.gates)
+}
+
+// gate returns the gate of the ID.
+func (p *parking) gate(id string) (parkingentity.Gate, error) {
+	for _, gate := range p.gates {
+		if gate.ID == id {
+			return gate, nil
+		}
+	}
+
+	return parkingentity.Gate{}, parkingentity.ErrUnknownGate
+}
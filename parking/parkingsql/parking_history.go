@@ -0,0 +1,54 @@
+package parkingsql
+
+import (
+	"context"
+	"database/sql"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
+	"time"
+)
+
+const selectSessions = "SELECT vehicle_number, vehicle_type, floor, row_no, col_no, parked_at, unparked_at, gate, exit_gate, lost_ticket FROM parking_sessions "
+
+func (p *parking) History(vehicleNumber parkingentity.Plate) ([]parkingentity.Session, error) {
+	vehicleNumber = vehicleNumber.Normalize()
+
+	sessions, err := p.querySessions(selectSessions+"WHERE vehicle_number = ? ORDER BY parked_at, id", vehicleNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sessions) == 0 {
+		return nil, parkingentity.ErrVehicleNotFound
+	}
+
+	return sessions, nil
+}
+
+func (p *parking) SessionsBetween(from, to time.Time) ([]parkingentity.Session, error) {
+	return p.querySessions(selectSessions+"WHERE parked_at < ? AND (unparked_at IS NULL OR unparked_at >= ?) ORDER BY parked_at, id", to.UTC(), from.UTC())
+}
+
+func (p *parking) querySessions(query string, args ...any) ([]parkingentity.Session, error) {
+	rows, err := p.db.QueryContext(context.Background(), p.dialect.rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := make([]parkingentity.Session, 0)
+	for rows.Next() {
+		var (
+			session parkingentity.Session
+			exitAt  sql.NullTime
+		)
+		err := rows.Scan(&session.VehicleNumber, &session.VehicleType, &session.SpotID.Floor, &session.SpotID.Row, &session.SpotID.Col, &session.EntryAt, &exitAt, &session.Gate, &session.ExitGate, &session.LostTicket)
+		if err != nil {
+			return nil, err
+		}
+
+		session.ExitAt = exitAt.Time
+		sessions = append(sessions, session)
+	}
+
+	return sessions, rows.Err()
+}
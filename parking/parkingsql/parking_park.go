@@ -0,0 +1,97 @@
+package parkingsql
+
+import (
+	"context"
+	"database/sql"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
+)
+
+func (p *parking) Park(vehicleType parkingentity.VehicleType, vehicleNumber parkingentity.Plate) (*parkingentity.SpotID, error) {
+	return p.park("", vehicleType, vehicleNumber)
+}
+
+func (p *parking) ParkAt(gateID string, vehicleType parkingentity.VehicleType, vehicleNumber parkingentity.Plate) (*parkingentity.SpotID, error) {
+	gate, err := p.gate(gateID)
+	if err != nil {
+		return nil, err
+	}
+	if !gate.Entry() {
+		return nil, parkingentity.ErrGateDirection
+	}
+
+	return p.park(gate.ID, vehicleType, vehicleNumber)
+}
+
+// park parks the vehicle entering at the gate, empty when unknown.
+func (p *parking) park(gate string, vehicleType parkingentity.VehicleType, vehicleNumber parkingentity.Plate) (*parkingentity.SpotID, error) {
+	vehicleNumber, err := parkingentity.ParsePlate(string(vehicleNumber), p.plateRule)
+	if err != nil {
+		return nil, err
+	}
+
+	switch vehicleType {
+	case parkingentity.A1, parkingentity.B1, parkingentity.M1:
+	default:
+		return nil, parkingentity.ErrInvalidVehicleType
+	}
+
+	ctx := context.Background()
+	var spotID parkingentity.SpotID
+
+	err = p.inTx(ctx, func(tx *sql.Tx) error {
+		var stillParked bool
+		err := tx.QueryRowContext(ctx, p.dialect.rebind("SELECT still_parked FROM vehicles WHERE vehicle_number = ?"), vehicleNumber).Scan(&stillParked)
+		if err != nil && err != sql.ErrNoRows {
+			return err
+		}
+		if stillParked {
+			return parkingentity.ErrVehicleAlreadyParked
+		}
+
+		// the free spot with the lowest queue sequence is the head of the queue, the fallback spot types are tried in order
+		err = sql.ErrNoRows
+		for _, spotType := range p.fallback.SpotTypes(vehicleType) {
+			err = tx.QueryRowContext(ctx, p.dialect.rebind(`SELECT floor, row_no, col_no FROM spots
+				WHERE vehicle_type = ? AND occupied = FALSE
+				ORDER BY queue_seq LIMIT 1`+p.dialect.lockAvailable), spotType).Scan(&spotID.Floor, &spotID.Row, &spotID.Col)
+			if err != sql.ErrNoRows {
+				break
+			}
+		}
+		if err == sql.ErrNoRows {
+			return parkingentity.ErrSpotNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		// the guard makes a concurrent park of the same vehicle lose, even when the vehicle row didn't exist yet
+		res, err := tx.ExecContext(ctx, p.dialect.rebind(`INSERT INTO vehicles (vehicle_number, vehicle_type, floor, row_no, col_no, still_parked)
+			VALUES (?, ?, ?, ?, ?, TRUE)
+			ON CONFLICT (vehicle_number) DO UPDATE SET
+				vehicle_type = excluded.vehicle_type, floor = excluded.floor, row_no = excluded.row_no, col_no = excluded.col_no, still_parked = TRUE
+			WHERE vehicles.still_parked = FALSE`), vehicleNumber, vehicleType, spotID.Floor, spotID.Row, spotID.Col)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return err
+		} else if n == 0 {
+			return parkingentity.ErrVehicleAlreadyParked
+		}
+
+		_, err = tx.ExecContext(ctx, p.dialect.rebind("UPDATE spots SET occupied = TRUE WHERE floor = ? AND row_no = ? AND col_no = ?"), spotID.Floor, spotID.Row, spotID.Col)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, p.dialect.rebind(`INSERT INTO parking_sessions (vehicle_number, vehicle_type, floor, row_no, col_no, parked_at, gate)
+			VALUES (?, ?, ?, ?, ?, ?, ?)`), vehicleNumber, vehicleType, spotID.Floor, spotID.Row, spotID.Col, p.clock.Now().UTC(), gate)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &spotID, nil
+}
@@ -0,0 +1,63 @@
+package parkingsql
+
+import (
+	"context"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
+)
+
+// QuerySpots filters and pages the free spots in the database, the total is counted alongside the page.
+func (p *parking) QuerySpots(query parkingentity.SpotQuery) (parkingentity.SpotPage, error) {
+	offset, err := query.Validate()
+	if err != nil {
+		return parkingentity.SpotPage{}, err
+	}
+	if query.VehicleType >= parkingentity.X0 {
+		return parkingentity.SpotPage{}, parkingentity.ErrInvalidVehicleType
+	}
+
+	where, args := "vehicle_type = ? AND occupied = FALSE", []any{query.VehicleType}
+	if query.Floor != nil {
+		where += " AND floor = ?"
+		args = append(args, *query.Floor)
+	}
+	if query.Rows != nil {
+		where += " AND row_no BETWEEN ? AND ?"
+		args = append(args, query.Rows.From, query.Rows.To)
+	}
+	if query.Near != nil {
+		where += " AND floor = ? AND ABS(row_no - ?) + ABS(col_no - ?) <= ?"
+		args = append(args, query.Near.Floor, query.Near.Row, query.Near.Col, query.Distance())
+	}
+
+	ctx := context.Background()
+	rows, err := p.db.QueryContext(ctx, p.dialect.rebind("SELECT floor, row_no, col_no, COUNT(*) OVER () FROM spots WHERE "+where+" ORDER BY queue_seq LIMIT ? OFFSET ?"),
+		append(args, query.PageSize(), offset)...)
+	if err != nil {
+		return parkingentity.SpotPage{}, err
+	}
+	defer rows.Close()
+
+	var (
+		spots []parkingentity.Spot
+		total int
+	)
+	for rows.Next() {
+		var spot parkingentity.Spot
+		if err := rows.Scan(&spot.Floor, &spot.Row, &spot.Col, &total); err != nil {
+			return parkingentity.SpotPage{}, err
+		}
+		spots = append(spots, spot)
+	}
+	if err := rows.Err(); err != nil {
+		return parkingentity.SpotPage{}, err
+	}
+
+	// a page past the end has no row to carry the total
+	if len(spots) == 0 && offset > 0 {
+		if err := p.db.QueryRowContext(ctx, p.dialect.rebind("SELECT COUNT(*) FROM spots WHERE "+where), args...).Scan(&total); err != nil {
+			return parkingentity.SpotPage{}, err
+		}
+	}
+
+	return parkingentity.NewSpotPage(spots, offset, total), nil
+}
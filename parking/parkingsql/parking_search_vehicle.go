@@ -0,0 +1,33 @@
+package parkingsql
+
+import (
+	"context"
+	"database/sql"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
+)
+
+func (p *parking) SearchVehicle(vehicleNumber parkingentity.Plate) (*parkingentity.SpotID, error) {
+	vehicleSpot, err := p.LocateVehicle(vehicleNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	return &vehicleSpot.SpotID, nil
+}
+
+func (p *parking) LocateVehicle(vehicleNumber parkingentity.Plate) (*parkingentity.VehicleSpot, error) {
+	vehicleNumber = vehicleNumber.Normalize()
+
+	var vehicleSpot parkingentity.VehicleSpot
+
+	err := p.db.QueryRowContext(context.Background(), p.dialect.rebind("SELECT vehicle_type, floor, row_no, col_no, still_parked FROM vehicles WHERE vehicle_number = ?"), vehicleNumber).
+		Scan(&vehicleSpot.Type, &vehicleSpot.Floor, &vehicleSpot.Row, &vehicleSpot.Col, &vehicleSpot.StillParked)
+	if err == sql.ErrNoRows {
+		return nil, parkingentity.ErrVehicleNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &vehicleSpot, nil
+}
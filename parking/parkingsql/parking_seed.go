@@ -0,0 +1,47 @@
+package parkingsql
+
+import (
+	"context"
+	"database/sql"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
+)
+
+// Seed inserts every usable spot of spaces in floor, row, column queue order, it does nothing when spots already exist.
+func (p *parking) Seed(ctx context.Context, spaces [][][]int) error {
+	return p.inTx(ctx, func(tx *sql.Tx) error {
+		var count int
+		if err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM spots").Scan(&count); err != nil {
+			return err
+		}
+		if count > 0 {
+			return nil
+		}
+
+		stmt, err := tx.PrepareContext(ctx, p.dialect.rebind("INSERT INTO spots (floor, row_no, col_no, vehicle_type, occupied, queue_seq) VALUES (?, ?, ?, ?, FALSE, ?)"))
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+
+		var seq int64
+		for floor := range spaces {
+			for row := range spaces[floor] {
+				for col, spot := range spaces[floor][row] {
+					switch parkingentity.VehicleType(spot) {
+					case parkingentity.B1, parkingentity.M1, parkingentity.A1:
+					default:
+						continue
+					}
+
+					seq++
+					if _, err := stmt.ExecContext(ctx, floor, row, col, spot, seq); err != nil {
+						return err
+					}
+				}
+			}
+		}
+
+		_, err = tx.ExecContext(ctx, p.dialect.rebind("UPDATE counters SET value = ? WHERE name = 'queue_seq'"), seq)
+		return err
+	})
+}
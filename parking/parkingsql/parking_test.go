@@ -0,0 +1,214 @@
+package parkingsql_test
+
+import (
+	"database/sql"
+	_ "github.com/mattn/go-sqlite3"
+	parkingpkg "github.com/mtfiqh/DoiT-parking-system/parking"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingsql"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingtest"
+	"github.com/mtfiqh/DoiT-parking-system/pkg/clockx"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var (
+	a1 = int(parkingentity.A1)
+	b1 = int(parkingentity.B1)
+	m1 = int(parkingentity.M1)
+	x0 = int(parkingentity.X0)
+)
+
+func newSQLitePark(t *testing.T, spaces [][][]int, opts ...parkingsql.ParkOption) (parkingpkg.ParkingSystem, *sql.DB) {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", "file:"+filepath.Join(t.TempDir(), "parking.db")+"?_txlock=immediate&_busy_timeout=5000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	opts = append([]parkingsql.ParkOption{parkingsql.WithDialect(parkingsql.SQLite), parkingsql.WithSpaces(spaces)}, opts...)
+	park, err := parkingsql.NewPark(db, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return park, db
+}
+
+func TestSeed(t *testing.T) {
+	park, db := newSQLitePark(t, [][][]int{
+		{{a1, b1, a1}, {m1, x0, a1}},
+		{{a1, m1, b1}},
+	})
+
+	t.Run("seeded in floor, row, column order", func(t *testing.T) {
+		total, spots := park.AvailableSpot(parkingentity.A1)
+		expected := []parkingentity.Spot{{Floor: 0, Row: 0, Col: 0}, {Floor: 0, Row: 0, Col: 2}, {Floor: 0, Row: 1, Col: 2}, {Floor: 1, Row: 0, Col: 0}}
+		if total != len(expected) || !reflect.DeepEqual(spots, expected) {
+			t.Fatalf("Expected %d A1 spots %v, got %d %v", len(expected), expected, total, spots)
+		}
+	})
+
+	t.Run("migrate and seed are idempotent", func(t *testing.T) {
+		again, err := parkingsql.NewPark(db, parkingsql.WithDialect(parkingsql.SQLite), parkingsql.WithSpaces([][][]int{{{a1}}}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if total, _ := again.AvailableSpot(parkingentity.A1); total != 4 {
+			t.Errorf("Expected 4 A1 spots, got %d", total)
+		}
+	})
+}
+
+func TestConformance(t *testing.T) {
+	parkingtest.RunConformance(t, func() parkingpkg.ParkingSystem {
+		park, _ := newSQLitePark(t, [][][]int{
+			{{a1, b1, m1, x0}, {a1, b1, m1, a1}},
+			{{m1, b1, a1, x0}},
+		})
+		return park
+	})
+}
+
+func TestSpotQueryConformance(t *testing.T) {
+	parkingtest.RunSpotQueryConformance(t, func() parkingpkg.ParkingSystem {
+		park, _ := newSQLitePark(t, [][][]int{
+			{{a1, a1, b1, a1}, {a1, m1, a1, a1}, {a1, a1, x0, a1}},
+			{{a1, b1, a1}, {m1, a1, a1}},
+		})
+		return park
+	})
+}
+
+func TestSessionConformance(t *testing.T) {
+	parkingtest.RunSessionConformance(t, func(clock clockx.Clock) parkingpkg.ParkingSystem {
+		park, _ := newSQLitePark(t, [][][]int{{{a1, b1, m1}}}, parkingsql.WithClock(clock))
+		return park
+	})
+}
+
+func TestLostTicketConformance(t *testing.T) {
+	parkingtest.RunLostTicketConformance(t, func(clock clockx.Clock, biller parkingpkg.Biller) parkingpkg.ParkingSystem {
+		park, _ := newSQLitePark(t, [][][]int{{{a1, b1, m1}}}, parkingsql.WithClock(clock), parkingsql.WithBilling(biller))
+		return park
+	})
+}
+
+func TestFallbackConformance(t *testing.T) {
+	parkingtest.RunFallbackConformance(t, func(fallback parkingentity.Fallback) parkingpkg.ParkingSystem {
+		park, _ := newSQLitePark(t, [][][]int{{{a1, b1, m1}, {m1, b1, a1}}}, parkingsql.WithFallback(fallback))
+		return park
+	})
+}
+
+func TestGates(t *testing.T) {
+	entry := parkingentity.Gate{ID: "west", Kind: parkingentity.GateEntry}
+	exit := parkingentity.Gate{ID: "south", Kind: parkingentity.GateExit}
+	p, _ := newSQLitePark(t, [][][]int{{{a1, b1, m1}}}, parkingsql.WithGates(entry, exit))
+	park := p.(parkingpkg.GateParker)
+
+	spotID, err := park.ParkAt("west", parkingentity.A1, "1000")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := park.ParkAt("south", parkingentity.B1, "2000"); err != parkingentity.ErrGateDirection {
+		t.Errorf("Expected %v entering at an exit, got %v", parkingentity.ErrGateDirection, err)
+	}
+	if _, err := park.ParkAt("north", parkingentity.B1, "2000"); err != parkingentity.ErrUnknownGate {
+		t.Errorf("Expected %v, got %v", parkingentity.ErrUnknownGate, err)
+	}
+	if _, err := park.UnparkAt("west", spotID.ID(), "1000"); err != parkingentity.ErrGateDirection {
+		t.Errorf("Expected %v leaving at an entry, got %v", parkingentity.ErrGateDirection, err)
+	}
+	if _, err := p.(parkingpkg.LostTicketUnparker).UnparkLostTicket("west", "1000"); err != parkingentity.ErrGateDirection {
+		t.Errorf("Expected %v leaving without a ticket at an entry, got %v", parkingentity.ErrGateDirection, err)
+	}
+
+	receipt, err := park.UnparkAt("south", spotID.ID(), "1000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if receipt.Session.Gate != "west" || receipt.Session.ExitGate != "south" {
+		t.Errorf("Expected a receipt from west to south, got %+v", receipt.Session)
+	}
+
+	sessions, _ := p.(parkingpkg.SessionHistory).History("1000")
+	if len(sessions) != 1 || sessions[0].Gate != "west" || sessions[0].ExitGate != "south" {
+		t.Errorf("Expected a session from west to south, got %+v", sessions)
+	}
+}
+
+func TestMigrateVehicleNumberToPlate(t *testing.T) {
+	db, err := sql.Open("sqlite3", "file:"+filepath.Join(t.TempDir(), "parking.db")+"?_txlock=immediate&_busy_timeout=5000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// a database migrated while vehicle numbers were ints
+	schema, err := os.ReadFile("migrations/00001_create_parking.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stmts := strings.Split(strings.ReplaceAll(string(schema), "{{serial}}", "INTEGER PRIMARY KEY AUTOINCREMENT"), ";")
+	stmts = append(stmts,
+		"CREATE TABLE schema_migrations (version BIGINT NOT NULL PRIMARY KEY)",
+		"INSERT INTO schema_migrations (version) VALUES (1)",
+		"INSERT INTO spots (floor, row_no, col_no, vehicle_type, occupied, queue_seq) VALUES (0, 0, 0, 2, TRUE, 1), (0, 0, 1, 2, FALSE, 2)",
+		"INSERT INTO vehicles (vehicle_number, vehicle_type, floor, row_no, col_no, still_parked) VALUES (1234, 2, 0, 0, 0, TRUE)",
+		"INSERT INTO parking_sessions (vehicle_number, vehicle_type, floor, row_no, col_no, parked_at) VALUES (1234, 2, 0, 0, 0, '2025-01-01 08:00:00')",
+		// -42 is looked up as the normalized plate 42, and 42 left before it parked
+		"INSERT INTO spots (floor, row_no, col_no, vehicle_type, occupied, queue_seq) VALUES (0, 1, 0, 2, TRUE, 3)",
+		"INSERT INTO vehicles (vehicle_number, vehicle_type, floor, row_no, col_no, still_parked) VALUES (-42, 2, 0, 1, 0, TRUE), (42, 2, 0, 0, 1, FALSE)",
+		"INSERT INTO parking_sessions (vehicle_number, vehicle_type, floor, row_no, col_no, parked_at, unparked_at) VALUES (42, 2, 0, 0, 1, '2025-01-01 07:00:00', '2025-01-01 07:30:00')",
+		"INSERT INTO parking_sessions (vehicle_number, vehicle_type, floor, row_no, col_no, parked_at) VALUES (-42, 2, 0, 1, 0, '2025-01-01 08:00:00')",
+	)
+	for _, stmt := range stmts {
+		if strings.TrimSpace(stmt) == "" {
+			continue
+		}
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	park, err := parkingsql.NewPark(db, parkingsql.WithDialect(parkingsql.SQLite))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spotID, err := park.SearchVehicle("1234")
+	if err != nil || spotID.ID() != "0-0-0" {
+		t.Fatalf("Expected vehicle 1234 at 0-0-0, got %v, %v", spotID, err)
+	}
+
+	sessions, err := park.(parkingpkg.SessionHistory).History("1234")
+	if err != nil || len(sessions) != 1 || sessions[0].VehicleNumber != "1234" {
+		t.Fatalf("Expected the session of vehicle 1234, got %+v, %v", sessions, err)
+	}
+
+	spotID, err = park.SearchVehicle(parkingentity.PlateFromNumber(-42))
+	if err != nil || spotID.ID() != "0-1-0" {
+		t.Fatalf("Expected vehicle -42 at 0-1-0, got %v, %v", spotID, err)
+	}
+	sessions, err = park.(parkingpkg.SessionHistory).History("42")
+	if err != nil || len(sessions) != 2 {
+		t.Fatalf("Expected both sessions of vehicle 42, got %+v, %v", sessions, err)
+	}
+	if _, err := park.Unpark("0-1-0", "42"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := park.Unpark("0-0-0", "1234"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := park.Park(parkingentity.A1, "B 1234 XYZ"); err != nil {
+		t.Fatal(err)
+	}
+}
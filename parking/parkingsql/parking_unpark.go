@@ -0,0 +1,139 @@
+package parkingsql
+
+import (
+	"context"
+	"database/sql"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
+)
+
+func (p *parking) Unpark(spotID string, vehicleNumber parkingentity.Plate) (*parkingentity.Receipt, error) {
+	return p.unpark("", spotID, vehicleNumber, false)
+}
+
+func (p *parking) UnparkAt(gateID string, spotID string, vehicleNumber parkingentity.Plate) (*parkingentity.Receipt, error) {
+	gate, err := p.gate(gateID)
+	if err != nil {
+		return nil, err
+	}
+	if !gate.Exit() {
+		return nil, parkingentity.ErrGateDirection
+	}
+
+	return p.unpark(gate.ID, spotID, vehicleNumber, false)
+}
+
+func (p *parking) UnparkLostTicket(gateID string, vehicleNumber parkingentity.Plate) (*parkingentity.Receipt, error) {
+	if gateID == "" {
+		return p.unpark("", "", vehicleNumber, true)
+	}
+
+	gate, err := p.gate(gateID)
+	if err != nil {
+		return nil, err
+	}
+	if !gate.Exit() {
+		return nil, parkingentity.ErrGateDirection
+	}
+
+	return p.unpark(gate.ID, "", vehicleNumber, true)
+}
+
+// unpark unparks the vehicle leaving at the gate, empty when unknown.
+// Without its ticket the vehicle is found by its plate instead of the spot ID, and charged the lost ticket penalty.
+func (p *parking) unpark(gate string, spotID string, vehicleNumber parkingentity.Plate, lostTicket bool) (*parkingentity.Receipt, error) {
+	vehicleNumber = vehicleNumber.Normalize()
+
+	var (
+		id  parkingentity.SpotID
+		err error
+	)
+	if !lostTicket {
+		id, err = p.spotFormat.Parse(spotID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ctx := context.Background()
+	var receipt parkingentity.Receipt
+
+	err = p.inTx(ctx, func(tx *sql.Tx) error {
+		if !lostTicket {
+			var spots int
+			err := tx.QueryRowContext(ctx, p.dialect.rebind("SELECT COUNT(*) FROM spots WHERE floor = ? AND row_no = ? AND col_no = ?"), id.Floor, id.Row, id.Col).Scan(&spots)
+			if err != nil {
+				return err
+			}
+			if spots == 0 {
+				return parkingentity.ErrSpotOutOfRange
+			}
+		}
+
+		var (
+			vehicleSpot parkingentity.VehicleSpot
+			stillParked bool
+		)
+		err := tx.QueryRowContext(ctx, p.dialect.rebind("SELECT vehicle_type, floor, row_no, col_no, still_parked FROM vehicles WHERE vehicle_number = ?"+p.dialect.lockRow), vehicleNumber).
+			Scan(&vehicleSpot.Type, &vehicleSpot.Floor, &vehicleSpot.Row, &vehicleSpot.Col, &stillParked)
+		if err == sql.ErrNoRows {
+			return parkingentity.ErrVehicleNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		if !stillParked {
+			return parkingentity.ErrVehicleNotFound
+		}
+		if !lostTicket && vehicleSpot.SpotID != id {
+			return parkingentity.ErrVehicleNotAtSpot
+		}
+
+		now := p.clock.Now().UTC()
+		session := parkingentity.Session{VehicleNumber: vehicleNumber, VehicleType: vehicleSpot.Type, SpotID: vehicleSpot.SpotID, EntryAt: now, ExitAt: now, ExitGate: gate, LostTicket: lostTicket}
+		err = tx.QueryRowContext(ctx, p.dialect.rebind("SELECT parked_at, gate FROM parking_sessions WHERE vehicle_number = ? AND unparked_at IS NULL"), vehicleNumber).Scan(&session.EntryAt, &session.Gate)
+		if err != nil && err != sql.ErrNoRows {
+			return err
+		}
+
+		// charge before anything changes, a failed charge keeps the vehicle parked
+		receipt, err = p.charge(session)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, p.dialect.rebind("UPDATE vehicles SET still_parked = FALSE WHERE vehicle_number = ?"), vehicleNumber)
+		if err != nil {
+			return err
+		}
+
+		// the freed spot goes to the tail of the queue
+		var seq int64
+		err = tx.QueryRowContext(ctx, "UPDATE counters SET value = value + 1 WHERE name = 'queue_seq' RETURNING value").Scan(&seq)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, p.dialect.rebind("UPDATE spots SET occupied = FALSE, queue_seq = ? WHERE floor = ? AND row_no = ? AND col_no = ?"), seq, vehicleSpot.Floor, vehicleSpot.Row, vehicleSpot.Col)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, p.dialect.rebind("UPDATE parking_sessions SET unparked_at = ?, exit_gate = ?, lost_ticket = ? WHERE vehicle_number = ? AND unparked_at IS NULL"), now, gate, lostTicket, vehicleNumber)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &receipt, nil
+}
+
+// charge computes the receipt of a closed session, without a biller only the duration is filled.
+func (p *parking) charge(session parkingentity.Session) (parkingentity.Receipt, error) {
+	if p.biller == nil {
+		return parkingentity.Receipt{Session: session, Duration: session.ExitAt.Sub(session.EntryAt)}, nil
+	}
+
+	return p.biller.Charge(session)
+}
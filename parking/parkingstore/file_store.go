@@ -0,0 +1,319 @@
+package parkingstore
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
+	"github.com/pkg/errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const (
+	snapshotFile = "snapshot.gob"
+	logFile      = "wal.log"
+)
+
+// FileStore is a Store keeping a gob snapshot and an append-only JSON lines log in a directory.
+type FileStore struct {
+	dir string
+	log *os.File
+	seq uint64
+
+	// snapshotSeq is the LastSeq of the stored snapshot, snapshots are encoded and written one at a time
+	// under snapshotMutex, so Append only waits for the log to be compacted.
+	snapshotSeq   uint64
+	snapshotMutex *sync.Mutex
+
+	mutex *sync.Mutex
+}
+
+// OpenFileStore opens or creates a file store in dir, Load must be called before the first Append.
+func OpenFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(filepath.Join(dir, logFile), os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileStore{
+		dir:           dir,
+		log:           f,
+		snapshotMutex: new(sync.Mutex),
+		mutex:         new(sync.Mutex),
+	}, nil
+}
+
+func (s *FileStore) Load() (*State, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	state, err := s.readSnapshot()
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := s.readLog()
+	if err != nil {
+		return nil, err
+	}
+
+	if state == nil {
+		if len(records) > 0 {
+			return nil, errors.New("log has records but there is no snapshot to replay them on")
+		}
+		return nil, nil
+	}
+
+	s.seq = state.LastSeq
+	s.snapshotSeq = state.LastSeq
+	for _, r := range records {
+		if err := state.Apply(r); err != nil {
+			return nil, err
+		}
+		if r.Seq > s.seq {
+			s.seq = r.Seq
+		}
+	}
+
+	return state, nil
+}
+
+func (s *FileStore) Append(r Record) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	r.Seq = s.seq + 1
+	line, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	// a single write so a crash leaves at most one truncated record at the end
+	if _, err := s.log.Write(append(line, '\n')); err != nil {
+		return errors.Wrap(err, "appending record")
+	}
+	// the operation is acknowledged once Append returns, so the record must survive an OS crash
+	if err := s.log.Sync(); err != nil {
+		return errors.Wrap(err, "syncing record")
+	}
+
+	s.seq = r.Seq
+	return nil
+}
+
+func (s *FileStore) LastSeq() uint64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.seq
+}
+
+func (s *FileStore) Snapshot(state *State) error {
+	s.snapshotMutex.Lock()
+	defer s.snapshotMutex.Unlock()
+
+	if state.LastSeq < s.snapshotSeq {
+		// a newer state is stored already
+		return nil
+	}
+
+	tmp := filepath.Join(s.dir, snapshotFile+".tmp")
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(f)
+	if err := gob.NewEncoder(w).Encode(state); err != nil {
+		f.Close()
+		return errors.Wrap(err, "encoding snapshot")
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp, filepath.Join(s.dir, snapshotFile)); err != nil {
+		return err
+	}
+	s.snapshotSeq = state.LastSeq
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.compact(state.LastSeq)
+}
+
+// compact drops the records up to lastSeq from the log, they are in the snapshot now.
+// A crash before the compacted log replaces the old one only leaves records that are skipped on replay.
+// It must be called while holding the mutex.
+func (s *FileStore) compact(lastSeq uint64) error {
+	if s.seq == lastSeq {
+		// nothing was appended since the state was copied
+		if err := s.log.Truncate(0); err != nil {
+			return err
+		}
+		_, err := s.log.Seek(0, io.SeekStart)
+		return err
+	}
+
+	records, err := s.readLog()
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	for _, r := range records {
+		if r.Seq <= lastSeq {
+			continue
+		}
+
+		line, err := json.Marshal(r)
+		if err != nil {
+			return err
+		}
+		buf.Write(append(line, '\n'))
+	}
+
+	tmp := filepath.Join(s.dir, logFile+".tmp")
+	if err := writeFileSync(tmp, buf.Bytes()); err != nil {
+		return errors.Wrap(err, "compacting log")
+	}
+	if err := os.Rename(tmp, filepath.Join(s.dir, logFile)); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(filepath.Join(s.dir, logFile), os.O_RDWR, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Seek(0, io.SeekEnd); err != nil {
+		f.Close()
+		return err
+	}
+
+	s.log.Close()
+	s.log = f
+	return nil
+}
+
+// writeFileSync writes the file and syncs it to disk.
+func writeFileSync(path string, data []byte) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+func (s *FileStore) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.log.Sync(); err != nil {
+		return err
+	}
+	return s.log.Close()
+}
+
+func (s *FileStore) readSnapshot() (*State, error) {
+	data, err := os.ReadFile(filepath.Join(s.dir, snapshotFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	state := new(State)
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(state); err != nil {
+		legacy := new(stateV1)
+		if gob.NewDecoder(bytes.NewReader(data)).Decode(legacy) != nil {
+			return nil, errors.Wrap(err, "decoding snapshot")
+		}
+		state = legacy.migrate()
+	}
+
+	// gob leaves empty maps nil
+	if state.AvailableSpots == nil {
+		state.AvailableSpots = make(map[parkingentity.VehicleType][]parkingentity.Spot)
+	}
+	if state.VehiclesParked == nil {
+		state.VehiclesParked = make(map[parkingentity.Plate]parkingentity.VehicleSpot)
+	}
+
+	return state, nil
+}
+
+// readLog reads every complete record and cuts off a truncated last record left by a crash.
+func (s *FileStore) readLog() ([]Record, error) {
+	if _, err := s.log.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	data, err := io.ReadAll(s.log)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		records []Record
+		offset  int
+	)
+	for offset < len(data) {
+		end := bytes.IndexByte(data[offset:], '\n')
+		if end < 0 {
+			// last record was not fully written
+			break
+		}
+
+		var r Record
+		if err := json.Unmarshal(data[offset:offset+end], &r); err != nil {
+			if offset+end+1 == len(data) {
+				// last record was not fully written
+				break
+			}
+			return nil, errors.Wrapf(err, "corrupted record at offset %d", offset)
+		}
+
+		records = append(records, r)
+		offset += end + 1
+	}
+
+	if offset < len(data) {
+		if err := s.log.Truncate(int64(offset)); err != nil {
+			return nil, err
+		}
+	}
+
+	// continue appending after the last complete record
+	if _, err := s.log.Seek(int64(offset), io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
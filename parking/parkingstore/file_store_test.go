@@ -0,0 +1,312 @@
+package parkingstore_test
+
+import (
+	"encoding/gob"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingstore"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func newState() *parkingstore.State {
+	return &parkingstore.State{
+		Spaces: [][][]int{{{int(parkingentity.A1), int(parkingentity.A1), int(parkingentity.A1)}}},
+		AvailableSpots: map[parkingentity.VehicleType][]parkingentity.Spot{
+			parkingentity.A1: {{Col: 0}, {Col: 1}, {Col: 2}},
+		},
+		VehiclesParked: map[parkingentity.Plate]parkingentity.VehicleSpot{},
+	}
+}
+
+// exported drops the unexported replay index so states can be compared.
+func exported(s *parkingstore.State) parkingstore.State {
+	return parkingstore.State{
+		LastSeq:        s.LastSeq,
+		Spaces:         s.Spaces,
+		AvailableSpots: s.AvailableSpots,
+		VehiclesParked: s.VehiclesParked,
+		Sessions:       s.Sessions,
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	t0 := time.Date(2025, 1, 1, 8, 0, 0, 0, time.UTC)
+	dir := t.TempDir()
+
+	store, err := parkingstore.OpenFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	state, err := store.Load()
+	if err != nil || state != nil {
+		t.Fatalf("Expected an empty store, got %v, %v", state, err)
+	}
+
+	if err := store.Snapshot(newState()); err != nil {
+		t.Fatal(err)
+	}
+
+	records := []parkingstore.Record{
+		{Op: parkingstore.OpPark, VehicleNumber: "1", VehicleType: parkingentity.A1, Spot: parkingentity.Spot{Col: 0}, Time: t0},
+		{Op: parkingstore.OpPark, VehicleNumber: "2", VehicleType: parkingentity.A1, Spot: parkingentity.Spot{Col: 1}, Time: t0.Add(time.Minute)},
+		{Op: parkingstore.OpUnpark, VehicleNumber: "1", VehicleType: parkingentity.A1, Spot: parkingentity.Spot{Col: 0}, Time: t0.Add(time.Hour)},
+	}
+	for _, r := range records {
+		if err := store.Append(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &parkingstore.State{
+		LastSeq: 3,
+		Spaces:  newState().Spaces,
+		AvailableSpots: map[parkingentity.VehicleType][]parkingentity.Spot{
+			parkingentity.A1: {{Col: 2}, {Col: 0}},
+		},
+		VehiclesParked: map[parkingentity.Plate]parkingentity.VehicleSpot{
+			"1": {SpotID: parkingentity.SpotID{Col: 0}, Type: parkingentity.A1, StillParked: false},
+			"2": {SpotID: parkingentity.SpotID{Col: 1}, Type: parkingentity.A1, StillParked: true},
+		},
+		Sessions: []parkingentity.Session{
+			{VehicleNumber: "1", VehicleType: parkingentity.A1, SpotID: parkingentity.SpotID{Col: 0}, EntryAt: t0, ExitAt: t0.Add(time.Hour)},
+			{VehicleNumber: "2", VehicleType: parkingentity.A1, SpotID: parkingentity.SpotID{Col: 1}, EntryAt: t0.Add(time.Minute)},
+		},
+	}
+
+	reopen := func() *parkingstore.FileStore {
+		store, err := parkingstore.OpenFileStore(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { store.Close() })
+		return store
+	}
+
+	t.Run("replay log on snapshot", func(t *testing.T) {
+		state, err := reopen().Load()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(exported(state), exported(expected)) {
+			t.Errorf("Expected state %+v, got %+v", expected, state)
+		}
+	})
+
+	t.Run("tolerate truncated last record", func(t *testing.T) {
+		f, err := os.OpenFile(filepath.Join(dir, "wal.log"), os.O_APPEND|os.O_WRONLY, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.WriteString(`{"seq":4,"op":"park","vehicle_num`); err != nil {
+			t.Fatal(err)
+		}
+		f.Close()
+
+		store := reopen()
+		state, err := store.Load()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(exported(state), exported(expected)) {
+			t.Errorf("Expected state %+v, got %+v", expected, state)
+		}
+
+		// the truncated record is cut off, so new records continue the sequence
+		if err := store.Append(parkingstore.Record{Op: parkingstore.OpPark, VehicleNumber: "3", VehicleType: parkingentity.A1, Spot: parkingentity.Spot{Col: 2}}); err != nil {
+			t.Fatal(err)
+		}
+		store.Close()
+
+		state, err = reopen().Load()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if state.LastSeq != 4 || !state.VehiclesParked["3"].StillParked {
+			t.Errorf("Expected vehicle 3 parked with last seq 4, got %d, %+v", state.LastSeq, state.VehiclesParked["3"])
+		}
+	})
+
+	t.Run("skip records already in snapshot", func(t *testing.T) {
+		store := reopen()
+		state, err := store.Load()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		log, err := os.ReadFile(filepath.Join(dir, "wal.log"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := store.Snapshot(state); err != nil {
+			t.Fatal(err)
+		}
+		store.Close()
+
+		// simulate a crash between writing the snapshot and truncating the log
+		if err := os.WriteFile(filepath.Join(dir, "wal.log"), log, 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		recovered, err := reopen().Load()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(exported(recovered), exported(state)) {
+			t.Errorf("Expected state %+v, got %+v", state, recovered)
+		}
+	})
+
+	t.Run("keep records appended after the state was copied", func(t *testing.T) {
+		store := reopen()
+		state, err := store.Load()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if state.LastSeq != store.LastSeq() {
+			t.Fatalf("Expected the last seq %d of the state, got %d", state.LastSeq, store.LastSeq())
+		}
+		older := *state
+
+		// vehicle 2 leaves while the copied state is being stored
+		if err := store.Append(parkingstore.Record{Op: parkingstore.OpUnpark, VehicleNumber: "2", VehicleType: parkingentity.A1, Spot: parkingentity.Spot{Col: 1}, Time: t0.Add(2 * time.Hour)}); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.Snapshot(state); err != nil {
+			t.Fatal(err)
+		}
+
+		// a state copied before the stored one is ignored
+		older.LastSeq--
+		older.VehiclesParked = nil
+		if err := store.Snapshot(&older); err != nil {
+			t.Fatal(err)
+		}
+		store.Close()
+
+		recovered, err := reopen().Load()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if recovered.LastSeq != state.LastSeq+1 || recovered.VehiclesParked["2"].StillParked || !recovered.VehiclesParked["3"].StillParked {
+			t.Errorf("Expected vehicle 2 unparked after the snapshot, got %d, %+v", recovered.LastSeq, recovered.VehiclesParked)
+		}
+	})
+}
+
+func TestStateApplyFallback(t *testing.T) {
+	state := newState()
+
+	// a motorcycle overflowed into an A-1 spot, the spot goes back to the A-1 queue
+	for _, r := range []parkingstore.Record{
+		{Seq: 1, Op: parkingstore.OpPark, VehicleNumber: "1", VehicleType: parkingentity.M1, Spot: parkingentity.Spot{Col: 0}},
+		{Seq: 2, Op: parkingstore.OpUnpark, VehicleNumber: "1", VehicleType: parkingentity.M1, Spot: parkingentity.Spot{Col: 0}},
+	} {
+		if err := state.Apply(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := map[parkingentity.VehicleType][]parkingentity.Spot{
+		parkingentity.A1: {{Col: 1}, {Col: 2}, {Col: 0}},
+	}
+	if !reflect.DeepEqual(state.AvailableSpots, expected) {
+		t.Errorf("Expected available spots %v, got %v", expected, state.AvailableSpots)
+	}
+}
+
+func TestFileStoreLegacy(t *testing.T) {
+	t0 := time.Date(2025, 1, 1, 8, 0, 0, 0, time.UTC)
+	dir := t.TempDir()
+
+	// snapshot and log as written while vehicle numbers and types were ints
+	type vehicleSpot struct {
+		SpotID      parkingentity.SpotID
+		Type        uint
+		StillParked bool
+	}
+	type session struct {
+		VehicleNumber int
+		VehicleType   uint
+		SpotID        parkingentity.SpotID
+		EntryAt       time.Time
+		ExitAt        time.Time
+	}
+	snapshot := struct {
+		LastSeq        uint64
+		Spaces         [][][]int
+		AvailableSpots map[uint][]parkingentity.Spot
+		VehiclesParked map[int]vehicleSpot
+		Sessions       []session
+	}{
+		LastSeq: 1,
+		Spaces:  newState().Spaces,
+		AvailableSpots: map[uint][]parkingentity.Spot{
+			uint(parkingentity.A1): {{Col: 1}, {Col: 2}},
+		},
+		VehiclesParked: map[int]vehicleSpot{
+			1234: {SpotID: parkingentity.SpotID{Col: 0}, Type: uint(parkingentity.A1), StillParked: true},
+			// plates are normalized without the sign, like the lookups of -42
+			-42: {SpotID: parkingentity.SpotID{Row: 1}, Type: uint(parkingentity.A1), StillParked: true},
+		},
+		Sessions: []session{
+			{VehicleNumber: 1234, VehicleType: uint(parkingentity.A1), SpotID: parkingentity.SpotID{Col: 0}, EntryAt: t0},
+			{VehicleNumber: -42, VehicleType: uint(parkingentity.A1), SpotID: parkingentity.SpotID{Row: 1}, EntryAt: t0},
+		},
+	}
+
+	f, err := os.Create(filepath.Join(dir, "snapshot.gob"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := gob.NewEncoder(f).Encode(snapshot); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	log := `{"seq":2,"op":"park","vehicle_number":5678,"vehicle_type":2,"spot":{"Floor":0,"Col":1,"Row":0},"time":"2025-01-01T09:00:00Z"}` + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "wal.log"), []byte(log), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := parkingstore.OpenFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	state, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &parkingstore.State{
+		LastSeq: 2,
+		Spaces:  newState().Spaces,
+		AvailableSpots: map[parkingentity.VehicleType][]parkingentity.Spot{
+			parkingentity.A1: {{Col: 2}},
+		},
+		VehiclesParked: map[parkingentity.Plate]parkingentity.VehicleSpot{
+			"1234": {SpotID: parkingentity.SpotID{Col: 0}, Type: parkingentity.A1, StillParked: true},
+			"5678": {SpotID: parkingentity.SpotID{Col: 1}, Type: parkingentity.A1, StillParked: true},
+			"42":   {SpotID: parkingentity.SpotID{Row: 1}, Type: parkingentity.A1, StillParked: true},
+		},
+		Sessions: []parkingentity.Session{
+			{VehicleNumber: "1234", VehicleType: parkingentity.A1, SpotID: parkingentity.SpotID{Col: 0}, EntryAt: t0},
+			{VehicleNumber: "42", VehicleType: parkingentity.A1, SpotID: parkingentity.SpotID{Row: 1}, EntryAt: t0},
+			{VehicleNumber: "5678", VehicleType: parkingentity.A1, SpotID: parkingentity.SpotID{Col: 1}, EntryAt: t0.Add(time.Hour)},
+		},
+	}
+	if !reflect.DeepEqual(exported(state), exported(expected)) {
+		t.Errorf("Expected state %+v, got %+v", expected, state)
+	}
+}
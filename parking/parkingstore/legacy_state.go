@@ -0,0 +1,73 @@
+package parkingstore
+
+import (
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
+	"time"
+)
+
+// stateV1 is the snapshot written before vehicles were identified by plate, vehicle numbers were ints
+// and vehicle types were encoded as numbers instead of their codes.
+type stateV1 struct {
+	LastSeq        uint64
+	Spaces         [][][]int
+	AvailableSpots map[uint][]parkingentity.Spot
+	VehiclesParked map[int]vehicleSpotV1
+	Sessions       []sessionV1
+}
+
+type vehicleSpotV1 struct {
+	SpotID      parkingentity.SpotID
+	Type        uint
+	StillParked bool
+}
+
+type sessionV1 struct {
+	VehicleNumber int
+	VehicleType   uint
+	SpotID        parkingentity.SpotID
+	Gate          string
+	EntryAt       time.Time
+	ExitAt        time.Time
+}
+
+// migrate converts the vehicle numbers to plates.
+func (s *stateV1) migrate() *State {
+	state := &State{
+		LastSeq:        s.LastSeq,
+		Spaces:         s.Spaces,
+		AvailableSpots: make(map[parkingentity.VehicleType][]parkingentity.Spot, len(s.AvailableSpots)),
+		VehiclesParked: make(map[parkingentity.Plate]parkingentity.VehicleSpot, len(s.VehiclesParked)),
+		Sessions:       make([]parkingentity.Session, 0, len(s.Sessions)),
+	}
+
+	for vehicleType, spots := range s.AvailableSpots {
+		state.AvailableSpots[parkingentity.VehicleType(vehicleType)] = spots
+	}
+
+	for vehicleNumber, vehicleSpot := range s.VehiclesParked {
+		// a number and its negative are the same plate, the vehicle still parked wins
+		plate := parkingentity.PlateFromNumber(vehicleNumber)
+		if migrated, ok := state.VehiclesParked[plate]; ok && (migrated.StillParked || !vehicleSpot.StillParked) {
+			continue
+		}
+
+		state.VehiclesParked[plate] = parkingentity.VehicleSpot{
+			SpotID:      vehicleSpot.SpotID,
+			Type:        parkingentity.VehicleType(vehicleSpot.Type),
+			StillParked: vehicleSpot.StillParked,
+		}
+	}
+
+	for _, session := range s.Sessions {
+		state.Sessions = append(state.Sessions, parkingentity.Session{
+			VehicleNumber: parkingentity.PlateFromNumber(session.VehicleNumber),
+			VehicleType:   parkingentity.VehicleType(session.VehicleType),
+			SpotID:        session.SpotID,
+			Gate:          session.Gate,
+			EntryAt:       session.EntryAt,
+			ExitAt:        session.ExitAt,
+		})
+	}
+
+	return state
+}
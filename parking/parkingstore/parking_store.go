@@ -0,0 +1,174 @@
+package parkingstore
+
+import (
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
+	"github.com/pkg/errors"
+	"time"
+)
+
+var ErrUnknownOp = errors.New("unknown record operation")
+
+// Op is the operation of a logged record.
+type Op string
+
+const (
+	OpPark   Op = "park"
+	OpUnpark Op = "unpark"
+	// OpReserve holds a spot for the vehicle until ExpiresAt, a later OpPark of the vehicle claims it.
+	OpReserve Op = "reserve"
+	// OpRelease returns the held spot of an expired or cancelled reservation to the queue.
+	OpRelease Op = "release"
+	// OpSpotType changes the type of a free spot to VehicleType, X-0 disables it.
+	OpSpotType Op = "spot_type"
+)
+
+// Record is a single parking operation appended to the log.
+type Record struct {
+	Seq           uint64                    `json:"seq"`
+	Op            Op                        `json:"op"`
+	VehicleNumber parkingentity.Plate       `json:"vehicle_number"`
+	VehicleType   parkingentity.VehicleType `json:"vehicle_type"`
+	Spot          parkingentity.Spot        `json:"spot"`
+	Gate          string                    `json:"gate,omitempty"`
+	Time          time.Time                 `json:"time"`
+	ExpiresAt     time.Time                 `json:"expires_at,omitempty"`
+	// LostTicket is set on the unpark of a vehicle that left without its ticket.
+	LostTicket bool `json:"lost_ticket,omitempty"`
+}
+
+// State is everything needed to rebuild a parking lot.
+type State struct {
+	// LastSeq is the sequence of the last record included in the state.
+	LastSeq        uint64
+	Spaces         [][][]int
+	AvailableSpots map[parkingentity.VehicleType][]parkingentity.Spot // queue order, head first
+	VehiclesParked map[parkingentity.Plate]parkingentity.VehicleSpot
+	// Sessions holds every parking session, oldest first.
+	Sessions []parkingentity.Session
+	// Reservations holds the spots held for pre-booked vehicles.
+	Reservations map[parkingentity.Plate]parkingentity.Reservation
+
+	// openSessions indexes the session of each parked vehicle while replaying.
+	openSessions map[parkingentity.Plate]int
+}
+
+// Store persists the state of a parking lot as snapshots plus a log of the records after them.
+type Store interface {
+	// Load returns the last snapshot with the logged records replayed on it, or nil when nothing is stored yet.
+	Load() (*State, error)
+	// Append logs a record and assigns its sequence, the record is on disk when it returns.
+	Append(r Record) error
+	// LastSeq returns the sequence of the last appended record, the LastSeq of a state copied now.
+	LastSeq() uint64
+	// Snapshot stores the full state, the records up to its LastSeq are no longer needed and the later ones are kept,
+	// so a state copied while holding the lot's lock can be stored after releasing it.
+	// A state older than the stored snapshot is ignored.
+	Snapshot(s *State) error
+	Close() error
+}
+
+// Apply replays a record on the state.
+func (s *State) Apply(r Record) error {
+	if r.Seq != 0 && r.Seq <= s.LastSeq {
+		// already part of the snapshot
+		return nil
+	}
+
+	switch r.Op {
+	case OpPark:
+		spotType := s.spotType(r)
+		s.AvailableSpots[spotType] = removeSpot(s.AvailableSpots[spotType], r.Spot)
+		s.VehiclesParked[r.VehicleNumber] = parkingentity.VehicleSpot{
+			SpotID:      parkingentity.SpotID(r.Spot),
+			Type:        r.VehicleType,
+			StillParked: true,
+		}
+		s.Sessions = append(s.Sessions, parkingentity.Session{
+			VehicleNumber: r.VehicleNumber,
+			VehicleType:   r.VehicleType,
+			SpotID:        parkingentity.SpotID(r.Spot),
+			Gate:          r.Gate,
+			EntryAt:       r.Time,
+		})
+		s.sessionIndex()[r.VehicleNumber] = len(s.Sessions) - 1
+		delete(s.Reservations, r.VehicleNumber)
+	case OpUnpark:
+		vehicleSpot := s.VehiclesParked[r.VehicleNumber]
+		vehicleSpot.StillParked = false
+		s.VehiclesParked[r.VehicleNumber] = vehicleSpot
+		spotType := s.spotType(r)
+		s.AvailableSpots[spotType] = append(s.AvailableSpots[spotType], r.Spot)
+		if i, ok := s.sessionIndex()[r.VehicleNumber]; ok {
+			s.Sessions[i].ExitAt = r.Time
+			s.Sessions[i].ExitGate = r.Gate
+			s.Sessions[i].LostTicket = r.LostTicket
+			delete(s.openSessions, r.VehicleNumber)
+		}
+	case OpReserve:
+		s.AvailableSpots[r.VehicleType] = removeSpot(s.AvailableSpots[r.VehicleType], r.Spot)
+		if s.Reservations == nil {
+			s.Reservations = make(map[parkingentity.Plate]parkingentity.Reservation)
+		}
+		s.Reservations[r.VehicleNumber] = parkingentity.Reservation{
+			VehicleNumber: r.VehicleNumber,
+			VehicleType:   r.VehicleType,
+			SpotID:        parkingentity.SpotID(r.Spot),
+			ReservedAt:    r.Time,
+			ExpiresAt:     r.ExpiresAt,
+		}
+	case OpRelease:
+		delete(s.Reservations, r.VehicleNumber)
+		s.AvailableSpots[r.VehicleType] = append(s.AvailableSpots[r.VehicleType], r.Spot)
+	case OpSpotType:
+		spotType := s.spotType(r)
+		s.AvailableSpots[spotType] = removeSpot(s.AvailableSpots[spotType], r.Spot)
+		if r.VehicleType != parkingentity.X0 {
+			s.AvailableSpots[r.VehicleType] = append(s.AvailableSpots[r.VehicleType], r.Spot)
+		}
+		s.Spaces[r.Spot.Floor][r.Spot.Row][r.Spot.Col] = int(r.VehicleType)
+	default:
+		return errors.Wrapf(ErrUnknownOp, "record %d: %q", r.Seq, r.Op)
+	}
+
+	s.LastSeq = r.Seq
+	return nil
+}
+
+// spotType returns the type the spot of the record is laid out for, a vehicle may have overflowed into it.
+func (s *State) spotType(r Record) parkingentity.VehicleType {
+	spot := r.Spot
+	if spot.Floor < len(s.Spaces) && spot.Row < len(s.Spaces[spot.Floor]) && spot.Col < len(s.Spaces[spot.Floor][spot.Row]) {
+		return parkingentity.VehicleType(s.Spaces[spot.Floor][spot.Row][spot.Col])
+	}
+
+	return r.VehicleType
+}
+
+// sessionIndex returns the index of the open session of each parked vehicle, it's built on first use.
+func (s *State) sessionIndex() map[parkingentity.Plate]int {
+	if s.openSessions == nil {
+		s.openSessions = make(map[parkingentity.Plate]int)
+		for i, session := range s.Sessions {
+			if session.Active() {
+				s.openSessions[session.VehicleNumber] = i
+			}
+		}
+	}
+
+	return s.openSessions
+}
+
+// removeSpot removes a spot from the queue, spots are dequeued from the head so it's checked first.
+func removeSpot(spots []parkingentity.Spot, spot parkingentity.Spot) []parkingentity.Spot {
+	if len(spots) > 0 && spots[0] == spot {
+		return spots[1:]
+	}
+
+	for i := range spots {
+		if spots[i] == spot {
+			return append(spots[:i], spots[i+1:]...)
+		}
+	}
+
+	return spots
+}
@@ -0,0 +1,433 @@
+// Package parkingtest verifies ParkingSystem implementations against the same contract.
+package parkingtest
+
+import (
+	"errors"
+	parkingpkg "github.com/mtfiqh/DoiT-parking-system/parking"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// vehicleTypes are the vehicle types that can be parked.
+var vehicleTypes = []parkingentity.VehicleType{parkingentity.M1, parkingentity.B1, parkingentity.A1}
+
+// RunConformance runs the parking system contract against the implementation built by factory.
+// Every call to factory must return a new, empty lot with at least two free spots of every vehicle type.
+func RunConformance(t *testing.T, factory func() parkingpkg.ParkingSystem) {
+	t.Run("park", func(t *testing.T) { testPark(t, factory) })
+	t.Run("unpark", func(t *testing.T) { testUnpark(t, factory) })
+	t.Run("search vehicle", func(t *testing.T) { testSearchVehicle(t, factory) })
+	t.Run("available spot", func(t *testing.T) { testAvailableSpot(t, factory) })
+	t.Run("plate", func(t *testing.T) { testPlate(t, factory) })
+	t.Run("concurrent gates", func(t *testing.T) { testConcurrentGates(t, factory) })
+}
+
+func expectErr(t *testing.T, err, expected error) {
+	t.Helper()
+
+	if !errors.Is(err, expected) {
+		t.Errorf("Expected error %v, got %v", expected, err)
+	}
+}
+
+func testPark(t *testing.T, factory func() parkingpkg.ParkingSystem) {
+	t.Run("takes the head of the available spots", func(t *testing.T) {
+		park := factory()
+
+		for i, vehicleType := range vehicleTypes {
+			total, spots := park.AvailableSpot(vehicleType)
+
+			spotID, err := park.Park(vehicleType, parkingentity.PlateFromNumber(1000+i))
+			if err != nil {
+				t.Fatalf("Failed to park %v vehicle: %v", vehicleType, err)
+			}
+
+			if parkingentity.Spot(*spotID) != spots[0] {
+				t.Errorf("Expected %v vehicle at the head spot %v, got %v", vehicleType, spots[0], *spotID)
+			}
+
+			if after, _ := park.AvailableSpot(vehicleType); after != total-1 {
+				t.Errorf("Expected %d available %v spots after parking, got %d", total-1, vehicleType, after)
+			}
+		}
+	})
+
+	t.Run("vehicle already parked", func(t *testing.T) {
+		park := factory()
+
+		if _, err := park.Park(parkingentity.A1, "1000"); err != nil {
+			t.Fatal(err)
+		}
+
+		total, _ := park.AvailableSpot(parkingentity.B1)
+		_, err := park.Park(parkingentity.B1, "1000")
+		expectErr(t, err, parkingentity.ErrVehicleAlreadyParked)
+
+		if after, _ := park.AvailableSpot(parkingentity.B1); after != total {
+			t.Errorf("Expected a rejected park to keep %d available spots, got %d", total, after)
+		}
+	})
+
+	t.Run("invalid vehicle type", func(t *testing.T) {
+		park := factory()
+
+		_, err := park.Park(parkingentity.X0, "1000")
+		expectErr(t, err, parkingentity.ErrInvalidVehicleType)
+	})
+
+	t.Run("until full", func(t *testing.T) {
+		park := factory()
+
+		for _, vehicleType := range vehicleTypes {
+			total, _ := park.AvailableSpot(vehicleType)
+			base := 10000 * (int(vehicleType) + 1)
+
+			for i := 0; i < total; i++ {
+				if _, err := park.Park(vehicleType, parkingentity.PlateFromNumber(base+i)); err != nil {
+					t.Fatalf("Failed to park %v vehicle %d of %d: %v", vehicleType, i+1, total, err)
+				}
+			}
+
+			_, err := park.Park(vehicleType, parkingentity.PlateFromNumber(base+total))
+			expectErr(t, err, parkingentity.ErrSpotNotFound)
+
+			if after, spots := park.AvailableSpot(vehicleType); after != 0 || len(spots) != 0 {
+				t.Errorf("Expected no available %v spots, got %d", vehicleType, after)
+			}
+		}
+	})
+}
+
+func testUnpark(t *testing.T, factory func() parkingpkg.ParkingSystem) {
+	park := factory()
+
+	spotID, err := park.Park(parkingentity.A1, "1000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := park.Park(parkingentity.A1, "1001")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("vehicle not parked", func(t *testing.T) {
+		_, err := park.Unpark(spotID.ID(), "9999")
+		expectErr(t, err, parkingentity.ErrVehicleNotFound)
+	})
+
+	t.Run("spot of another vehicle", func(t *testing.T) {
+		_, err := park.Unpark(other.ID(), "1000")
+		expectErr(t, err, parkingentity.ErrVehicleNotAtSpot)
+	})
+
+	t.Run("malformed spot id", func(t *testing.T) {
+		for _, id := range []string{"", "1-2", "1-2-3-4", "a-b-c", "1--2", "-1-2-3", "1-2-+3"} {
+			_, err := park.Unpark(id, "1000")
+			expectErr(t, err, parkingentity.ErrMalformedSpotID)
+		}
+	})
+
+	t.Run("spot out of range", func(t *testing.T) {
+		_, err := park.Unpark("999-999-999", "1000")
+		expectErr(t, err, parkingentity.ErrSpotOutOfRange)
+	})
+
+	t.Run("returns the spot to the tail", func(t *testing.T) {
+		total, _ := park.AvailableSpot(parkingentity.A1)
+
+		receipt, err := park.Unpark(spotID.ID(), "1000")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if receipt == nil || receipt.Session.VehicleNumber != "1000" || receipt.Session.SpotID != *spotID || receipt.Session.Active() {
+			t.Errorf("Expected a receipt of the closed session at %s, got %+v", spotID.ID(), receipt)
+		}
+
+		after, spots := park.AvailableSpot(parkingentity.A1)
+		if after != total+1 {
+			t.Errorf("Expected %d available spots after unparking, got %d", total+1, after)
+		}
+		if spots[len(spots)-1] != parkingentity.Spot(*spotID) {
+			t.Errorf("Expected spot %s at the tail, got %v", spotID.ID(), spots[len(spots)-1])
+		}
+	})
+
+	t.Run("twice", func(t *testing.T) {
+		_, err := park.Unpark(spotID.ID(), "1000")
+		expectErr(t, err, parkingentity.ErrVehicleNotFound)
+	})
+
+	t.Run("park again", func(t *testing.T) {
+		if _, err := park.Park(parkingentity.A1, "1000"); err != nil {
+			t.Errorf("Expected an unparked vehicle to park again, got %v", err)
+		}
+	})
+}
+
+func testSearchVehicle(t *testing.T, factory func() parkingpkg.ParkingSystem) {
+	park := factory()
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := park.SearchVehicle("9999")
+		expectErr(t, err, parkingentity.ErrVehicleNotFound)
+	})
+
+	t.Run("parked", func(t *testing.T) {
+		spotID, err := park.Park(parkingentity.M1, "1000")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		found, err := park.SearchVehicle("1000")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if found.ID() != spotID.ID() {
+			t.Errorf("Expected spot %s, got %s", spotID.ID(), found.ID())
+		}
+
+		if located, err := parkingpkg.LocateVehicleOf(park, "1000"); err != nil || located.SpotID != *spotID || !located.StillParked {
+			t.Errorf("Expected spot %s still parked, got %+v %v", spotID.ID(), located, err)
+		}
+	})
+
+	t.Run("last spot after unpark", func(t *testing.T) {
+		first, err := park.Park(parkingentity.B1, "2000")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := park.Unpark(first.ID(), "2000"); err != nil {
+			t.Fatal(err)
+		}
+
+		second, err := park.Park(parkingentity.B1, "2000")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := park.Unpark(second.ID(), "2000"); err != nil {
+			t.Fatal(err)
+		}
+
+		found, err := park.SearchVehicle("2000")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if found.ID() != second.ID() {
+			t.Errorf("Expected last spot %s, got %s", second.ID(), found.ID())
+		}
+
+		if located, err := parkingpkg.LocateVehicleOf(park, "2000"); err != nil || located.SpotID != *second || located.StillParked {
+			t.Errorf("Expected last spot %s no longer parked, got %+v %v", second.ID(), located, err)
+		}
+	})
+}
+
+func testPlate(t *testing.T, factory func() parkingpkg.ParkingSystem) {
+	park := factory()
+
+	spotID, err := park.Park(parkingentity.A1, "b 1234-xyz")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("spellings resolve to the same vehicle", func(t *testing.T) {
+		found, err := park.SearchVehicle("B 1234 XYZ")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if found.ID() != spotID.ID() {
+			t.Errorf("Expected spot %s, got %s", spotID.ID(), found.ID())
+		}
+
+		_, err = park.Park(parkingentity.M1, "B1234XYZ")
+		expectErr(t, err, parkingentity.ErrVehicleAlreadyParked)
+	})
+
+	t.Run("invalid plate", func(t *testing.T) {
+		_, err := park.Park(parkingentity.A1, " - ")
+		expectErr(t, err, parkingentity.ErrInvalidPlate)
+	})
+
+	t.Run("unpark with another spelling", func(t *testing.T) {
+		receipt, err := park.Unpark(spotID.ID(), "B-1234-XYZ")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if receipt.Session.VehicleNumber != "B1234XYZ" {
+			t.Errorf("Expected the normalized plate B1234XYZ, got %s", receipt.Session.VehicleNumber)
+		}
+	})
+}
+
+func testAvailableSpot(t *testing.T, factory func() parkingpkg.ParkingSystem) {
+	park := factory()
+
+	for _, vehicleType := range vehicleTypes {
+		total, spots := park.AvailableSpot(vehicleType)
+		if total < 2 {
+			t.Fatalf("Expected the factory to provide at least 2 %v spots, got %d", vehicleType, total)
+		}
+		if total != len(spots) {
+			t.Errorf("Expected %d %v spots listed, got %d", total, vehicleType, len(spots))
+		}
+
+		seen := make(map[parkingentity.Spot]bool, len(spots))
+		for _, spot := range spots {
+			if seen[spot] {
+				t.Errorf("Spot %v listed twice", spot)
+			}
+			seen[spot] = true
+		}
+	}
+
+	if total, spots := park.AvailableSpot(parkingentity.X0); total != 0 || len(spots) != 0 {
+		t.Errorf("Expected no available spots for X0, got %d", total)
+	}
+}
+
+func testConcurrentGates(t *testing.T, factory func() parkingpkg.ParkingSystem) {
+	t.Run("distinct vehicles never share a spot", func(t *testing.T) {
+		park := factory()
+		total, _ := park.AvailableSpot(parkingentity.A1)
+
+		var (
+			wg     sync.WaitGroup
+			mu     sync.Mutex
+			parked = make(map[string]parkingentity.Plate)
+		)
+		for i := 0; i < total+10; i++ {
+			wg.Add(1)
+			go func(vehicleNumber parkingentity.Plate) {
+				defer wg.Done()
+
+				spotID, err := park.Park(parkingentity.A1, vehicleNumber)
+				if err != nil {
+					if !errors.Is(err, parkingentity.ErrSpotNotFound) {
+						t.Errorf("Unexpected error parking vehicle %s: %v", vehicleNumber, err)
+					}
+					return
+				}
+
+				mu.Lock()
+				defer mu.Unlock()
+				if other, ok := parked[spotID.ID()]; ok {
+					t.Errorf("Spot %s given to both vehicle %s and %s", spotID.ID(), other, vehicleNumber)
+				}
+				parked[spotID.ID()] = vehicleNumber
+			}(parkingentity.PlateFromNumber(1000 + i))
+		}
+		wg.Wait()
+
+		if len(parked) != total {
+			t.Errorf("Expected %d vehicles parked, got %d", total, len(parked))
+		}
+	})
+
+	t.Run("park and unpark keep every spot", func(t *testing.T) {
+		park := factory()
+		total, _ := park.AvailableSpot(parkingentity.M1)
+
+		const gates = 8
+		var wg sync.WaitGroup
+		for gate := 0; gate < gates; gate++ {
+			wg.Add(1)
+			go func(gate int) {
+				defer wg.Done()
+
+				for i := 0; i < 20; i++ {
+					vehicleNumber := parkingentity.PlateFromNumber(1000*(gate+1) + i)
+					spotID, err := park.Park(parkingentity.M1, vehicleNumber)
+					if err != nil {
+						continue
+					}
+					if _, err := park.Unpark(spotID.ID(), vehicleNumber); err != nil {
+						t.Errorf("Failed to unpark vehicle %s: %v", vehicleNumber, err)
+					}
+				}
+			}(gate)
+		}
+		wg.Wait()
+
+		after, spots := park.AvailableSpot(parkingentity.M1)
+		if after != total || len(spots) != total {
+			t.Errorf("Expected all %d M1 spots available, got %d", total, after)
+		}
+	})
+	t.Run("the same vehicle at every gate parks and unparks once", func(t *testing.T) {
+		park := factory()
+		total, _ := park.AvailableSpot(parkingentity.A1)
+
+		// conserved checks every spot is either free once or taken by the parked vehicle
+		conserved := func(t *testing.T, parked int) {
+			t.Helper()
+
+			free, spots := park.AvailableSpot(parkingentity.A1)
+			seen := make(map[parkingentity.Spot]bool, len(spots))
+			for _, spot := range spots {
+				if seen[spot] {
+					t.Fatalf("Spot %v is free twice", spot)
+				}
+				seen[spot] = true
+			}
+			if free+parked != total {
+				t.Fatalf("Expected %d free and %d parked spots to add up to %d", free, parked, total)
+			}
+		}
+
+		const gates = 32
+		for round := 0; round < 20; round++ {
+			// the same car shows up at every gate at once, only one gets a spot
+			var (
+				wg     sync.WaitGroup
+				parked atomic.Int32
+				spotID atomic.Pointer[parkingentity.SpotID]
+			)
+			for gate := 0; gate < gates; gate++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+
+					id, err := park.Park(parkingentity.A1, "1000")
+					switch {
+					case err == nil:
+						parked.Add(1)
+						spotID.Store(id)
+					case !errors.Is(err, parkingentity.ErrVehicleAlreadyParked):
+						t.Errorf("Unexpected error %v", err)
+					}
+				}()
+			}
+			wg.Wait()
+
+			if parked.Load() != 1 {
+				t.Fatalf("Round %d: expected one park of the same vehicle, got %d", round, parked.Load())
+			}
+			conserved(t, 1)
+
+			// and leaves at every gate at once, its spot is freed once
+			var unparked atomic.Int32
+			for gate := 0; gate < gates; gate++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+
+					_, err := park.Unpark(spotID.Load().ID(), "1000")
+					switch {
+					case err == nil:
+						unparked.Add(1)
+					case !errors.Is(err, parkingentity.ErrVehicleNotFound):
+						t.Errorf("Unexpected error %v", err)
+					}
+				}()
+			}
+			wg.Wait()
+
+			if unparked.Load() != 1 {
+				t.Fatalf("Round %d: expected one unpark of the same vehicle, got %d", round, unparked.Load())
+			}
+			conserved(t, 0)
+		}
+	})
+}
@@ -0,0 +1,90 @@
+package parkingtest
+
+import (
+	parkingpkg "github.com/mtfiqh/DoiT-parking-system/parking"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
+	"testing"
+)
+
+// RunFallbackConformance runs the overflow contract against the implementation built by factory.
+// Every call to factory must return a new, empty lot parking with the fallback, with at least one free spot of every vehicle type.
+func RunFallbackConformance(t *testing.T, factory func(fallback parkingentity.Fallback) parkingpkg.ParkingSystem) {
+	// fill parks vehicles until the queue of the vehicle type is empty.
+	fill := func(t *testing.T, park parkingpkg.ParkingSystem, vehicleType parkingentity.VehicleType, first int) {
+		t.Helper()
+
+		total, _ := park.AvailableSpot(vehicleType)
+		for i := 0; i < total; i++ {
+			if _, err := park.Park(vehicleType, parkingentity.PlateFromNumber(first+i)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	t.Run("overflows in order", func(t *testing.T) {
+		park := factory(parkingentity.FallbackBySize)
+		fill(t, park, parkingentity.B1, 10000)
+
+		_, m1Spots := park.AvailableSpot(parkingentity.M1)
+		spotID, err := park.Park(parkingentity.B1, "1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if parkingentity.Spot(*spotID) != m1Spots[0] {
+			t.Errorf("Expected the bicycle at the head M-1 spot %v, got %v", m1Spots[0], *spotID)
+		}
+
+		fill(t, park, parkingentity.M1, 20000)
+
+		_, a1Spots := park.AvailableSpot(parkingentity.A1)
+		spotID, err = park.Park(parkingentity.B1, "2")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if parkingentity.Spot(*spotID) != a1Spots[0] {
+			t.Errorf("Expected the bicycle at the head A-1 spot %v, got %v", a1Spots[0], *spotID)
+		}
+
+		if found, err := park.SearchVehicle("2"); err != nil || *found != *spotID {
+			t.Errorf("Expected vehicle 2 at %v, got %v, %v", *spotID, found, err)
+		}
+	})
+
+	t.Run("unpark returns the spot to its own queue", func(t *testing.T) {
+		park := factory(parkingentity.FallbackBySize)
+		fill(t, park, parkingentity.M1, 10000)
+
+		a1Total, _ := park.AvailableSpot(parkingentity.A1)
+		spotID, err := park.Park(parkingentity.M1, "1")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := park.Unpark(spotID.ID(), "1"); err != nil {
+			t.Fatal(err)
+		}
+
+		if m1Total, _ := park.AvailableSpot(parkingentity.M1); m1Total != 0 {
+			t.Errorf("Expected no available M-1 spots, got %d", m1Total)
+		}
+		after, a1Spots := park.AvailableSpot(parkingentity.A1)
+		if after != a1Total || a1Spots[len(a1Spots)-1] != parkingentity.Spot(*spotID) {
+			t.Errorf("Expected %d available A-1 spots with %v at the tail, got %d %v", a1Total, *spotID, after, a1Spots)
+		}
+	})
+
+	t.Run("no fallback", func(t *testing.T) {
+		park := factory(nil)
+		fill(t, park, parkingentity.M1, 10000)
+
+		_, err := park.Park(parkingentity.M1, "1")
+		expectErr(t, err, parkingentity.ErrSpotNotFound)
+
+		// larger vehicles never take smaller spots
+		park = factory(parkingentity.FallbackBySize)
+		fill(t, park, parkingentity.A1, 10000)
+
+		_, err = park.Park(parkingentity.A1, "1")
+		expectErr(t, err, parkingentity.ErrSpotNotFound)
+	})
+}
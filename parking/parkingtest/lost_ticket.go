@@ -0,0 +1,89 @@
+package parkingtest
+
+import (
+	parkingpkg "github.com/mtfiqh/DoiT-parking-system/parking"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
+	"github.com/mtfiqh/DoiT-parking-system/pkg/clockx"
+	"slices"
+	"testing"
+	"time"
+)
+
+const (
+	// flatFee and lostTicketPenalty are charged by lostTicketBiller.
+	flatFee           = 5000
+	lostTicketPenalty = 25000
+)
+
+// lostTicketBiller charges a flat fee, plus the penalty for a session with a lost ticket.
+type lostTicketBiller struct{}
+
+func (lostTicketBiller) Charge(session parkingentity.Session) (parkingentity.Receipt, error) {
+	receipt := parkingentity.Receipt{Session: session, Duration: session.ExitAt.Sub(session.EntryAt), Amount: flatFee}
+	if session.LostTicket {
+		receipt.Amount += lostTicketPenalty
+	}
+	return receipt, nil
+}
+
+// RunLostTicketConformance runs the lost ticket contract against the implementation built by factory.
+// Every call to factory must return a new, empty lot without gates and with a free A1 spot that implements
+// parking.LostTicketUnparker, timestamps must come from clock and receipts from biller.
+func RunLostTicketConformance(t *testing.T, factory func(clock clockx.Clock, biller parkingpkg.Biller) parkingpkg.ParkingSystem) {
+	t0 := time.Date(2025, 1, 1, 8, 0, 0, 0, time.UTC)
+	clock := clockx.NewFake(t0)
+
+	park := factory(clock, lostTicketBiller{})
+	unparker, ok := park.(parkingpkg.LostTicketUnparker)
+	if !ok {
+		t.Fatalf("Expected %T to implement parking.LostTicketUnparker", park)
+	}
+
+	spotID, err := park.Park(parkingentity.A1, "B 1234 XYZ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(time.Hour)
+
+	t.Run("unknown vehicle", func(t *testing.T) {
+		_, err := unparker.UnparkLostTicket("", "9999")
+		expectErr(t, err, parkingentity.ErrVehicleNotFound)
+	})
+
+	t.Run("found by its plate and charged the penalty", func(t *testing.T) {
+		receipt, err := unparker.UnparkLostTicket("", "b-1234-xyz")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if receipt.Amount != flatFee+lostTicketPenalty || receipt.Duration != time.Hour || receipt.Session.SpotID != *spotID || !receipt.Session.LostTicket {
+			t.Errorf("Expected %d for a lost ticket at %s after 1h, got %+v", flatFee+lostTicketPenalty, spotID.ID(), receipt)
+		}
+	})
+
+	t.Run("twice", func(t *testing.T) {
+		_, err := unparker.UnparkLostTicket("", "B1234XYZ")
+		expectErr(t, err, parkingentity.ErrVehicleNotFound)
+	})
+
+	t.Run("frees the spot", func(t *testing.T) {
+		if _, free := park.AvailableSpot(parkingentity.A1); !slices.Contains(free, parkingentity.Spot(*spotID)) {
+			t.Errorf("Expected spot %s to be free again, got %v", spotID.ID(), free)
+		}
+	})
+
+	t.Run("recorded in the history", func(t *testing.T) {
+		history, ok := park.(parkingpkg.SessionHistory)
+		if !ok {
+			t.Skipf("%T has no session history", park)
+		}
+
+		sessions, err := history.History("B1234XYZ")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(sessions) != 1 || !sessions[0].LostTicket || sessions[0].Active() {
+			t.Errorf("Expected one closed session with a lost ticket, got %+v", sessions)
+		}
+	})
+}
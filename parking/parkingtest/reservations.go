@@ -0,0 +1,173 @@
+package parkingtest
+
+import (
+	parkingpkg "github.com/mtfiqh/DoiT-parking-system/parking"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
+	"github.com/mtfiqh/DoiT-parking-system/pkg/clockx"
+	"testing"
+	"time"
+)
+
+// RunReservationConformance runs the reservation contract against the implementation built by factory.
+// Every call to factory must return a new, empty lot that implements parking.Reserver, holds must expire on clock.
+func RunReservationConformance(t *testing.T, factory func(clock clockx.Clock) parkingpkg.ParkingSystem) {
+	t0 := time.Date(2025, 1, 1, 8, 0, 0, 0, time.UTC)
+
+	newReserver := func(t *testing.T) (parkingpkg.ParkingSystem, parkingpkg.Reserver, *clockx.Fake) {
+		t.Helper()
+
+		clock := clockx.NewFake(t0)
+		park := factory(clock)
+		reserver, ok := park.(parkingpkg.Reserver)
+		if !ok {
+			t.Fatalf("Expected %T to implement parking.Reserver", park)
+		}
+
+		return park, reserver, clock
+	}
+
+	t.Run("holds the head spot", func(t *testing.T) {
+		park, reserver, _ := newReserver(t)
+		total, spots := park.AvailableSpot(parkingentity.A1)
+
+		reservation, err := reserver.Reserve(parkingentity.A1, "b 1234 xyz", 30*time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if parkingentity.Spot(reservation.SpotID) != spots[0] || reservation.VehicleNumber != "B1234XYZ" || !reservation.ExpiresAt.Equal(t0.Add(30*time.Minute)) {
+			t.Errorf("Expected B1234XYZ to hold %v until %v, got %+v", spots[0], t0.Add(30*time.Minute), reservation)
+		}
+
+		after, afterSpots := park.AvailableSpot(parkingentity.A1)
+		if after != total-1 {
+			t.Errorf("Expected %d available spots while held, got %d", total-1, after)
+		}
+		for _, spot := range afterSpots {
+			if spot == spots[0] {
+				t.Errorf("Expected the held spot %v not to be available", spot)
+			}
+		}
+	})
+
+	t.Run("rejects", func(t *testing.T) {
+		park, reserver, _ := newReserver(t)
+
+		if _, err := reserver.Reserve(parkingentity.A1, "1000", time.Hour); err != nil {
+			t.Fatal(err)
+		}
+
+		_, err := reserver.Reserve(parkingentity.M1, "1000", time.Hour)
+		expectErr(t, err, parkingentity.ErrVehicleReserved)
+
+		_, err = park.Park(parkingentity.A1, "1000")
+		expectErr(t, err, parkingentity.ErrVehicleReserved)
+
+		_, err = reserver.Reserve(parkingentity.A1, "2000", 0)
+		expectErr(t, err, parkingentity.ErrInvalidWindow)
+
+		_, err = reserver.Reserve(parkingentity.X0, "2000", time.Hour)
+		expectErr(t, err, parkingentity.ErrInvalidVehicleType)
+
+		if _, err := park.Park(parkingentity.A1, "3000"); err != nil {
+			t.Fatal(err)
+		}
+		_, err = reserver.Reserve(parkingentity.A1, "3000", time.Hour)
+		expectErr(t, err, parkingentity.ErrVehicleAlreadyParked)
+
+		_, err = reserver.ClaimReservation("4000")
+		expectErr(t, err, parkingentity.ErrReservationNotFound)
+	})
+
+	t.Run("claim parks at the held spot", func(t *testing.T) {
+		park, reserver, clock := newReserver(t)
+
+		reservation, err := reserver.Reserve(parkingentity.B1, "1000", time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		}
+		total, _ := park.AvailableSpot(parkingentity.B1)
+
+		clock.Advance(59 * time.Minute)
+		spotID, err := reserver.ClaimReservation("1000")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if *spotID != reservation.SpotID {
+			t.Errorf("Expected the held spot %s, got %s", reservation.SpotID.ID(), spotID.ID())
+		}
+
+		if found, err := park.SearchVehicle("1000"); err != nil || *found != reservation.SpotID {
+			t.Errorf("Expected vehicle 1000 at %s, got %v, %v", reservation.SpotID.ID(), found, err)
+		}
+		if after, _ := park.AvailableSpot(parkingentity.B1); after != total {
+			t.Errorf("Expected a claim to keep %d available spots, got %d", total, after)
+		}
+
+		_, err = reserver.ClaimReservation("1000")
+		expectErr(t, err, parkingentity.ErrReservationNotFound)
+
+		if _, err := park.Unpark(spotID.ID(), "1000"); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("expired hold goes back to the tail", func(t *testing.T) {
+		park, reserver, clock := newReserver(t)
+
+		reservation, err := reserver.Reserve(parkingentity.M1, "1000", time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		}
+		total, _ := park.AvailableSpot(parkingentity.M1)
+
+		clock.Advance(time.Hour)
+		_, err = reserver.ClaimReservation("1000")
+		expectErr(t, err, parkingentity.ErrReservationExpired)
+
+		after, spots := park.AvailableSpot(parkingentity.M1)
+		if after != total+1 || spots[len(spots)-1] != parkingentity.Spot(reservation.SpotID) {
+			t.Errorf("Expected %d available spots with %s at the tail, got %d %v", total+1, reservation.SpotID.ID(), after, spots)
+		}
+
+		if _, err := park.Park(parkingentity.M1, "1000"); err != nil {
+			t.Errorf("Expected the vehicle to park after its hold expired, got %v", err)
+		}
+	})
+
+	t.Run("park releases an expired hold", func(t *testing.T) {
+		park, reserver, clock := newReserver(t)
+		total, _ := park.AvailableSpot(parkingentity.M1)
+
+		if _, err := reserver.Reserve(parkingentity.M1, "1000", time.Hour); err != nil {
+			t.Fatal(err)
+		}
+
+		// parking without claiming must not leak the held spot
+		clock.Advance(time.Hour)
+		if _, err := park.Park(parkingentity.M1, "1000"); err != nil {
+			t.Fatal(err)
+		}
+		if after, _ := park.AvailableSpot(parkingentity.M1); after != total-1 {
+			t.Errorf("Expected %d available spots with only the parked vehicle taking one, got %d", total-1, after)
+		}
+
+		expectErr(t, reserver.CancelReservation("1000"), parkingentity.ErrReservationNotFound)
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		park, reserver, _ := newReserver(t)
+		total, _ := park.AvailableSpot(parkingentity.A1)
+
+		if _, err := reserver.Reserve(parkingentity.A1, "1000", time.Hour); err != nil {
+			t.Fatal(err)
+		}
+		if err := reserver.CancelReservation("1000"); err != nil {
+			t.Fatal(err)
+		}
+		if after, _ := park.AvailableSpot(parkingentity.A1); after != total {
+			t.Errorf("Expected %d available spots after cancelling, got %d", total, after)
+		}
+
+		expectErr(t, reserver.CancelReservation("1000"), parkingentity.ErrReservationNotFound)
+	})
+}
@@ -0,0 +1,133 @@
+package parkingtest
+
+import (
+	parkingpkg "github.com/mtfiqh/DoiT-parking-system/parking"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
+	"github.com/mtfiqh/DoiT-parking-system/pkg/clockx"
+	"testing"
+	"time"
+)
+
+// RunSessionConformance runs the session history contract against the implementation built by factory.
+// Every call to factory must return a new, empty lot that implements parking.SessionHistory, timestamps must come from clock.
+func RunSessionConformance(t *testing.T, factory func(clock clockx.Clock) parkingpkg.ParkingSystem) {
+	t0 := time.Date(2025, 1, 1, 8, 0, 0, 0, time.UTC)
+	clock := clockx.NewFake(t0)
+
+	park := factory(clock)
+	history, ok := park.(parkingpkg.SessionHistory)
+	if !ok {
+		t.Fatalf("Expected %T to implement parking.SessionHistory", park)
+	}
+
+	first, err := park.Park(parkingentity.A1, "1000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(time.Hour)
+	receipt, err := park.Unpark(first.ID(), "1000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if receipt.Duration != time.Hour {
+		t.Errorf("Expected a receipt of 1h, got %v", receipt.Duration)
+	}
+	clock.Advance(time.Hour)
+	second, err := park.Park(parkingentity.M1, "1000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(time.Minute)
+	if _, err := park.Park(parkingentity.B1, "2000"); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []parkingentity.Session{
+		{VehicleNumber: "1000", VehicleType: parkingentity.A1, SpotID: *first, EntryAt: t0, ExitAt: t0.Add(time.Hour)},
+		{VehicleNumber: "1000", VehicleType: parkingentity.M1, SpotID: *second, EntryAt: t0.Add(2 * time.Hour)},
+		{VehicleNumber: "2000", VehicleType: parkingentity.B1, EntryAt: t0.Add(2*time.Hour + time.Minute)},
+	}
+
+	t.Run("history", func(t *testing.T) {
+		sessions, err := history.History("1000")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expectSessions(t, sessions, expected[:2])
+	})
+
+	t.Run("history of unknown vehicle", func(t *testing.T) {
+		_, err := history.History("9999")
+		expectErr(t, err, parkingentity.ErrVehicleNotFound)
+	})
+
+	testCases := []struct {
+		name     string
+		from, to time.Time
+		expected []parkingentity.Session
+	}{
+		{
+			name:     "before the first entry",
+			from:     t0.Add(-time.Hour),
+			to:       t0,
+			expected: nil,
+		},
+		{
+			name:     "while the first session was active",
+			from:     t0.Add(30 * time.Minute),
+			to:       t0.Add(90 * time.Minute),
+			expected: expected[:1],
+		},
+		{
+			name:     "from the exact exit time",
+			from:     t0.Add(time.Hour),
+			to:       t0.Add(2 * time.Hour),
+			expected: expected[:1],
+		},
+		{
+			name:     "active sessions are open ended",
+			from:     t0.Add(24 * time.Hour),
+			to:       t0.Add(25 * time.Hour),
+			expected: expected[1:],
+		},
+		{
+			name:     "whole day",
+			from:     t0.Add(-time.Hour),
+			to:       t0.Add(24 * time.Hour),
+			expected: expected,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run("sessions between "+tc.name, func(t *testing.T) {
+			sessions, err := history.SessionsBetween(tc.from, tc.to)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			expectSessions(t, sessions, tc.expected)
+		})
+	}
+}
+
+// expectSessions compares sessions ignoring the time zone and the spot of the expected sessions without one.
+func expectSessions(t *testing.T, got, expected []parkingentity.Session) {
+	t.Helper()
+
+	if len(got) != len(expected) {
+		t.Fatalf("Expected %d sessions, got %d: %+v", len(expected), len(got), got)
+	}
+
+	for i := range expected {
+		e, g := expected[i], got[i]
+		if e.SpotID == (parkingentity.SpotID{}) {
+			e.SpotID = g.SpotID
+		}
+
+		if g.VehicleNumber != e.VehicleNumber || g.VehicleType != e.VehicleType || g.SpotID != e.SpotID ||
+			!g.EntryAt.Equal(e.EntryAt) || !g.ExitAt.Equal(e.ExitAt) || g.Active() != e.Active() {
+			t.Errorf("Session %d: expected %+v, got %+v", i, e, g)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package parkingtest
+
+import (
+	parkingpkg "github.com/mtfiqh/DoiT-parking-system/parking"
+	"github.com/mtfiqh/DoiT-parking-system/parking/parkingentity"
+	"reflect"
+	"testing"
+)
+
+// RunSpotQueryConformance runs the spot query contract against the implementation built by factory.
+// Every call to factory must return a new, empty lot with free A1 spots on floors 0 and 1 that implements parking.SpotQuerier.
+func RunSpotQueryConformance(t *testing.T, factory func() parkingpkg.ParkingSystem) {
+	park := factory()
+	querier, ok := park.(parkingpkg.SpotQuerier)
+	if !ok {
+		t.Fatalf("Expected %T to implement parking.SpotQuerier", park)
+	}
+
+	// the first spot is taken, so the pages don't start at the first spot seeded
+	if _, err := park.Park(parkingentity.A1, "1000"); err != nil {
+		t.Fatal(err)
+	}
+
+	// expect pages through the query two spots at a time and compares them with the filtered AvailableSpot
+	expect := func(t *testing.T, query parkingentity.SpotQuery) {
+		t.Helper()
+
+		var expected []parkingentity.Spot
+		_, spots := park.AvailableSpot(query.VehicleType)
+		for _, spot := range spots {
+			if query.Matches(spot) {
+				expected = append(expected, spot)
+			}
+		}
+
+		query.Limit = 2
+		var got []parkingentity.Spot
+		for pages := 0; ; pages++ {
+			page, err := querier.QuerySpots(query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if page.Total != len(expected) || len(page.Spots) > 2 {
+				t.Fatalf("Expected pages of 2 of %d spots, got %d of %d", len(expected), len(page.Spots), page.Total)
+			}
+			if pages > len(expected) {
+				t.Fatalf("Expected at most %d pages", len(expected)/2+1)
+			}
+
+			got = append(got, page.Spots...)
+			if page.Next == "" {
+				break
+			}
+			query.Cursor = page.Next
+		}
+
+		if len(got) != len(expected) || (len(got) > 0 && !reflect.DeepEqual(got, expected)) {
+			t.Errorf("Expected spots %v, got %v", expected, got)
+		}
+	}
+
+	floor := 1
+	t.Run("every spot", func(t *testing.T) {
+		expect(t, parkingentity.SpotQuery{VehicleType: parkingentity.A1})
+	})
+
+	t.Run("floor", func(t *testing.T) {
+		expect(t, parkingentity.SpotQuery{VehicleType: parkingentity.A1, Floor: &floor})
+	})
+
+	t.Run("rows", func(t *testing.T) {
+		expect(t, parkingentity.SpotQuery{VehicleType: parkingentity.A1, Rows: &parkingentity.RowRange{From: 1, To: 1}})
+	})
+
+	t.Run("near", func(t *testing.T) {
+		expect(t, parkingentity.SpotQuery{VehicleType: parkingentity.A1, Near: &parkingentity.Spot{Floor: 0, Row: 0, Col: 1}, Within: 2})
+	})
+
+	t.Run("page past the end", func(t *testing.T) {
+		total, _ := park.AvailableSpot(parkingentity.A1)
+		page, err := querier.QuerySpots(parkingentity.SpotQuery{VehicleType: parkingentity.A1, Cursor: "1000000"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(page.Spots) != 0 || page.Total != total || page.Next != "" {
+			t.Errorf("Expected an empty last page of %d spots, got %+v", total, page)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := querier.QuerySpots(parkingentity.SpotQuery{VehicleType: parkingentity.A1, Limit: -1})
+		expectErr(t, err, parkingentity.ErrInvalidSpotQuery)
+
+		_, err = querier.QuerySpots(parkingentity.SpotQuery{VehicleType: parkingentity.A1, Cursor: "next"})
+		expectErr(t, err, parkingentity.ErrInvalidCursor)
+
+		_, err = querier.QuerySpots(parkingentity.SpotQuery{VehicleType: parkingentity.X0})
+		expectErr(t, err, parkingentity.ErrInvalidVehicleType)
+	})
+}
@@ -0,0 +1,59 @@
+package clockx
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock tells the current time, inject a Fake in tests.
+type Clock interface {
+	Now() time.Time
+}
+
+type realClock struct{}
+
+// New returns a clock reading the system time.
+func New() Clock {
+	return realClock{}
+}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// Fake is a clock that only moves when told to, it's safe for concurrent use.
+type Fake struct {
+	now   time.Time
+	mutex *sync.RWMutex
+}
+
+// NewFake returns a fake clock stopped at now.
+func NewFake(now time.Time) *Fake {
+	return &Fake{
+		now:   now,
+		mutex: new(sync.RWMutex),
+	}
+}
+
+func (f *Fake) Now() time.Time {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	return f.now
+}
+
+// Advance moves the clock forward by d.
+func (f *Fake) Advance(d time.Duration) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.now = f.now.Add(d)
+}
+
+// Set moves the clock to now.
+func (f *Fake) Set(now time.Time) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.now = now
+}
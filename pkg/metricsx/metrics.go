@@ -0,0 +1,302 @@
+package metricsx
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Registry holds metrics and writes them in the Prometheus text exposition format, it's safe for concurrent use.
+type Registry struct {
+	metrics []metric
+	mutex   *sync.Mutex
+}
+
+type metric interface {
+	write(w io.Writer) error
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{mutex: new(sync.Mutex)}
+}
+
+func (r *Registry) register(m metric) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.metrics = append(r.metrics, m)
+}
+
+// Write writes every metric in the order they were registered, the series of a metric sorted by their labels.
+func (r *Registry) Write(w io.Writer) error {
+	r.mutex.Lock()
+	metrics := append([]metric(nil), r.metrics...)
+	r.mutex.Unlock()
+
+	for _, m := range metrics {
+		if err := m.write(w); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ServeHTTP serves the metrics to a Prometheus scrape.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_ = r.Write(w)
+}
+
+// desc is the name, help and label names of a metric.
+type desc struct {
+	name   string
+	help   string
+	kind   string
+	labels []string
+}
+
+func (d desc) header(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", d.name, strings.ReplaceAll(d.help, "\n", " "), d.name, d.kind)
+	return err
+}
+
+// series writes one sample, extra is an additional label such as le.
+func (d desc) series(w io.Writer, suffix string, values []string, extra string, value float64) error {
+	var b strings.Builder
+	b.WriteString(d.name)
+	b.WriteString(suffix)
+
+	pairs := make([]string, 0, len(values)+1)
+	for i, v := range values {
+		pairs = append(pairs, d.labels[i]+`="`+escape(v)+`"`)
+	}
+	if extra != "" {
+		pairs = append(pairs, extra)
+	}
+	if len(pairs) > 0 {
+		b.WriteString("{" + strings.Join(pairs, ",") + "}")
+	}
+
+	_, err := fmt.Fprintf(w, "%s %s\n", b.String(), formatFloat(value))
+	return err
+}
+
+// key identifies the series of the label values.
+func (d desc) key(values []string) string {
+	if len(values) != len(d.labels) {
+		panic(fmt.Sprintf("metricsx: %s takes %d label values, got %d", d.name, len(d.labels), len(values)))
+	}
+	return strings.Join(values, "\xff")
+}
+
+// CounterVec counts events per label values.
+type CounterVec struct {
+	desc
+	values map[string]float64
+	mutex  *sync.Mutex
+}
+
+// Counter registers a counter with the label names.
+func (r *Registry) Counter(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{desc: desc{name: name, help: help, kind: "counter", labels: labels}, values: make(map[string]float64), mutex: new(sync.Mutex)}
+	r.register(c)
+	return c
+}
+
+// Inc adds one to the series of the label values.
+func (c *CounterVec) Inc(values ...string) {
+	c.Add(1, values...)
+}
+
+// Add adds delta to the series of the label values.
+func (c *CounterVec) Add(delta float64, values ...string) {
+	key := c.key(values)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.values[key] += delta
+}
+
+// Value returns the count of the label values.
+func (c *CounterVec) Value(values ...string) float64 {
+	key := c.key(values)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.values[key]
+}
+
+func (c *CounterVec) write(w io.Writer) error {
+	c.mutex.Lock()
+	keys := sortedKeys(c.values)
+	values := make([]float64, len(keys))
+	for i, key := range keys {
+		values[i] = c.values[key]
+	}
+	c.mutex.Unlock()
+
+	if err := c.header(w); err != nil {
+		return err
+	}
+	for i, key := range keys {
+		if err := c.series(w, "", splitKey(key, len(c.labels)), "", values[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// HistogramVec counts observations in buckets per label values.
+type HistogramVec struct {
+	desc
+	buckets []float64
+	values  map[string]*histogram
+	mutex   *sync.Mutex
+}
+
+type histogram struct {
+	counts []uint64 // per bucket, not cumulative
+	count  uint64
+	sum    float64
+}
+
+// Histogram registers a histogram with the upper bounds of its buckets, +Inf is added.
+func (r *Registry) Histogram(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+
+	h := &HistogramVec{desc: desc{name: name, help: help, kind: "histogram", labels: labels}, buckets: buckets, values: make(map[string]*histogram), mutex: new(sync.Mutex)}
+	r.register(h)
+	return h
+}
+
+// Observe adds the value to the series of the label values.
+func (h *HistogramVec) Observe(value float64, values ...string) {
+	key := h.key(values)
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	s, ok := h.values[key]
+	if !ok {
+		s = &histogram{counts: make([]uint64, len(h.buckets))}
+		h.values[key] = s
+	}
+
+	if i := sort.SearchFloat64s(h.buckets, value); i < len(h.buckets) {
+		s.counts[i]++
+	}
+	s.count++
+	s.sum += value
+}
+
+func (h *HistogramVec) write(w io.Writer) error {
+	h.mutex.Lock()
+	keys := sortedKeys(h.values)
+	series := make([]histogram, len(keys))
+	for i, key := range keys {
+		s := h.values[key]
+		series[i] = histogram{counts: append([]uint64(nil), s.counts...), count: s.count, sum: s.sum}
+	}
+	h.mutex.Unlock()
+
+	if err := h.header(w); err != nil {
+		return err
+	}
+	for i, key := range keys {
+		values := splitKey(key, len(h.labels))
+
+		var cumulative uint64
+		for j, bound := range h.buckets {
+			cumulative += series[i].counts[j]
+			if err := h.series(w, "_bucket", values, `le="`+formatFloat(bound)+`"`, float64(cumulative)); err != nil {
+				return err
+			}
+		}
+		if err := h.series(w, "_bucket", values, `le="+Inf"`, float64(series[i].count)); err != nil {
+			return err
+		}
+		if err := h.series(w, "_sum", values, "", series[i].sum); err != nil {
+			return err
+		}
+		if err := h.series(w, "_count", values, "", float64(series[i].count)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GaugeFunc is a gauge whose series are collected on every write.
+type GaugeFunc struct {
+	desc
+	collect func(set func(value float64, values ...string))
+}
+
+// GaugeFunc registers a gauge, collect calls set for every series when the metrics are written.
+func (r *Registry) GaugeFunc(name, help string, labels []string, collect func(set func(value float64, values ...string))) *GaugeFunc {
+	g := &GaugeFunc{desc: desc{name: name, help: help, kind: "gauge", labels: labels}, collect: collect}
+	r.register(g)
+	return g
+}
+
+func (g *GaugeFunc) write(w io.Writer) error {
+	values := make(map[string]float64)
+	g.collect(func(value float64, labelValues ...string) {
+		values[g.key(labelValues)] = value
+	})
+
+	if err := g.header(w); err != nil {
+		return err
+	}
+	for _, key := range sortedKeys(values) {
+		if err := g.series(w, "", splitKey(key, len(g.labels)), "", values[key]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func splitKey(key string, labels int) []string {
+	if labels == 0 {
+		return nil
+	}
+	return strings.Split(key, "\xff")
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escape(v string) string {
+	return labelEscaper.Replace(v)
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	default:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+}
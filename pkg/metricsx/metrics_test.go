@@ -0,0 +1,63 @@
+package metricsx_test
+
+import (
+	"bytes"
+	"github.com/mtfiqh/DoiT-parking-system/pkg/metricsx"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	registry := metricsx.NewRegistry()
+
+	ops := registry.Counter("ops_total", "Operations done.", "op", "outcome")
+	ops.Inc("park", "ok")
+	ops.Inc("park", "ok")
+	ops.Add(3, "unpark", `say "hi"`)
+
+	latency := registry.Histogram("op_seconds", "Operation latency.", []float64{0.1, 0.01}, "op")
+	latency.Observe(0.005, "park")
+	latency.Observe(0.05, "park")
+	latency.Observe(2, "park")
+
+	registry.GaugeFunc("free", "Free spots.", []string{"type"}, func(set func(float64, ...string)) {
+		set(7, "B-1")
+		set(1.5, "A-1")
+	})
+
+	var out bytes.Buffer
+	if err := registry.Write(&out); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `# HELP ops_total Operations done.
+# TYPE ops_total counter
+ops_total{op="park",outcome="ok"} 2
+ops_total{op="unpark",outcome="say \"hi\""} 3
+# HELP op_seconds Operation latency.
+# TYPE op_seconds histogram
+op_seconds_bucket{op="park",le="0.01"} 1
+op_seconds_bucket{op="park",le="0.1"} 2
+op_seconds_bucket{op="park",le="+Inf"} 3
+op_seconds_sum{op="park"} 2.055
+op_seconds_count{op="park"} 3
+# HELP free Free spots.
+# TYPE free gauge
+free{type="A-1"} 1.5
+free{type="B-1"} 7
+`
+	if out.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+
+	if got := ops.Value("park", "ok"); got != 2 {
+		t.Errorf("Expected 2 parks, got %v", got)
+	}
+
+	rec := httptest.NewRecorder()
+	registry.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/plain; version=0.0.4") || rec.Body.String() != expected {
+		t.Errorf("Expected the text exposition, got %q %q", rec.Header().Get("Content-Type"), rec.Body.String())
+	}
+}
@@ -0,0 +1,166 @@
+package queuex
+
+import (
+	"iter"
+	"sync/atomic"
+)
+
+// LockFree is the Michael-Scott lock-free queue: enqueues and dequeues link and unlink nodes with compare-and-swap,
+// so callers never wait on each other's lock, only retry when they lose a race.
+//
+// Removed elements are marked taken and unlinked by the Remove that took them, a taken node stays linked only while
+// it is the last one or when its unlink lost a race, until the next Remove walking past it or Dequeue reaching it.
+// The walks of Snapshot and Values see the queue as it changes, so their size is the number of values they found
+// rather than Len at one instant.
+type LockFree[T any] struct {
+	// head is a sentinel, the elements start at head.next
+	head atomic.Pointer[lockFreeNode[T]]
+	tail atomic.Pointer[lockFreeNode[T]]
+	// taken counts the values dequeued or removed, the length is the index of the last node minus it
+	taken atomic.Int64
+}
+
+type lockFreeNode[T any] struct {
+	value T
+	next  atomic.Pointer[lockFreeNode[T]]
+	// index is the number of elements enqueued up to this one, set before the node is linked
+	index int64
+	// taken is set once by whoever dequeues or removes the value
+	taken atomic.Bool
+}
+
+// NewLockFree returns an empty lock-free queue.
+func NewLockFree[T any]() *LockFree[T] {
+	q := new(LockFree[T])
+
+	sentinel := new(lockFreeNode[T])
+	sentinel.taken.Store(true)
+	q.head.Store(sentinel)
+	q.tail.Store(sentinel)
+
+	return q
+}
+
+// Enqueue adds an element to the end of the queue (tail).
+func (q *LockFree[T]) Enqueue(v T) {
+	node := &lockFreeNode[T]{value: v}
+
+	for {
+		tail := q.tail.Load()
+		next := tail.next.Load()
+		if tail != q.tail.Load() {
+			continue
+		}
+
+		if next != nil {
+			// another enqueue linked its node but hasn't swung the tail yet, help it
+			q.tail.CompareAndSwap(tail, next)
+			continue
+		}
+
+		node.index = tail.index + 1
+		if tail.next.CompareAndSwap(nil, node) {
+			q.tail.CompareAndSwap(tail, node)
+			return
+		}
+	}
+}
+
+// Dequeue removes and returns the element at the front of the queue (head). FIFO
+func (q *LockFree[T]) Dequeue() (T, bool) {
+	for {
+		head := q.head.Load()
+		tail := q.tail.Load()
+		next := head.next.Load()
+		if head != q.head.Load() {
+			continue
+		}
+
+		if next == nil {
+			var zeroValue T
+			return zeroValue, false
+		}
+
+		if head == tail {
+			q.tail.CompareAndSwap(tail, next)
+			continue
+		}
+
+		// next becomes the sentinel, its value is ours unless Remove took it first
+		if q.head.CompareAndSwap(head, next) && next.taken.CompareAndSwap(false, true) {
+			q.taken.Add(1)
+			return next.value, true
+		}
+	}
+}
+
+// Remove marks the first element matching as taken, it reports whether one was found.
+// The taken nodes it walks past, its own included, are unlinked from their predecessor.
+func (q *LockFree[T]) Remove(match func(T) bool) bool {
+	prev := q.head.Load()
+	for node := prev.next.Load(); node != nil; node = prev.next.Load() {
+		if node.taken.Load() {
+			if !q.unlink(prev, node) {
+				prev = node
+			}
+			continue
+		}
+
+		if match(node.value) && node.taken.CompareAndSwap(false, true) {
+			q.taken.Add(1)
+			q.unlink(prev, node)
+			return true
+		}
+		prev = node
+	}
+
+	return false
+}
+
+// unlink points prev past the taken node, it reports whether it did.
+// The last node is never unlinked, an enqueue may be linking its successor, so the nodes enqueued are never lost.
+// Losing a race with another unlink leaves the node linked for the next walk, the values are never lost either:
+// next pointers only move forward, past taken nodes.
+func (q *LockFree[T]) unlink(prev, node *lockFreeNode[T]) bool {
+	next := node.next.Load()
+	if next == nil {
+		return false
+	}
+	return prev.next.CompareAndSwap(node, next)
+}
+
+// Len returns the number of elements in the queue.
+// The taken count is loaded before the last node, every value it counts was linked before, so Len is never negative.
+func (q *LockFree[T]) Len() int {
+	taken := q.taken.Load()
+
+	last := q.tail.Load()
+	for next := last.next.Load(); next != nil; next = last.next.Load() {
+		last = next
+	}
+
+	return int(last.index - taken)
+}
+
+// Snapshot returns the values from head to tail and their count.
+func (q *LockFree[T]) Snapshot() (int, []T) {
+	var values []T
+	for v := range q.Values() {
+		values = append(values, v)
+	}
+	return len(values), values
+}
+
+// Values yields the values from head to tail without copying them.
+func (q *LockFree[T]) Values() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for node := q.head.Load().next.Load(); node != nil; node = node.next.Load() {
+			if node.taken.Load() {
+				continue
+			}
+			if !yield(node.value) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package queuex
+
+import (
+	"sync"
+	"testing"
+)
+
+// nodes counts the nodes linked after the sentinel, taken or not.
+func (q *LockFree[T]) nodes() int {
+	count := 0
+	for node := q.head.Load().next.Load(); node != nil; node = node.next.Load() {
+		count++
+	}
+	return count
+}
+
+func TestLockFreeUnlinksRemoved(t *testing.T) {
+	queue := NewLockFree[int]()
+	for i := 0; i < 100; i++ {
+		queue.Enqueue(i)
+	}
+
+	for i := 0; i < 100; i += 2 {
+		queue.Remove(func(v int) bool { return v == i })
+	}
+	if queue.nodes() != 50 || queue.Len() != 50 {
+		t.Errorf("Expected the 50 removed nodes to be unlinked, got %d nodes with length %d", queue.nodes(), queue.Len())
+	}
+
+	// the last node stays linked until an element is enqueued after it
+	queue.Remove(func(v int) bool { return v == 99 })
+	queue.Enqueue(100)
+	queue.Remove(func(v int) bool { return v == 100 })
+	if queue.nodes() != 50 || queue.Len() != 49 {
+		t.Errorf("Expected 49 elements and the last taken node, got %d nodes with length %d", queue.nodes(), queue.Len())
+	}
+}
+
+// TestLockFreeDeadNodesBounded removes and enqueues back the elements from many goroutines without dequeuing,
+// once they are done a single walk leaves only live nodes and the last one.
+func TestLockFreeDeadNodesBounded(t *testing.T) {
+	const elements, workers, rounds = 100, 8, 1000
+
+	queue := NewLockFree[int]()
+	for i := 0; i < elements; i++ {
+		queue.Enqueue(i)
+	}
+
+	var wg sync.WaitGroup
+	for worker := 0; worker < workers; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for round := 0; round < rounds; round++ {
+				target := (worker*rounds + round) % elements
+				if queue.Remove(func(v int) bool { return v == target }) {
+					queue.Enqueue(target)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if nodes := queue.nodes(); nodes > elements+workers*rounds/10 {
+		t.Errorf("Expected the removed nodes to be unlinked while working, got %d nodes for %d elements", nodes, elements)
+	}
+
+	queue.Remove(func(int) bool { return false })
+	if nodes := queue.nodes(); nodes > elements+1 || queue.Len() != elements {
+		t.Errorf("Expected at most %d nodes after a walk, got %d with length %d", elements+1, nodes, queue.Len())
+	}
+}
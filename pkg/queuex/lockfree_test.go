@@ -0,0 +1,88 @@
+package queuex_test
+
+import (
+	"github.com/mtfiqh/DoiT-parking-system/pkg/queuex"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestLockFree(t *testing.T) {
+	queue := queuex.NewLockFree[int]()
+	if _, ok := queue.Dequeue(); ok {
+		t.Fatal("Expected an empty queue")
+	}
+
+	for i := 1; i <= 5; i++ {
+		queue.Enqueue(i)
+	}
+	if v, ok := queue.Dequeue(); v != 1 || !ok {
+		t.Errorf("Expected (1, true), got (%d, %t)", v, ok)
+	}
+
+	if !queue.Remove(func(v int) bool { return v == 3 }) || queue.Remove(func(v int) bool { return v == 3 }) {
+		t.Error("Expected 3 to be removed once")
+	}
+	queue.Enqueue(6)
+
+	if size, values := queue.Snapshot(); size != 4 || queue.Len() != 4 || !reflect.DeepEqual(values, []int{2, 4, 5, 6}) {
+		t.Errorf("Expected [2 4 5 6], got %v with size %d and length %d", values, size, queue.Len())
+	}
+
+	// the removed element is skipped
+	var dequeued []int
+	for v, ok := queue.Dequeue(); ok; v, ok = queue.Dequeue() {
+		dequeued = append(dequeued, v)
+	}
+	if !reflect.DeepEqual(dequeued, []int{2, 4, 5, 6}) || queue.Len() != 0 {
+		t.Errorf("Expected to dequeue [2 4 5 6], got %v with length %d", dequeued, queue.Len())
+	}
+}
+
+// testConservation moves elements between the queue and goroutines that dequeue, remove and enqueue them back,
+// at the end every element must be in the queue exactly once, which the FIFO queues and Sharded all promise.
+func testConservation(t *testing.T, queue queuex.Unordered[int]) {
+	const elements, workers, rounds = 100, 16, 1000
+
+	for i := 0; i < elements; i++ {
+		queue.Enqueue(i)
+	}
+
+	var wg sync.WaitGroup
+	for worker := 0; worker < workers; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for round := 0; round < rounds; round++ {
+				if round%10 == 0 {
+					target := (worker + round) % elements
+					if queue.Remove(func(v int) bool { return v == target }) {
+						queue.Enqueue(target)
+					}
+					continue
+				}
+
+				if v, ok := queue.Dequeue(); ok {
+					queue.Enqueue(v)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	size, values := queue.Snapshot()
+	seen := make(map[int]bool, len(values))
+	for _, v := range values {
+		if seen[v] {
+			t.Fatalf("Expected %d once in the queue", v)
+		}
+		seen[v] = true
+	}
+	if size != elements || queue.Len() != elements {
+		t.Errorf("Expected %d elements, got %d with length %d", elements, size, queue.Len())
+	}
+}
+
+func TestLockFreeConcurrency(t *testing.T) {
+	testConservation(t, queuex.NewLockFree[int]())
+}
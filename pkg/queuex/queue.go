@@ -1,23 +1,61 @@
 package queuex
 
-import "sync"
+import (
+	"iter"
+	"sync"
+)
+
+// Interface is a FIFO queue safe for concurrent use, Queue and LockFree implement it.
+type Interface[T any] interface {
+	// Enqueue adds an element to the tail.
+	Enqueue(v T)
+	// Dequeue removes and returns the element at the head, false when the queue is empty.
+	Dequeue() (T, bool)
+	// Remove removes the first element matching, it reports whether one was found.
+	Remove(match func(T) bool) bool
+	// Len returns the number of elements.
+	Len() int
+	// Snapshot returns the size and the values from head to tail, the size always matches the values.
+	Snapshot() (int, []T)
+	// Values yields the values from head to tail without copying them, the loop must not change the queue.
+	Values() iter.Seq[T]
+}
+
+// Unordered is a queue safe for concurrent use that dequeues every element once but in no set order, Sharded implements it.
+// Every Interface is an Unordered too.
+type Unordered[T any] interface {
+	// Enqueue adds an element.
+	Enqueue(v T)
+	// Dequeue removes and returns an element, false when the queue is empty.
+	Dequeue() (T, bool)
+	// Remove removes an element matching, it reports whether one was found.
+	Remove(match func(T) bool) bool
+	// Len returns the number of elements.
+	Len() int
+	// Snapshot returns the size and the values, the size always matches the values.
+	Snapshot() (int, []T)
+	// Values yields the values without copying them, the loop must not change the queue.
+	Values() iter.Seq[T]
+}
 
 type Node[T any] struct {
 	Value T
 	Next  *Node[T]
 }
+
+// Queue is a FIFO queue safe for concurrent use, its state is only read under its lock through Len, Print and Snapshot.
 type Queue[T any] struct {
-	Head  *Node[T]
-	Tail  *Node[T]
-	Size  int
+	head  *Node[T]
+	tail  *Node[T]
+	size  int
 	mutex *sync.RWMutex
 }
 
 func NewQueue[T any]() *Queue[T] {
 	return &Queue[T]{
-		Head:  nil,
-		Tail:  nil,
-		Size:  0,
+		head:  nil,
+		tail:  nil,
+		size:  0,
 		mutex: new(sync.RWMutex),
 	}
 }
@@ -33,16 +71,16 @@ func (q *Queue[T]) Enqueue(v T) {
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
 
-	q.Size++
+	q.size++
 
-	if q.Tail == nil {
-		q.Head = node
-		q.Tail = node
+	if q.tail == nil {
+		q.head = node
+		q.tail = node
 		return
 	}
 
-	q.Tail.Next = node
-	q.Tail = node
+	q.tail.Next = node
+	q.tail = node
 }
 
 // Dequeue removes and returns the element at the front of the queue (head). FIFO
@@ -51,16 +89,16 @@ func (q *Queue[T]) Dequeue() (T, bool) {
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
 
-	if q.Head != nil {
+	if q.head != nil {
 
-		v := q.Head.Value
-		q.Head = q.Head.Next
+		v := q.head.Value
+		q.head = q.head.Next
 
-		if q.Head == nil {
-			q.Tail = nil
+		if q.head == nil {
+			q.tail = nil
 		}
 
-		q.Size--
+		q.size--
 
 		return v, true
 	}
@@ -74,19 +112,73 @@ func (q *Queue[T]) IsEmpty() bool {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
 
-	return q.Size == 0
+	return q.size == 0
+}
+
+// Len returns the number of elements in the queue.
+func (q *Queue[T]) Len() int {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	return q.size
 }
 
 // Print to get all values each node.
 func (q *Queue[T]) Print() []T {
+	_, values := q.Snapshot()
+	return values
+}
+
+// Snapshot returns the size and the values from head to tail read together, so they always agree.
+func (q *Queue[T]) Snapshot() (int, []T) {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
 
 	var values []T
-	current := q.Head
-	for current != nil {
+	for current := q.head; current != nil; current = current.Next {
 		values = append(values, current.Value)
-		current = current.Next
 	}
-	return values
+	return q.size, values
+}
+
+// Values yields the values from head to tail without copying them, the queue is read locked until the loop ends,
+// so the loop must not change the queue.
+func (q *Queue[T]) Values() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		q.mutex.RLock()
+		defer q.mutex.RUnlock()
+
+		for current := q.head; current != nil; current = current.Next {
+			if !yield(current.Value) {
+				return
+			}
+		}
+	}
+}
+
+// Remove removes the first element matching from the queue, it reports whether one was found.
+func (q *Queue[T]) Remove(match func(T) bool) bool {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	var prev *Node[T]
+	for current := q.head; current != nil; prev, current = current, current.Next {
+		if !match(current.Value) {
+			continue
+		}
+
+		if prev == nil {
+			q.head = current.Next
+		} else {
+			prev.Next = current.Next
+		}
+		if q.tail == current {
+			q.tail = prev
+		}
+
+		q.size--
+		return true
+	}
+
+	return false
 }
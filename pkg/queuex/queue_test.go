@@ -1,6 +1,7 @@
 package queuex_test
 
 import (
+	"fmt"
 	"github.com/mtfiqh/DoiT-parking-system/pkg/queuex"
 	"sync"
 	"sync/atomic"
@@ -151,7 +152,7 @@ func TestQueuexConcurrency(t *testing.T) {
 	wg.Wait()
 
 	// Verify final state
-	remaining := queue.Size
+	remaining := queue.Len()
 	totalProcessed := int(dequeuedItems) + remaining
 
 	t.Logf("Enqueued: %d, Dequeued: %d, Remaining: %d",
@@ -168,3 +169,137 @@ func TestQueuexConcurrency(t *testing.T) {
 		t.Errorf("Expected %d remaining values, got %d", remaining, len(values))
 	}
 }
+
+func TestQueuexRemove(t *testing.T) {
+	queue := queuex.NewQueue[int]()
+	for i := 1; i <= 4; i++ {
+		queue.Enqueue(i)
+	}
+
+	for _, v := range []int{1, 3, 4} {
+		if !queue.Remove(func(x int) bool { return x == v }) {
+			t.Errorf("Expected %d to be removed", v)
+		}
+	}
+	if queue.Remove(func(x int) bool { return x == 3 }) {
+		t.Error("Expected 3 to be removed already")
+	}
+
+	// the tail moved back, so enqueue still appends
+	queue.Enqueue(5)
+	if values := queue.Print(); len(values) != 2 || values[0] != 2 || values[1] != 5 || queue.Len() != 2 {
+		t.Errorf("Expected [2 5], got %v with size %d", values, queue.Len())
+	}
+}
+
+func TestQueuexSnapshot(t *testing.T) {
+	queue := queuex.NewQueue[int]()
+	if size, values := queue.Snapshot(); size != 0 || len(values) != 0 {
+		t.Errorf("Expected an empty snapshot, got %d %v", size, values)
+	}
+
+	// the size and the values of a snapshot agree while other goroutines keep changing the queue
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				queue.Enqueue(i)
+				queue.Dequeue()
+				queue.Enqueue(i)
+			}
+		}()
+	}
+
+	for i := 0; i < 1000; i++ {
+		if size, values := queue.Snapshot(); size != len(values) {
+			t.Fatalf("Expected the size %d to match %d values", size, len(values))
+		}
+	}
+	close(done)
+	wg.Wait()
+
+	if size, values := queue.Snapshot(); size != queue.Len() || len(values) != size {
+		t.Errorf("Expected the snapshot to match the length %d, got %d %d", queue.Len(), size, len(values))
+	}
+}
+
+// BenchmarkQueues compares the queues with gates goroutines taking an element and putting it back,
+// the way parking and unparking move spots through the queue.
+func BenchmarkQueues(b *testing.B) {
+	queues := []struct {
+		name string
+		new  func() queuex.Unordered[int]
+	}{
+		{"list", func() queuex.Unordered[int] { return queuex.NewQueue[int]() }},
+		{"lock-free", func() queuex.Unordered[int] { return queuex.NewLockFree[int]() }},
+		{"sharded", func() queuex.Unordered[int] { return queuex.NewSharded(func(v int) int { return v % 16 }) }},
+	}
+
+	for _, q := range queues {
+		for _, gates := range []int{1, 10, 100, 1000} {
+			b.Run(fmt.Sprintf("%s/gates=%d", q.name, gates), func(b *testing.B) {
+				queue := q.new()
+				for i := 0; i < 10000; i++ {
+					queue.Enqueue(i)
+				}
+
+				var wg sync.WaitGroup
+				b.ResetTimer()
+				for gate := 0; gate < gates; gate++ {
+					// the operations are split between the gates, the first ones take the remainder
+					ops := b.N / gates
+					if gate < b.N%gates {
+						ops++
+					}
+
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						for i := 0; i < ops; i++ {
+							if v, ok := queue.Dequeue(); ok {
+								queue.Enqueue(v)
+							}
+						}
+					}()
+				}
+				wg.Wait()
+			})
+		}
+	}
+}
+
+// BenchmarkEnqueueDequeue fills the queue with b.N elements and empties it again.
+func BenchmarkEnqueueDequeue(b *testing.B) {
+	queues := []struct {
+		name string
+		new  func() queuex.Unordered[int]
+	}{
+		{"list", func() queuex.Unordered[int] { return queuex.NewQueue[int]() }},
+		{"lock-free", func() queuex.Unordered[int] { return queuex.NewLockFree[int]() }},
+		{"sharded", func() queuex.Unordered[int] { return queuex.NewSharded(func(v int) int { return v % 16 }) }},
+	}
+
+	for _, q := range queues {
+		b.Run(q.name, func(b *testing.B) {
+			queue := q.new()
+
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				queue.Enqueue(i)
+			}
+			for i := 0; i < b.N; i++ {
+				if _, ok := queue.Dequeue(); !ok {
+					b.Fatal("Expected an element")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package queuex
+
+import (
+	"iter"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// Sharded spreads the elements over several queues by shardOf, e.g. one per floor, so callers mostly lock different shards.
+// Every dequeue starts at the next shard in turn and steals from the following ones when it's empty.
+//
+// It implements Unordered, not Interface: every element enqueued is dequeued once and the elements of one shard leave
+// in the order they came, but Dequeue takes the head of some shard, and Snapshot and Values return the shards one after the other.
+type Sharded[T any] struct {
+	// shards is replaced by a longer copy when an element goes beyond the last shard, growMutex serialises the copies
+	shards    atomic.Pointer[[]*Queue[T]]
+	growMutex sync.Mutex
+	shardOf   func(T) int
+	next      atomic.Uint64
+}
+
+// NewSharded returns an empty queue of shards, the element v goes to the shard shardOf(v), negative ones to the first.
+// There is a shard up to the highest one enqueued to, e.g. as many as the floors of a lot when shardOf returns the floor.
+func NewSharded[T any](shardOf func(T) int) *Sharded[T] {
+	q := &Sharded[T]{shardOf: shardOf}
+	q.shards.Store(new([]*Queue[T]))
+
+	return q
+}
+
+// shard returns the shard of the element, adding the shards up to it when it's beyond the last one.
+func (q *Sharded[T]) shard(v T) *Queue[T] {
+	i := max(q.shardOf(v), 0)
+	if shards := *q.shards.Load(); i < len(shards) {
+		return shards[i]
+	}
+
+	q.growMutex.Lock()
+	defer q.growMutex.Unlock()
+
+	shards := *q.shards.Load()
+	if i >= len(shards) {
+		shards = slices.Clone(shards)
+		for len(shards) <= i {
+			shards = append(shards, NewQueue[T]())
+		}
+		q.shards.Store(&shards)
+	}
+	return shards[i]
+}
+
+// Enqueue adds an element to the tail of its shard.
+func (q *Sharded[T]) Enqueue(v T) {
+	q.shard(v).Enqueue(v)
+}
+
+// Dequeue removes and returns the head of the next shard that has elements.
+func (q *Sharded[T]) Dequeue() (T, bool) {
+	shards := *q.shards.Load()
+	if len(shards) > 0 {
+		start := int(q.next.Add(1) % uint64(len(shards)))
+		for i := range shards {
+			if v, ok := shards[(start+i)%len(shards)].Dequeue(); ok {
+				return v, true
+			}
+		}
+	}
+
+	var zeroValue T
+	return zeroValue, false
+}
+
+// Remove removes the first element matching of the first shard that has one, it reports whether one was found.
+func (q *Sharded[T]) Remove(match func(T) bool) bool {
+	for _, shard := range *q.shards.Load() {
+		if shard.Remove(match) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Len returns the number of elements in every shard.
+func (q *Sharded[T]) Len() int {
+	n := 0
+	for _, shard := range *q.shards.Load() {
+		n += shard.Len()
+	}
+	return n
+}
+
+// Snapshot read locks every shard, so the size and values are of one instant.
+func (q *Sharded[T]) Snapshot() (int, []T) {
+	shards := *q.shards.Load()
+	for _, shard := range shards {
+		shard.mutex.RLock()
+		defer shard.mutex.RUnlock()
+	}
+
+	var values []T
+	for _, shard := range shards {
+		for current := shard.head; current != nil; current = current.Next {
+			values = append(values, current.Value)
+		}
+	}
+	return len(values), values
+}
+
+// Values yields the values shard by shard, each shard is read locked while it's walked.
+func (q *Sharded[T]) Values() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, shard := range *q.shards.Load() {
+			for v := range shard.Values() {
+				if !yield(v) {
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package queuex_test
+
+import (
+	"github.com/mtfiqh/DoiT-parking-system/pkg/queuex"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestSharded(t *testing.T) {
+	// even and odd numbers go to their own shard
+	queue := queuex.NewSharded(func(v int) int { return v % 2 })
+	for i := 1; i <= 6; i++ {
+		queue.Enqueue(i)
+	}
+
+	if size, values := queue.Snapshot(); size != 6 || queue.Len() != 6 || !reflect.DeepEqual(values, []int{2, 4, 6, 1, 3, 5}) {
+		t.Errorf("Expected the shards one after the other [2 4 6 1 3 5], got %v with size %d", values, size)
+	}
+
+	if !queue.Remove(func(v int) bool { return v == 4 }) {
+		t.Error("Expected 4 to be removed")
+	}
+
+	// dequeues take turns between the shards and steal from the other one once theirs is empty
+	var dequeued []int
+	for v, ok := queue.Dequeue(); ok; v, ok = queue.Dequeue() {
+		dequeued = append(dequeued, v)
+	}
+
+	var evens, odds []int
+	for _, v := range dequeued {
+		if v%2 == 0 {
+			evens = append(evens, v)
+		} else {
+			odds = append(odds, v)
+		}
+	}
+	if !reflect.DeepEqual(evens, []int{2, 6}) || !reflect.DeepEqual(odds, []int{1, 3, 5}) {
+		t.Errorf("Expected every shard in FIFO order, got %v", dequeued)
+	}
+
+	sort.Ints(dequeued)
+	if !reflect.DeepEqual(dequeued, []int{1, 2, 3, 5, 6}) || queue.Len() != 0 {
+		t.Errorf("Expected to dequeue every element once, got %v", dequeued)
+	}
+}
+
+func TestShardedConcurrency(t *testing.T) {
+	testConservation(t, queuex.NewSharded(func(v int) int { return v % 4 }))
+}
+
+func TestShardedGrows(t *testing.T) {
+	queue := queuex.NewSharded(func(v int) int { return v / 10 })
+	if _, ok := queue.Dequeue(); ok || queue.Len() != 0 {
+		t.Fatal("Expected an empty queue without shards")
+	}
+
+	// one shard per ten, added as the elements come
+	for _, v := range []int{25, 3, 14, 27} {
+		queue.Enqueue(v)
+	}
+	if size, values := queue.Snapshot(); size != 4 || !reflect.DeepEqual(values, []int{3, 14, 25, 27}) {
+		t.Errorf("Expected the shards in order [3 14 25 27], got %v with size %d", values, size)
+	}
+}
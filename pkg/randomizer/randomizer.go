@@ -5,13 +5,20 @@ import (
 )
 
 func RandomizeEnum[T any](enums ...T) T {
+	return RandomizeEnumFrom(nil, enums...)
+}
 
+// RandomizeEnumFrom picks one of the enums drawing from r, the global source when nil, so a seeded r picks the same ones.
+func RandomizeEnumFrom[T any](r *rand.Rand, enums ...T) T {
 	if len(enums) == 0 {
 		var zeroValue T
 		return zeroValue
 	}
 
-	return enums[rand.Intn(len(enums))]
+	if r == nil {
+		return enums[rand.Intn(len(enums))]
+	}
+	return enums[r.Intn(len(enums))]
 }
 
 func RandomizeInt(min, max int) int {